@@ -0,0 +1,302 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+// Package client builds the client chain of the NSC requesting the Network Services from NSMgr
+package client
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/edwarnicke/vpphelper"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/connectioncontext"
+	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/up"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/clientinfo"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/excludedprefixes"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/heal"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/recvfd"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/sendfd"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/null"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/upstreamrefresh"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/adapters"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/config"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/nsc"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/attach"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/connacl"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/connfile"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/connlog"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/dnsfile"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/ecmp"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/failover"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/ipfix"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/isolation"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/locality"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/localprefixes"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/metrics"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/mtu"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/policy"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/qos"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/redundancy"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/registry"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/routes"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/snat"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/vcl"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/verify"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/plugins"
+)
+
+// Dependencies are the components of the NSC the client chain shares with the rest of it
+type Dependencies struct {
+	// VPPConn is the VPP API connection
+	VPPConn vpphelper.Connection
+	// Conns are the gRPC connections to NSMgr
+	Conns *failover.Conns
+	// HealCheck is the datapath liveness check used by heal
+	HealCheck heal.LivenessCheck
+	// ConnLog adds the connection fields to the logs
+	ConnLog *connlog.Hook
+	// Metrics records the connection metrics
+	Metrics *metrics.Metrics
+	// Registry keeps the established connections
+	Registry *registry.Registry
+	// Attacher attaches the connection interfaces to the VPP interfaces
+	Attacher *attach.Attacher
+	// Redundancy keeps the redundancy groups of the connections
+	Redundancy *redundancy.Groups
+	// Verifier verifies the datapath of the established connections, not verified if nil
+	Verifier *verify.Verifier
+	// APITrace logs the recent VPP API messages on the failed requests, disabled if nil
+	APITrace networkservice.NetworkServiceClient
+	// Events posts Kubernetes Events about the connections, disabled if nil
+	Events networkservice.NetworkServiceClient
+}
+
+// Builder builds the client chains of the NSC sharing the chain elements configured once
+type Builder struct {
+	ctx    context.Context
+	config *config.Config
+	deps   Dependencies
+	opts   []nsc.Option
+
+	ecmp                 *ecmp.Balancer
+	router               *routes.Router
+	nodeLocality         locality.Mode
+	policy               networkservice.NetworkServiceClient
+	dns                  networkservice.NetworkServiceClient
+	connFile             networkservice.NetworkServiceClient
+	vcl                  networkservice.NetworkServiceClient
+	isolation            networkservice.NetworkServiceClient
+	snat                 networkservice.NetworkServiceClient
+	acl                  networkservice.NetworkServiceClient
+	ipfix                networkservice.NetworkServiceClient
+	verify               networkservice.NetworkServiceClient
+	localPrefixes        networkservice.NetworkServiceClient
+	excludedPrefixesFile networkservice.NetworkServiceClient
+
+	ifindex interface_types.InterfaceIndex
+}
+
+// New returns the builder of the client chains configured by config. The options of the client plugins and opts are
+// applied to every chain after the built-in elements, e.g. nsc.WithBefore and nsc.WithAfter insert the additional
+// elements around them.
+func New(ctx context.Context, config *config.Config, deps *Dependencies, opts ...nsc.Option) (*Builder, error) {
+	b := &Builder{
+		ctx:                  ctx,
+		config:               config,
+		deps:                 *deps,
+		ecmp:                 ecmp.New(deps.VPPConn),
+		policy:               null.NewClient(),
+		dns:                  null.NewClient(),
+		connFile:             null.NewClient(),
+		vcl:                  null.NewClient(),
+		isolation:            null.NewClient(),
+		snat:                 null.NewClient(),
+		acl:                  null.NewClient(),
+		ipfix:                null.NewClient(),
+		verify:               null.NewClient(),
+		localPrefixes:        null.NewClient(),
+		excludedPrefixesFile: null.NewClient(),
+	}
+	if b.deps.APITrace == nil {
+		b.deps.APITrace = null.NewClient()
+	}
+	if b.deps.Events == nil {
+		b.deps.Events = null.NewClient()
+	}
+	if deps.Verifier != nil {
+		b.verify = deps.Verifier.NewClient()
+	}
+
+	staticRoutes, err := routes.ParsePrefixes(config.StaticRoutes...)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid static routes")
+	}
+	policyRoutes, err := routes.ParsePrefixes(config.PolicyRoutes...)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid policy routes")
+	}
+	b.router = routes.New(deps.VPPConn, &routes.Routes{Static: staticRoutes, Policy: policyRoutes})
+
+	if b.nodeLocality, err = locality.ParseMode(config.NodeLocality); err != nil {
+		return nil, errors.Wrap(err, "invalid node locality")
+	}
+
+	// The excluded prefixes file is watched by the server chain element, adapted to be used in the client chains
+	if config.ExcludedPrefixesFile != "" {
+		b.excludedPrefixesFile = adapters.NewServerToClient(
+			excludedprefixes.NewServer(ctx, excludedprefixes.WithConfigPath(config.ExcludedPrefixesFile)),
+		)
+	}
+	if config.ExcludeLocalPrefixes {
+		b.localPrefixes = localprefixes.NewClient(deps.VPPConn)
+	}
+
+	routedPairs, err := isolation.ParsePairs(config.RoutedPairs...)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid routed pairs")
+	}
+	if len(routedPairs) > 0 && !config.VrfIsolation {
+		return nil, errors.New("routed pairs require VRF isolation")
+	}
+	if config.VrfIsolation {
+		b.isolation = isolation.NewClient(deps.VPPConn, isolation.WithRoutedPairs(routedPairs...))
+	}
+	if config.SourceNAT {
+		b.snat = snat.NewClient(deps.VPPConn)
+	}
+
+	if config.IpfixCollector != "" {
+		collector, collectorErr := ipfix.ParseCollector(config.IpfixCollector)
+		if collectorErr != nil {
+			return nil, errors.Wrap(collectorErr, "invalid IPFIX collector")
+		}
+		src := net.ParseIP(config.IpfixSrcAddress)
+		if src == nil {
+			return nil, errors.Errorf("invalid IPFIX source address: %q", config.IpfixSrcAddress)
+		}
+		b.ipfix = ipfix.NewClient(deps.VPPConn, &ipfix.Config{
+			Collector:      collector,
+			Src:            src,
+			ActiveTimeout:  config.IpfixActiveTimeout,
+			PassiveTimeout: config.IpfixPassiveTimeout,
+		})
+	}
+
+	if config.ACLFile != "" {
+		aclConfig, aclErr := connacl.Load(config.ACLFile)
+		if aclErr != nil {
+			return nil, errors.Wrap(aclErr, "failed to load ACL rules")
+		}
+		b.acl = connacl.NewClient(deps.VPPConn, aclConfig)
+	}
+
+	if config.DNSConfigFile != "" {
+		dnsFormat, formatErr := dnsfile.ParseFormat(config.DNSConfigFormat)
+		if formatErr != nil {
+			return nil, errors.Wrap(formatErr, "invalid DNS config format")
+		}
+		b.dns = dnsfile.NewClient(config.DNSConfigFile, dnsFormat)
+	}
+
+	if config.ConnectionFilesDir != "" {
+		if mkdirErr := os.MkdirAll(config.ConnectionFilesDir, 0o755); mkdirErr != nil {
+			return nil, errors.Wrap(mkdirErr, "failed to create connection files directory")
+		}
+		b.connFile = connfile.NewClient(config.ConnectionFilesDir)
+	}
+
+	if config.VclDir != "" {
+		if mkdirErr := os.MkdirAll(config.VclDir, 0o755); mkdirErr != nil {
+			return nil, errors.Wrap(mkdirErr, "failed to create VCL directory")
+		}
+		if b.vcl, err = vcl.NewClient(deps.VPPConn, config.VclDir); err != nil {
+			return nil, errors.Wrap(err, "failed to create VCL client")
+		}
+	}
+
+	if len(config.Policies) > 0 {
+		if b.policy, err = policy.NewClient(config.Policies...); err != nil {
+			return nil, errors.Wrap(err, "failed to load policies")
+		}
+	}
+
+	if b.opts, err = plugins.ClientOptions(ctx, &plugins.Env{Config: config, VPPConn: deps.VPPConn}); err != nil {
+		return nil, err
+	}
+	b.opts = append(b.opts, opts...)
+	return b, nil
+}
+
+// NewClient returns the client chain requesting the Network Services from NSMgr on u, dialed with dialTimeout
+func (b *Builder) NewClient(u *url.URL, dialTimeout time.Duration) networkservice.NetworkServiceClient {
+	ctx, config, vppConn := b.ctx, b.config, b.deps.VPPConn
+	opts := []nsc.Option{
+		nsc.WithClientURL(u),
+		nsc.WithClientConn(b.deps.Conns.ClientConn(u, dialTimeout)),
+		nsc.WithName(config.Name),
+		nsc.WithHealClient(heal.NewClient(ctx,
+			heal.WithLivenessCheck(b.deps.HealCheck),
+			heal.WithLivenessCheckInterval(config.LivenessCheckInterval),
+			heal.WithLivenessCheckTimeout(config.LivenessCheckTimeout))),
+		nsc.WithElements(
+			nsc.Element{Name: nsc.ConnLog, Client: b.deps.ConnLog.NewClient()},
+			nsc.Element{Name: nsc.Metrics, Client: b.deps.Metrics.NewClient()},
+			nsc.Element{Name: nsc.APITrace, Client: b.deps.APITrace},
+			nsc.Element{Name: nsc.Events, Client: b.deps.Events},
+			nsc.Element{Name: nsc.ClientInfo, Client: clientinfo.NewClient()},
+			nsc.Element{Name: nsc.UpstreamRefresh, Client: upstreamrefresh.NewClient(ctx)},
+			nsc.Element{Name: nsc.Policy, Client: b.policy},
+			nsc.Element{Name: nsc.Locality, Client: locality.NewClient(b.nodeLocality, config.NodeLocalityAttempts)},
+			nsc.Element{Name: nsc.Redundancy, Client: b.deps.Redundancy.NewClient()},
+			nsc.Element{Name: nsc.Verify, Client: b.verify},
+			nsc.Element{Name: nsc.Up, Client: up.NewClient(ctx, vppConn)},
+			nsc.Element{Name: nsc.ECMP, Client: b.ecmp.NewClient()},
+			nsc.Element{Name: nsc.ConnectionContext, Client: connectioncontext.NewClient(vppConn)},
+			nsc.Element{Name: nsc.DNS, Client: b.dns},
+			nsc.Element{Name: nsc.ConnFile, Client: b.connFile},
+			nsc.Element{Name: nsc.VCL, Client: b.vcl},
+			nsc.Element{Name: nsc.Registry, Client: b.deps.Registry.NewClient()},
+			nsc.Element{Name: nsc.Attach, Client: b.deps.Attacher.NewClient()},
+			nsc.Element{Name: nsc.Routes, Client: b.router.NewClient()},
+			nsc.Element{Name: nsc.Isolation, Client: b.isolation},
+			nsc.Element{Name: nsc.SNAT, Client: b.snat},
+			nsc.Element{Name: nsc.ACL, Client: b.acl},
+			nsc.Element{Name: nsc.IPFIX, Client: b.ipfix},
+			nsc.Element{Name: nsc.MTU, Client: mtu.NewClient(vppConn, config.MSSClamp)},
+			nsc.Element{Name: nsc.QoS, Client: qos.NewClient(vppConn)},
+			nsc.Element{Name: nsc.Mechanisms, Client: newMechanismsClient(ctx, vppConn, config)},
+			nsc.Element{Name: nsc.IfIndex, Client: newIfIndexClient(&b.ifindex)},
+			nsc.Element{Name: nsc.SendFD, Client: sendfd.NewClient()},
+			nsc.Element{Name: nsc.RecvFD, Client: recvfd.NewClient()},
+			nsc.Element{Name: nsc.LocalPrefixes, Client: b.localPrefixes},
+			nsc.Element{Name: nsc.ExcludedPrefixesFile, Client: b.excludedPrefixesFile},
+			nsc.Element{Name: nsc.ExcludedPrefixes, Client: excludedprefixes.NewClient(excludedprefixes.WithAwarenessGroups(config.AwarenessGroups))},
+		),
+	}
+	return nsc.New(ctx, append(opts, b.opts...)...)
+}
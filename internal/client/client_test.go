@@ -0,0 +1,172 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package client
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/networkservicemesh/govpp/binapi/interface"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/ip"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	vxlanmech "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/vxlan"
+	wireguardmech "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/wireguard"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/payload"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/types"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/checks/checkcontext"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/checks/checkrequest"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/inject/injecterror"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/config"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/fakevpp"
+)
+
+func TestMechanisms(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		tunnelIP net.IP
+		expected []string
+	}{
+		{
+			name:     "local",
+			expected: []string{"MEMIF", "KERNEL"},
+		},
+		{
+			name:     "remote",
+			tunnelIP: net.ParseIP("10.0.0.1"),
+			expected: []string{"MEMIF", "KERNEL", "WIREGUARD", "VXLAN"},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, Mechanisms(&config.Config{TunnelIP: tc.tunnelIP}))
+		})
+	}
+}
+
+func TestIfIndexClient(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		stored   bool
+		expected interface_types.InterfaceIndex
+	}{
+		{name: "stored", stored: true, expected: 5},
+		{name: "not stored", expected: 0},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			swIfIndex := interface_types.InterfaceIndex(1)
+			client := chain.NewNetworkServiceClient(
+				metadata.NewClient(),
+				newIfIndexClient(&swIfIndex),
+				checkcontext.NewClient(t, func(t *testing.T, ctx context.Context) {
+					if tc.stored {
+						ifindex.Store(ctx, true, 5)
+					}
+				}),
+			)
+
+			_, err := client.Request(context.Background(), &networkservice.NetworkServiceRequest{
+				Connection: &networkservice.Connection{Id: "nsc-1"},
+			})
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, swIfIndex)
+		})
+	}
+}
+
+func TestMechanismsClient_RemotePreferences(t *testing.T) {
+	tunnelIP := net.ParseIP("10.0.0.1")
+
+	vppConn := fakevpp.New()
+	vppConn.Handle((&interfaces.SwInterfaceDump{}).GetMessageName(), func(api.Message) ([]api.Message, error) {
+		return []api.Message{&interfaces.SwInterfaceDetails{SwIfIndex: 1, Mtu: []uint32{1500, 1500, 1500, 1500}}}, nil
+	})
+	vppConn.Handle((&ip.IPAddressDump{}).GetMessageName(), func(api.Message) ([]api.Message, error) {
+		return []api.Message{&ip.IPAddressDetails{
+			SwIfIndex: 1,
+			Prefix:    types.ToVppAddressWithPrefix(&net.IPNet{IP: tunnelIP, Mask: net.CIDRMask(24, 32)}),
+		}}, nil
+	})
+	cfg := &config.Config{TunnelIP: tunnelIP, VxlanPort: 4790}
+
+	for _, tc := range []struct {
+		name      string
+		mechanism string
+		payload   string
+		check     func(t *testing.T, mechanism *networkservice.Mechanism)
+	}{
+		{
+			name:      "wireguard",
+			mechanism: wireguardmech.MECHANISM,
+			payload:   payload.IP,
+			check: func(t *testing.T, mechanism *networkservice.Mechanism) {
+				m := wireguardmech.ToMechanism(mechanism)
+				require.NotEmpty(t, m.SrcPublicKey())
+				require.True(t, tunnelIP.Equal(m.SrcIP()))
+				require.NotZero(t, m.SrcPort())
+			},
+		},
+		{
+			name:      "vxlan",
+			mechanism: vxlanmech.MECHANISM,
+			payload:   payload.Ethernet,
+			check: func(t *testing.T, mechanism *networkservice.Mechanism) {
+				m := vxlanmech.ToMechanism(mechanism)
+				require.True(t, tunnelIP.Equal(m.SrcIP()))
+				require.Equal(t, uint16(cfg.VxlanPort), m.SrcPort())
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var preferences []*networkservice.Mechanism
+			client := chain.NewNetworkServiceClient(
+				newMechanismsClient(context.Background(), vppConn, cfg),
+				checkrequest.NewClient(t, func(t *testing.T, request *networkservice.NetworkServiceRequest) {
+					preferences = request.Clone().GetMechanismPreferences()
+				}),
+				injecterror.NewClient(),
+			)
+
+			// The preference built from the Network Service URL has no parameters of the sdk-vpp client
+			_, err := client.Request(context.Background(), &networkservice.NetworkServiceRequest{
+				Connection:           &networkservice.Connection{Id: "nsc-1", Payload: tc.payload},
+				MechanismPreferences: []*networkservice.Mechanism{{Cls: cls.REMOTE, Type: tc.mechanism}},
+			})
+			require.Error(t, err)
+
+			require.Len(t, preferences, 1)
+			require.Equal(t, tc.mechanism, preferences[0].GetType())
+			require.Equal(t, cls.REMOTE, preferences[0].GetCls())
+			tc.check(t, preferences[0])
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+type ifIndexGetClient struct {
+	ifindex *interface_types.InterfaceIndex
+}
+
+// newIfIndexClient returns a client storing the swIfIndex of the last requested connection to ifindex
+func newIfIndexClient(ifindex *interface_types.InterfaceIndex) networkservice.NetworkServiceClient {
+	return &ifIndexGetClient{
+		ifindex: ifindex,
+	}
+}
+
+func (u *ifIndexGetClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+
+	ifindex, _ := ifindex.Load(ctx, true)
+	*u.ifindex = ifindex
+	log.FromContext(ctx).Infof("ifindex: %v", ifindex)
+
+	return conn, err
+}
+
+func (u *ifIndexGetClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
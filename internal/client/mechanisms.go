@@ -0,0 +1,92 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package client
+
+import (
+	"context"
+	"os"
+
+	"github.com/edwarnicke/vpphelper"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	kernelmech "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	memifmech "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+	vxlanmech "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/vxlan"
+	wireguardmech "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/wireguard"
+	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/mechanisms/kernel/kerneltap"
+	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/mechanisms/vxlan"
+	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/mechanisms/wireguard"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/kernel"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/config"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/mechanismfilter"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/memif"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/plugins"
+)
+
+// Mechanisms returns the types of the mechanisms the client chain configured by config can request
+func Mechanisms(config *config.Config) []string {
+	types := []string{memifmech.MECHANISM, kernelmech.MECHANISM}
+	if config.TunnelIP != nil {
+		types = append(types, wireguardmech.MECHANISM, vxlanmech.MECHANISM)
+	}
+	return append(types, plugins.MechanismTypes()...)
+}
+
+// newMechanismsClient returns a client handling the first supported mechanism from the request preferences
+func newMechanismsClient(ctx context.Context, vppConn vpphelper.Connection, config *config.Config) networkservice.NetworkServiceClient {
+	mechanismClients := map[string]networkservice.NetworkServiceClient{
+		memifmech.MECHANISM: chain.NewNetworkServiceClient(
+			mechanismfilter.NewClient(memifmech.MECHANISM),
+			memif.NewClient(ctx, vppConn,
+				memif.WithSocketDir(config.MemifSocketDir),
+				memif.WithSocketName(config.MemifSocketName),
+				memif.WithSocketMode(os.FileMode(config.MemifSocketMode)),
+				memif.WithSocketOwner(config.MemifSocketUID, config.MemifSocketGID),
+			),
+		),
+		kernelmech.MECHANISM: chain.NewNetworkServiceClient(
+			mechanismfilter.NewClient(kernelmech.MECHANISM),
+			kernel.NewClient(),
+			kerneltap.NewClient(vppConn),
+		),
+	}
+	if config.TunnelIP != nil {
+		mechanismClients[wireguardmech.MECHANISM] = chain.NewNetworkServiceClient(
+			mechanismfilter.NewClient(),
+			wireguard.NewClient(vppConn, config.TunnelIP),
+		)
+		mechanismClients[vxlanmech.MECHANISM] = chain.NewNetworkServiceClient(
+			mechanismfilter.NewClient(),
+			vxlan.NewClient(vppConn, config.TunnelIP, vxlan.WithPort(config.VxlanPort)),
+		)
+	}
+	// The mechanism handlers of the plugins replace the built-in ones
+	for mechanism, mechanismClient := range plugins.Mechanisms(ctx, &plugins.Env{Config: config, VPPConn: vppConn}) {
+		mechanismClients[mechanism] = chain.NewNetworkServiceClient(
+			mechanismfilter.NewClient(mechanism),
+			mechanismClient,
+		)
+	}
+	return mechanisms.NewClient(mechanismClients)
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config provides the configuration of the NSC loaded from the config file and the environment
+package config
+
+import (
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/awarenessgroups"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/configfile"
+)
+
+// Prefix is the prefix of the environment variables of Config
+const Prefix = "nsm"
+
+// Config - configuration for cmd-nsc-vpp
+type Config struct {
+	Name                        string                  `default:"cmd-nsc-vpp" desc:"Name of Endpoint"`
+	DialTimeout                 time.Duration           `default:"5s" desc:"timeout to dial NSMgr" split_words:"true"`
+	DialBackoff                 time.Duration           `default:"1s" desc:"Initial delay between NSMgr dial attempts, doubled after every failed attempt" split_words:"true"`
+	DialMaxBackoff              time.Duration           `default:"30s" desc:"Maximum delay between NSMgr dial attempts" split_words:"true"`
+	DialMaxWait                 time.Duration           `default:"5m" desc:"Maximum time to wait for NSMgr socket to appear and accept connections, waits forever if 0" split_words:"true"`
+	RequestTimeout              time.Duration           `default:"35s" desc:"timeout to request NSE" split_words:"true"`
+	ConnectTo                   []url.URL               `default:"unix:///var/lib/networkservicemesh/nsm.io.sock" desc:"NSMgr URLs to connect to, the next URL is used if the current one fails FailoverMaxFailures times in a row" split_words:"true"`
+	NsmgrDiscovery              bool                    `default:"false" desc:"Find the node-local NSMgr pods through the Kubernetes API and connect to them over TCP instead of ConnectTo" split_words:"true"`
+	NodeName                    string                  `default:"" desc:"Name of the node used by the NSMgr discovery, usually set from spec.nodeName with the downward API" split_words:"true"`
+	NsmgrNamespace              string                  `default:"nsm-system" desc:"Namespace of the NSMgr pods used by the NSMgr discovery" split_words:"true"`
+	NsmgrLabelSelector          string                  `default:"app=nsmgr" desc:"Label selector of the NSMgr pods used by the NSMgr discovery" split_words:"true"`
+	NsmgrPort                   int                     `default:"0" desc:"NSMgr port used by the NSMgr discovery, the port named nsmgr or the first TCP port of the pod is used if 0" split_words:"true"`
+	NsmgrSpiffeID               []string                `default:"" desc:"SPIFFE IDs of NSMgr allowed to connect to, any ID is allowed if empty" split_words:"true"`
+	NsmgrTrustDomain            string                  `default:"" desc:"Trust domain of NSMgr allowed to connect to, any trust domain is allowed if empty" split_words:"true"`
+	NsmgrSpiffeIDPattern        string                  `default:"" desc:"Regular expression matching the whole SPIFFE ID of NSMgr allowed to connect to, e.g. spiffe://example.org/ns/nsm-system/.*, any ID is allowed if empty" split_words:"true"`
+	FailoverMaxFailures         int                     `default:"3" desc:"Number of failed dials and requests in a row before failing over to the next NSMgr URL" split_words:"true"`
+	WorkloadAPIAddress          string                  `default:"" desc:"SPIFFE Workload API address, e.g. unix:///run/spire/sockets/agent.sock, SPIFFE_ENDPOINT_SOCKET is used if empty" split_words:"true"`
+	SvidFetchTimeout            time.Duration           `default:"15s" desc:"Timeout of a single attempt to fetch X.509 SVID" split_words:"true"`
+	SvidMaxWait                 time.Duration           `default:"5m" desc:"Maximum time to wait for X.509 SVID at startup, waits forever if 0" split_words:"true"`
+	X509CertFile                string                  `default:"" desc:"PEM file with the X.509 SVID certificate chain used instead of the SPIFFE Workload API, e.g. mounted from the cert-manager secret" split_words:"true"`
+	X509KeyFile                 string                  `default:"" desc:"PEM file with the private key of the X.509 SVID from X509CertFile" split_words:"true"`
+	X509CaFile                  string                  `default:"" desc:"PEM file with the trusted CA certificates used with X509CertFile" split_words:"true"`
+	X509ReloadInterval          time.Duration           `default:"1m" desc:"Interval of checking X509CertFile, X509KeyFile, X509CaFile for changes, disabled if 0" split_words:"true"`
+	TLSProfile                  string                  `default:"default" desc:"TLS profile of the NSMgr connection: default or fips - TLS 1.2 with FIPS 140-2 approved cipher suites and curves only" split_words:"true"`
+	TLSMinVersion               string                  `default:"" desc:"Minimum TLS version of the NSMgr connection: 1.2 or 1.3, 1.2 if empty" split_words:"true"`
+	TLSMaxVersion               string                  `default:"" desc:"Maximum TLS version of the NSMgr connection: 1.2 or 1.3, the latest if empty" split_words:"true"`
+	TLSCipherSuites             []string                `default:"" desc:"TLS 1.2 cipher suites allowed for the NSMgr connection, e.g. TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, the Go defaults if empty" split_words:"true"`
+	KeepaliveTime               time.Duration           `default:"0" desc:"Interval of the gRPC keepalive pings to NSMgr when the connection is idle, at least 10s, disabled if 0" split_words:"true"`
+	KeepaliveTimeout            time.Duration           `default:"20s" desc:"Timeout of the gRPC keepalive ping reply before closing the NSMgr connection" split_words:"true"`
+	KeepaliveWithoutStream      bool                    `default:"false" desc:"Send the gRPC keepalive pings to NSMgr even without active streams" split_words:"true"`
+	ProxyURL                    string                  `default:"" desc:"Proxy used to connect to NSMgr over TCP: http://, https:// for CONNECT over TLS or socks5://, with optional user:password@, HTTPS_PROXY is used by gRPC if empty" split_words:"true"`
+	NoProxy                     string                  `default:"" desc:"Comma separated hosts, domains, IPs and CIDRs connected directly when ProxyURL is set" split_words:"true"`
+	MaxRecvMsgSize              int                     `default:"0" desc:"Maximum size in bytes of the gRPC messages received from NSMgr, 4MiB if 0" split_words:"true"`
+	MaxSendMsgSize              int                     `default:"0" desc:"Maximum size in bytes of the gRPC messages sent to NSMgr, unlimited if 0" split_words:"true"`
+	Compression                 string                  `default:"" desc:"Compression of the gRPC messages sent to NSMgr: gzip or none if empty, NSMgr should support it" split_words:"true"`
+	MaxTokenLifetime            time.Duration           `default:"10m" desc:"maximum lifetime of tokens" split_words:"true"`
+	TokenRefreshAhead           time.Duration           `default:"1m" desc:"Tokens are reused until they expire in less than TokenRefreshAhead, should be longer than the longest request" split_words:"true"`
+	TokenAudience               []string                `default:"" desc:"Audience of tokens, the NSMgr SPIFFE ID if empty" split_words:"true"`
+	NetworkServices             []url.URL               `default:"" desc:"A list of Network Service Requests, {first..last} ranges in the path and the query expand into a request per value" split_words:"true"`
+	Labels                      map[string]string       `default:"" desc:"Labels added to all the requests, overridden by the URL labels. The values may reference the pod fields as {podName}, {nodeName}, {namespace} or the PodInfoDir files as {fileName}" split_words:"true"`
+	Policies                    []string                `default:"" desc:"Paths to the Rego policies or the file masks checking the received connections: path, labels, connection context, disabled if empty" split_words:"true"`
+	NodeLocality                string                  `default:"" desc:"Node locality of the endpoints selected by NSMgr: prefer or require the endpoints on the same node, any if empty" split_words:"true"`
+	NodeLocalityAttempts        int                     `default:"3" desc:"Number of requests trying to get the node-local endpoint" split_words:"true"`
+	PodName                     string                  `default:"" desc:"Name of the pod referenced in the labels as {podName}, usually set from metadata.name with the downward API" split_words:"true"`
+	PodNamespace                string                  `default:"" desc:"Namespace of the pod referenced in the labels as {namespace}, usually set from metadata.namespace with the downward API" split_words:"true"`
+	KubernetesEvents            bool                    `default:"false" desc:"Post Kubernetes Events about the connection failures and heals to the pod PodName in PodNamespace, the service account needs get pods and create events permissions" split_words:"true"`
+	PodInfoDir                  string                  `default:"" desc:"Downward API volume directory which files are referenced in the labels by the file names, disabled if empty" split_words:"true"`
+	ExcludedPrefixesFile        string                  `default:"" desc:"Path to the YAML file with the prefixes: list excluded from the connection IP addresses, watched for changes, disabled if empty" split_words:"true"`
+	ExcludeLocalPrefixes        bool                    `default:"false" desc:"Exclude the prefixes of the host and VPP interfaces from the connection IP addresses" split_words:"true"`
+	AwarenessGroups             awarenessgroups.Decoder `defailt:"" desc:"Awareness groups for mutually aware NSEs" split_words:"true"`
+	LogLevel                    string                  `default:"INFO" desc:"Log level" split_words:"true"`
+	LogFile                     string                  `default:"" desc:"Path to the file the logs are written to in addition to stderr, disabled if empty" split_words:"true"`
+	LogFileMaxSize              int                     `default:"100" desc:"Size of the log file in megabytes it is rotated after, not rotated if 0" split_words:"true"`
+	LogFileMaxBackups           int                     `default:"3" desc:"Number of the rotated log files kept" split_words:"true"`
+	LogRepeatInterval           time.Duration           `default:"1m" desc:"Identical messages of the liveness checks are logged at most once per this interval, not limited if 0" split_words:"true"`
+	LogLevels                   map[string]string       `default:"" desc:"Log levels of the components overriding LogLevel, e.g. heal:DEBUG,vpp:WARN,default:INFO, the components are heal - datapath liveness checks and vpp - VPP supervisor and API errors" split_words:"true"`
+	OpenTelemetryEndpoint       string                  `default:"otel-collector.observability.svc.cluster.local:4317" desc:"OpenTelemetry Collector Endpoint"`
+	MaxParallelRequests         int                     `default:"1" desc:"Maximum number of Network Services requested at the same time" split_words:"true"`
+	StartupJitter               time.Duration           `default:"0" desc:"Maximum random delay before the initial requests, spreads the requests of the NSCs restarted at the same time" split_words:"true"`
+	RetryJitter                 time.Duration           `default:"0" desc:"Maximum random delay added to the backoff between the retries of the failed requests" split_words:"true"`
+	AdminSocket                 string                  `default:"" desc:"Path to the unix socket of the runtime admin gRPC API, disabled if empty" split_words:"true"`
+	InterfacesSocket            string                  `default:"" desc:"Path to the unix socket of the read-only gRPC API mapping the Network Services to the connections, memif parameters and VPP interface indexes for the sidecars, disabled if empty" split_words:"true"`
+	AdminListen                 string                  `default:"" desc:"host:port of the local HTTP admin endpoint serving GET /connections, disabled if empty" split_words:"true"`
+	DebugBundleDir              string                  `default:"" desc:"Directory the debug bundles collected on SIGUSR1 or by the admin API are written to, disabled if empty" split_words:"true"`
+	DebugLogLines               int                     `default:"1000" desc:"Number of the recent log lines included in the debug bundles" split_words:"true"`
+	MirrorSocketDir             string                  `default:"" desc:"Directory of the memif sockets {name}.sock the connection traffic is mirrored to by the admin API for the analyzer containers, memif mirrors are disabled if empty" split_words:"true"`
+	PcapDir                     string                  `default:"" desc:"Directory the pcap files captured on the connection interfaces are written to, packet capture is disabled if empty" split_words:"true"`
+	PcapMaxPackets              uint32                  `default:"1000" desc:"Default maximum number of packets captured to a pcap file" split_words:"true"`
+	PcapDuration                time.Duration           `default:"10s" desc:"Default duration of a packet capture" split_words:"true"`
+	PcapOnConnect               bool                    `default:"false" desc:"Capture packets of every new connection for PcapDuration" split_words:"true"`
+	PrometheusListen            string                  `default:"" desc:"host:port of the HTTP server for Prometheus /metrics, metrics are exported to Prometheus instead of OTLP if set" split_words:"true"`
+	PprofListenOn               string                  `default:"" desc:"host:port of the HTTP server for /debug/pprof, should be a local address, disabled if empty" split_words:"true"`
+	ProbesListen                string                  `default:"" desc:"host:port of the HTTP server for /healthz, /readyz and /startupz probes, disabled if empty" split_words:"true"`
+	StateFile                   string                  `default:"" desc:"Path to the file the established connections are saved to, used to resume them and to close leftovers after restart, disabled if empty" split_words:"true"`
+	ConfigFile                  string                  `default:"" desc:"Path to YAML/JSON file with config values, env vars override values from the file" split_words:"true"`
+	DryRun                      bool                    `default:"false" desc:"Validate the config, print the effective config and exit without starting VPP, the exit code is non-zero if the config is invalid" split_words:"true"`
+	TunnelIP                    net.IP                  `default:"" desc:"IP of the VPP interface used for the remote mechanisms tunnels, remote mechanisms are disabled if empty" split_words:"true"`
+	VxlanPort                   uint16                  `default:"0" desc:"VXLAN port to use" split_words:"true"`
+	MemifSocketDir              string                  `default:"" desc:"Directory of the socket files of the master memif interfaces, the socket file from the peer is used if empty" split_words:"true"`
+	MemifSocketName             string                  `default:"{id}.sock" desc:"Name pattern of the master memif socket files, {id} and {service} are replaced with the connection ID and the Network Service" split_words:"true"`
+	MemifSocketMode             uint32                  `default:"0" desc:"File mode of the master memif socket files, e.g. 0660, unchanged if 0" split_words:"true"`
+	MemifSocketUID              int                     `default:"-1" desc:"Owner uid of the master memif socket files, unchanged if -1" split_words:"true"`
+	MemifSocketGID              int                     `default:"-1" desc:"Owner gid of the master memif socket files, unchanged if -1" split_words:"true"`
+	LivenessCheck               string                  `default:"vpp-ping" desc:"Datapath liveness check: vpp-ping, tcp - connect to the LivenessCheckPort, grpc-health - gRPC health check on the LivenessCheckPort, none - disabled or a check registered by the plugins" split_words:"true"`
+	LivenessCheckPort           int                     `default:"0" desc:"Port used by the tcp and grpc-health liveness checks" split_words:"true"`
+	LivenessCheckService        string                  `default:"" desc:"Service name checked by the grpc-health liveness check, the overall server health is checked if empty" split_words:"true"`
+	LivenessCheckInterval       time.Duration           `default:"3s" desc:"Interval of the datapath liveness checks" split_words:"true"`
+	LivenessCheckTimeout        time.Duration           `default:"10s" desc:"Timeout of a single datapath liveness check" split_words:"true"`
+	LivenessCheckPacketCount    int                     `default:"4" desc:"Number of packets sent to every address by the vpp-ping liveness check" split_words:"true"`
+	LivenessCheckPacketInterval time.Duration           `default:"0" desc:"Time the vpp-ping liveness check waits for the reply to every packet, derived from the check timeout if 0" split_words:"true"`
+	LivenessCheckPolicy         string                  `default:"any" desc:"Liveness check result aggregation over the connection addresses: any - alive if any address replies, all - alive if all addresses reply" split_words:"true"`
+	LivenessCheckGateways       bool                    `default:"false" desc:"Check the gateway addresses of the connections in addition to the destination addresses" split_words:"true"`
+	HookCommands                []string                `default:"" desc:"Executables run on the connection established, healed, degraded and closed events with the event and connection JSON on stdin" split_words:"true"`
+	HookWebhooks                []url.URL               `default:"" desc:"Webhook URLs the event and connection JSON is posted to on the connection established, healed, degraded and closed events" split_words:"true"`
+	HookTimeout                 time.Duration           `default:"10s" desc:"Timeout of a single hook run" split_words:"true"`
+	StaticRoutes                []string                `default:"" desc:"Destination prefixes routed in VPP via every connection interface in addition to the IP context routes" split_words:"true"`
+	PolicyRoutes                []string                `default:"" desc:"Source prefixes which traffic coming from the other NSM connections is forwarded via every connection interface" split_words:"true"`
+	VrfIsolation                bool                    `default:"false" desc:"Place every connection interface into its own VPP VRF, so the overlapping IP ranges of the Network Services don't collide" split_words:"true"`
+	RoutedPairs                 []string                `default:"" desc:"Pairs of the Network Services which connections are routed to each other in VPP, e.g. service-a:service-b, the other connections stay isolated, requires VrfIsolation" split_words:"true"`
+	SourceNAT                   bool                    `default:"false" desc:"Source NAT44 the traffic leaving VPP via the connection interfaces to the connection source IPs, for the applications that can't bind to them" split_words:"true"`
+	IpfixCollector              string                  `default:"" desc:"IPFIX collector host:port the flow records of the connection interfaces are exported to from VPP, disabled if empty" split_words:"true"`
+	IpfixSrcAddress             string                  `default:"" desc:"Source address of the IPFIX packets, an address of a VPP interface the collector is reachable via" split_words:"true"`
+	IpfixActiveTimeout          time.Duration           `default:"15s" desc:"Interval the IPFIX records of the active flows are exported at" split_words:"true"`
+	IpfixPassiveTimeout         time.Duration           `default:"120s" desc:"Inactivity interval the flows expire after" split_words:"true"`
+	ACLFile                     string                  `default:"" desc:"Path to the YAML file with the ingress and egress ACL rules of the connection interfaces by the Network Service, * for the other Network Services" split_words:"true"`
+	MSSClamp                    bool                    `default:"false" desc:"Clamp the TCP MSS to the MTU on all the connection interfaces" split_words:"true"`
+	DNSConfigFile               string                  `default:"" desc:"Path to the file the DNS configs of the connections are written to, disabled if empty" split_words:"true"`
+	DNSConfigFormat             string                  `default:"resolvconf" desc:"Format of the DNSConfigFile: resolvconf - nameserver and search lines, corefile - CoreDNS config for a sidecar forwarding the search domains to the DNS servers" split_words:"true"`
+	ConnectionFilesDir          string                  `default:"" desc:"Shared directory the JSON files with the IPs, routes, DNS, ifindex and memif socket of the established connections are written to as {id}.json, disabled if empty" split_words:"true"`
+	VclDir                      string                  `default:"" desc:"Shared directory the VCL configs {id}.conf and the VPP app sockets {id}.sock of the connections are written to, enables the VPP session layer for the applications using VCL sockets over the connection interfaces, disabled if empty" split_words:"true"`
+	CloseOnExit                 bool                    `default:"true" desc:"Close the connections on exit, if false they are left open to be adopted by the next NSC instance with the same Name" split_words:"true"`
+	ShutdownTimeout             time.Duration           `default:"15s" desc:"Time to close the connections and to wait for their VPP interfaces deletion on shutdown before VPP is stopped" split_words:"true"`
+	VppAPISocket                string                  `default:"" desc:"filename of socket to connect to existing VPP instance, a new VPP instance is started if empty" split_words:"true"`
+	VppConfigFile               string                  `default:"" desc:"Path to the startup.conf template of the started VPP, %[1]s is replaced with the VPP root dir" split_words:"true"`
+	VppInit                     string                  `default:"" desc:"startup.conf fragments appended to the config of the started VPP" split_words:"true"`
+	VppMainCore                 int                     `default:"-1" desc:"CPU the main thread of the started VPP is pinned to, not pinned if -1" split_words:"true"`
+	VppCorelistWorkers          string                  `default:"" desc:"CPUs the worker threads of the started VPP are pinned to, e.g. 2-3,5" split_words:"true"`
+	VppWorkers                  int                     `default:"0" desc:"Number of worker threads of the started VPP, can't be used together with VppCorelistWorkers" split_words:"true"`
+	VppStatsSocket              string                  `default:"/var/run/vpp/stats.sock" desc:"VPP stats socket used to export the memif interface counters when OpenTelemetry or Prometheus is enabled" split_words:"true"`
+	BenchmarkDuration           time.Duration           `default:"0" desc:"Duration of the traffic pushed into every connection by the VPP packet generator once all of them are established, the achieved throughput and drops are logged, disabled if 0" split_words:"true"`
+	BenchmarkRate               uint64                  `default:"10000" desc:"Packets per second generated by the benchmark" split_words:"true"`
+	BenchmarkPacketSize         int                     `default:"128" desc:"Size of the IP packets generated by the benchmark, at least 64" split_words:"true"`
+	VerifyMinPathMTU            int                     `default:"0" desc:"Minimal size of the IP packets passing the path to the NSE verified after each connection is established, not verified if 0" split_words:"true"`
+	VerifyMaxLatency            time.Duration           `default:"0" desc:"Maximal average round trip time to the NSE verified after each connection is established, not verified if 0" split_words:"true"`
+	VerifyMinThroughput         string                  `default:"" desc:"Minimal throughput of the echo requests answered by the NSE verified after each connection is established in bit/s with an optional k, M or G suffix, e.g. 100M, not verified if empty" split_words:"true"`
+	VerifyProbeDuration         time.Duration           `default:"1s" desc:"Duration of the throughput probe of the datapath verification" split_words:"true"`
+	ChaosFaults                 []string                `default:"" desc:"Faults injected at random times for the resilience testing of the applications: close - closes a connection, vpp - drops the VPP API session, heal - delays the heals, disabled if empty" split_words:"true"`
+	ChaosInterval               time.Duration           `default:"1m" desc:"Average interval between the injected faults" split_words:"true"`
+	ChaosDuration               time.Duration           `default:"10s" desc:"Duration of the dropped VPP API session and the delayed heals" split_words:"true"`
+	VppAPITraceSize             int                     `default:"0" desc:"Number of the recent VPP API messages logged when a request fails on a VPP API error, disabled if 0" split_words:"true"`
+	VppMaxRestarts              int                     `default:"0" desc:"Number of VPP restarts and re-dials after VPP failures before the NSC exits, VPP is not restarted if 0" split_words:"true"`
+}
+
+// Load loads config from the NSM_CONFIG_FILE file if set and from the environment overriding the file values
+func Load(config *Config) error {
+	if configFile := os.Getenv("NSM_CONFIG_FILE"); configFile != "" {
+		if err := configfile.Apply(Prefix, config, configFile); err != nil {
+			return errors.Wrap(err, "error processing config file")
+		}
+	}
+	if err := envconfig.Process(Prefix, config); err != nil {
+		return errors.Wrap(err, "error processing config from env")
+	}
+	return nil
+}
+
+// Usage prints the environment variables of config
+func Usage(config *Config) error {
+	return envconfig.Usage(Prefix, config)
+}
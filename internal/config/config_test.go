@@ -0,0 +1,120 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/config"
+)
+
+func TestLoad(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		file   string
+		env    map[string]string
+		check  func(t *testing.T, c *config.Config)
+		errMsg string
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, c *config.Config) {
+				require.Equal(t, "cmd-nsc-vpp", c.Name)
+				require.Equal(t, 5*time.Second, c.DialTimeout)
+				require.Equal(t, []url.URL{{Scheme: "unix", Path: "/var/lib/networkservicemesh/nsm.io.sock"}}, c.ConnectTo)
+				require.Empty(t, c.NetworkServices)
+				require.True(t, c.CloseOnExit)
+			},
+		},
+		{
+			name: "env",
+			env: map[string]string{
+				"NSM_NAME":             "nsc-1",
+				"NSM_NETWORK_SERVICES": "kernel://a/nsm-1,memif://b",
+				"NSM_LABELS":           "app:nsc,zone:a",
+				"NSM_CLOSE_ON_EXIT":    "false",
+			},
+			check: func(t *testing.T, c *config.Config) {
+				require.Equal(t, "nsc-1", c.Name)
+				require.Len(t, c.NetworkServices, 2)
+				require.Equal(t, "memif://b", c.NetworkServices[1].String())
+				require.Equal(t, map[string]string{"app": "nsc", "zone": "a"}, c.Labels)
+				require.False(t, c.CloseOnExit)
+			},
+		},
+		{
+			name: "file",
+			file: "name: nsc-1\nrequestTimeout: 1m\nnetworkServices:\n  - kernel://a/nsm-1\n",
+			check: func(t *testing.T, c *config.Config) {
+				require.Equal(t, "nsc-1", c.Name)
+				require.Equal(t, time.Minute, c.RequestTimeout)
+				require.Len(t, c.NetworkServices, 1)
+			},
+		},
+		{
+			name: "env overrides file",
+			file: "name: nsc-1\nrequestTimeout: 1m\n",
+			env:  map[string]string{"NSM_NAME": "nsc-2"},
+			check: func(t *testing.T, c *config.Config) {
+				require.Equal(t, "nsc-2", c.Name)
+				require.Equal(t, time.Minute, c.RequestTimeout)
+			},
+		},
+		{
+			name:   "unknown file key",
+			file:   "unknown: value\n",
+			errMsg: "error processing config file",
+		},
+		{
+			name:   "invalid env value",
+			env:    map[string]string{"NSM_DIAL_TIMEOUT": "5"},
+			errMsg: "error processing config from env",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.file != "" {
+				path := filepath.Join(t.TempDir(), "config.yaml")
+				require.NoError(t, os.WriteFile(path, []byte(tc.file), 0o600))
+				t.Setenv("NSM_CONFIG_FILE", path)
+				// Drop the values exported from the file once the test is done
+				t.Cleanup(func() {
+					require.NoError(t, os.WriteFile(path, nil, 0o600))
+					require.NoError(t, config.Load(new(config.Config)))
+				})
+			}
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			c := new(config.Config)
+			err := config.Load(c)
+			if tc.errMsg != "" {
+				require.ErrorContains(t, err, tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			tc.check(t, c)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/pkg/errors"
+)
+
+var printTemplate = template.Must(template.New("config").Funcs(template.FuncMap{
+	"format": format,
+}).Parse(`{{range .}}{{.Key}}={{format .Name .Field}}
+{{end}}`))
+
+// Print prints the effective config to out as the NSM_* environment variables
+func Print(out io.Writer, config *Config) error {
+	if err := envconfig.Usaget(Prefix, config, out, printTemplate); err != nil {
+		return errors.Wrap(err, "failed to print config")
+	}
+	return nil
+}
+
+// format returns the value of the config field in the format it is parsed from, the proxy and webhook passwords are
+// redacted
+func format(name string, value reflect.Value) string {
+	switch name {
+	case "ProxyURL":
+		if u, err := url.Parse(value.String()); err == nil {
+			return u.Redacted()
+		}
+	case "HookWebhooks":
+		webhooks := value.Interface().([]url.URL)
+		items := make([]string, len(webhooks))
+		for i := range webhooks {
+			items[i] = webhooks[i].Redacted()
+		}
+		return strings.Join(items, ",")
+	}
+	return formatValue(value)
+}
+
+func formatValue(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		if value.IsNil() || value.Kind() != reflect.Ptr && value.Len() == 0 {
+			return ""
+		}
+	}
+	if stringer, ok := value.Interface().(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	if value.CanAddr() {
+		if stringer, ok := value.Addr().Interface().(fmt.Stringer); ok {
+			return stringer.String()
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Slice:
+		items := make([]string, value.Len())
+		for i := range items {
+			if items[i] = formatValue(value.Index(i)); value.Index(i).Kind() == reflect.Slice {
+				items[i] = "[" + items[i] + "]"
+			}
+		}
+		return strings.Join(items, ",")
+	case reflect.Map:
+		items := make([]string, 0, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			items = append(items, formatValue(iter.Key())+":"+formatValue(iter.Value()))
+		}
+		sort.Strings(items)
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
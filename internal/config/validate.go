@@ -0,0 +1,203 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package config
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/encoding/gzip"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/chaos"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/connections"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/dnsfile"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/ipfix"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/isolation"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/locality"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/loglevels"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/proxydial"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/qos"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/routes"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/spiffeauth"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/tlsprofile"
+)
+
+const minKeepaliveTime = 10 * time.Second
+
+// Validate returns an error listing all the invalid values of config. The Network Services are checked to request
+// only the mechanisms if they are set. It doesn't start VPP, dial NSMgr or read the files config refers to, so it can
+// be run anywhere the config is defined.
+func Validate(config *Config, mechanisms ...string) error {
+	var errs []string
+	check := func(err error, format string, args ...interface{}) {
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, format, args...).Error())
+		}
+	}
+
+	level, err := logrus.ParseLevel(config.LogLevel)
+	check(err, "invalid LogLevel")
+	if len(config.LogLevels) > 0 {
+		_, err = loglevels.New(io.Discard, config.LogLevels, level)
+		check(err, "invalid LogLevels")
+	}
+
+	if config.NsmgrDiscovery {
+		if config.NodeName == "" {
+			check(errors.New("NodeName is required"), "invalid NsmgrDiscovery")
+		}
+	} else if len(config.ConnectTo) == 0 {
+		check(errors.New("no NSMgr URLs"), "invalid ConnectTo")
+	}
+	for i := range config.ConnectTo {
+		check(validateNSMgrURL(&config.ConnectTo[i]), "invalid ConnectTo")
+	}
+	check(connections.Validate(config.NetworkServices, mechanisms...), "invalid NetworkServices")
+
+	for _, timeout := range []struct {
+		name     string
+		value    time.Duration
+		positive bool
+	}{
+		{"DialTimeout", config.DialTimeout, true},
+		{"DialBackoff", config.DialBackoff, true},
+		{"DialMaxBackoff", config.DialMaxBackoff, true},
+		{"DialMaxWait", config.DialMaxWait, false},
+		{"RequestTimeout", config.RequestTimeout, true},
+		{"SvidFetchTimeout", config.SvidFetchTimeout, true},
+		{"SvidMaxWait", config.SvidMaxWait, false},
+		{"MaxTokenLifetime", config.MaxTokenLifetime, true},
+		{"TokenRefreshAhead", config.TokenRefreshAhead, false},
+		{"StartupJitter", config.StartupJitter, false},
+		{"RetryJitter", config.RetryJitter, false},
+		{"KeepaliveTimeout", config.KeepaliveTimeout, false},
+		{"LogRepeatInterval", config.LogRepeatInterval, false},
+		{"LivenessCheckInterval", config.LivenessCheckInterval, true},
+		{"LivenessCheckTimeout", config.LivenessCheckTimeout, true},
+		{"HookTimeout", config.HookTimeout, true},
+		{"ShutdownTimeout", config.ShutdownTimeout, true},
+	} {
+		switch {
+		case timeout.positive && timeout.value <= 0:
+			check(errors.Errorf("%s should be positive", timeout.value), "invalid %s", timeout.name)
+		case timeout.value < 0:
+			check(errors.Errorf("%s should not be negative", timeout.value), "invalid %s", timeout.name)
+		}
+	}
+	if config.TokenRefreshAhead >= config.MaxTokenLifetime {
+		check(errors.Errorf("%s should be shorter than MaxTokenLifetime %s", config.TokenRefreshAhead, config.MaxTokenLifetime), "invalid TokenRefreshAhead")
+	}
+	if config.KeepaliveTime != 0 && config.KeepaliveTime < minKeepaliveTime {
+		check(errors.Errorf("%s should be at least %s", config.KeepaliveTime, minKeepaliveTime), "invalid KeepaliveTime")
+	}
+	if config.MaxParallelRequests < 1 {
+		check(errors.Errorf("%d should be positive", config.MaxParallelRequests), "invalid MaxParallelRequests")
+	}
+
+	_, err = spiffeauth.NewAuthorizer(&spiffeauth.Config{
+		IDs:         config.NsmgrSpiffeID,
+		TrustDomain: config.NsmgrTrustDomain,
+		Pattern:     config.NsmgrSpiffeIDPattern,
+	})
+	check(err, "invalid NSMgr authorization config")
+	check(tlsprofile.Apply(&tls.Config{MinVersion: tls.VersionTLS12}, &tlsprofile.Config{
+		Profile:      config.TLSProfile,
+		MinVersion:   config.TLSMinVersion,
+		MaxVersion:   config.TLSMaxVersion,
+		CipherSuites: config.TLSCipherSuites,
+	}), "invalid TLS config")
+	if config.Compression != "" && config.Compression != gzip.Name {
+		check(errors.Errorf("unknown compression %s", config.Compression), "invalid Compression")
+	}
+	if config.ProxyURL != "" {
+		proxyURL, parseErr := url.Parse(config.ProxyURL)
+		check(parseErr, "invalid ProxyURL")
+		if parseErr == nil {
+			_, err = proxydial.New(proxyURL, config.NoProxy)
+			check(err, "invalid ProxyURL")
+		}
+	}
+
+	_, err = locality.ParseMode(config.NodeLocality)
+	check(err, "invalid NodeLocality")
+	_, err = routes.ParsePrefixes(config.StaticRoutes...)
+	check(err, "invalid StaticRoutes")
+	_, err = routes.ParsePrefixes(config.PolicyRoutes...)
+	check(err, "invalid PolicyRoutes")
+	routedPairs, err := isolation.ParsePairs(config.RoutedPairs...)
+	check(err, "invalid RoutedPairs")
+	if len(routedPairs) > 0 && !config.VrfIsolation {
+		check(errors.New("routed pairs require VrfIsolation"), "invalid RoutedPairs")
+	}
+	if config.IpfixCollector != "" {
+		_, err = ipfix.ParseCollector(config.IpfixCollector)
+		check(err, "invalid IpfixCollector")
+		if net.ParseIP(config.IpfixSrcAddress) == nil {
+			check(errors.Errorf("%q is not an IP", config.IpfixSrcAddress), "invalid IpfixSrcAddress")
+		}
+	}
+	if config.DNSConfigFile != "" {
+		_, err = dnsfile.ParseFormat(config.DNSConfigFormat)
+		check(err, "invalid DNSConfigFormat")
+	}
+
+	if config.VppCorelistWorkers != "" && config.VppWorkers > 0 {
+		check(errors.New("VppCorelistWorkers and VppWorkers can't be used together"), "invalid VPP cpu config")
+	}
+	if config.BenchmarkDuration > 0 && config.BenchmarkPacketSize < 64 {
+		check(errors.Errorf("%d is less than 64", config.BenchmarkPacketSize), "invalid BenchmarkPacketSize")
+	}
+	if config.VerifyMinThroughput != "" {
+		_, err = qos.ParseRate(config.VerifyMinThroughput)
+		check(err, "invalid VerifyMinThroughput")
+	}
+	if len(config.ChaosFaults) > 0 {
+		_, err = chaos.New(config.ChaosFaults, config.ChaosInterval, config.ChaosDuration)
+		check(err, "invalid chaos config")
+	}
+
+	if len(errs) > 0 {
+		return errors.Errorf("%d invalid config values: %s", len(errs), strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// validateNSMgrURL returns an error if NSMgr can't be dialed on u
+func validateNSMgrURL(u *url.URL) error {
+	switch u.Scheme {
+	case "unix":
+		if u.Path == "" {
+			return errors.Errorf("no socket path in %s", u.String())
+		}
+	case "tcp":
+		if u.Host == "" {
+			return errors.Errorf("no host in %s", u.String())
+		}
+	default:
+		return errors.Errorf("unsupported scheme %s in %s, should be unix or tcp", u.Scheme, u.String())
+	}
+	return nil
+}
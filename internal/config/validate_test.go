@@ -0,0 +1,154 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package config_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/config"
+)
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		update     func(c *config.Config)
+		mechanisms []string
+		errMsgs    []string
+	}{
+		{
+			name:   "defaults",
+			update: func(c *config.Config) {},
+		},
+		{
+			name: "network services",
+			update: func(c *config.Config) {
+				c.NetworkServices = []url.URL{{Scheme: "kernel", Host: "a", Path: "/nsm-1"}, {Scheme: "memif", Host: "b"}}
+			},
+			mechanisms: []string{"KERNEL", "MEMIF"},
+		},
+		{
+			name: "unsupported mechanism",
+			update: func(c *config.Config) {
+				c.NetworkServices = []url.URL{{Scheme: "vxlan", Host: "a"}}
+			},
+			mechanisms: []string{"KERNEL", "MEMIF"},
+			errMsgs:    []string{"invalid NetworkServices"},
+		},
+		{
+			name: "log level",
+			update: func(c *config.Config) {
+				c.LogLevel = "LOUD"
+			},
+			errMsgs: []string{"invalid LogLevel"},
+		},
+		{
+			name: "no nsmgr",
+			update: func(c *config.Config) {
+				c.ConnectTo = nil
+			},
+			errMsgs: []string{"invalid ConnectTo: no NSMgr URLs"},
+		},
+		{
+			name: "nsmgr discovery without node",
+			update: func(c *config.Config) {
+				c.ConnectTo = nil
+				c.NsmgrDiscovery = true
+			},
+			errMsgs: []string{"invalid NsmgrDiscovery"},
+		},
+		{
+			name: "nsmgr url",
+			update: func(c *config.Config) {
+				c.ConnectTo = []url.URL{{Scheme: "tcp"}, {Scheme: "http", Host: "nsmgr:5001"}}
+			},
+			errMsgs: []string{"no host", "unsupported scheme http"},
+		},
+		{
+			name: "timeouts",
+			update: func(c *config.Config) {
+				c.DialTimeout = 0
+				c.StartupJitter = -time.Second
+				c.TokenRefreshAhead = c.MaxTokenLifetime
+				c.KeepaliveTime = time.Second
+			},
+			errMsgs: []string{"invalid DialTimeout", "invalid StartupJitter", "invalid TokenRefreshAhead", "invalid KeepaliveTime"},
+		},
+		{
+			name: "tls",
+			update: func(c *config.Config) {
+				c.TLSProfile = "fips"
+				c.TLSMinVersion = "1.3"
+			},
+			errMsgs: []string{"invalid TLS config"},
+		},
+		{
+			name: "proxy",
+			update: func(c *config.Config) {
+				c.ProxyURL = "ftp://proxy:21"
+			},
+			errMsgs: []string{"invalid ProxyURL"},
+		},
+		{
+			name: "routed pairs without vrf isolation",
+			update: func(c *config.Config) {
+				c.RoutedPairs = []string{"a:b"}
+			},
+			errMsgs: []string{"routed pairs require VrfIsolation"},
+		},
+		{
+			name: "vpp workers",
+			update: func(c *config.Config) {
+				c.VppCorelistWorkers = "2-3"
+				c.VppWorkers = 2
+			},
+			errMsgs: []string{"invalid VPP cpu config"},
+		},
+		{
+			name: "all errors",
+			update: func(c *config.Config) {
+				c.Compression = "zstd"
+				c.MaxParallelRequests = 0
+				c.NodeLocality = "anywhere"
+				c.VerifyMinThroughput = "fast"
+			},
+			errMsgs: []string{"4 invalid config values", "invalid Compression", "invalid MaxParallelRequests", "invalid NodeLocality", "invalid VerifyMinThroughput"},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := new(config.Config)
+			require.NoError(t, config.Load(c))
+			tc.update(c)
+
+			err := config.Validate(c, tc.mechanisms...)
+			if len(tc.errMsgs) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			for _, errMsg := range tc.errMsgs {
+				require.ErrorContains(t, err, errMsg)
+			}
+		})
+	}
+}
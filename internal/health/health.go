@@ -0,0 +1,104 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package health provides the datapath liveness checks of the connections and the probes of the NSC
+package health
+
+import (
+	"context"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/edwarnicke/vpphelper"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/heal"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/config"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/attach"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/liveness"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/logsampler"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/metrics"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/probes"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/redundancy"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/registry"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/plugins"
+)
+
+// NewLivenessCheck returns the datapath liveness check of the connections configured by config. The liveness checks
+// registered by the plugins take precedence over the built-in ones.
+func NewLivenessCheck(ctx context.Context, config *config.Config, vppConn vpphelper.Connection, nscMetrics *metrics.Metrics) (heal.LivenessCheck, error) {
+	if newCheck, ok := plugins.LivenessCheck(config.LivenessCheck); ok {
+		check, err := newCheck(ctx, &plugins.Env{Config: config, VPPConn: vppConn})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create %s liveness check", config.LivenessCheck)
+		}
+		return check, nil
+	}
+	policy, err := liveness.ParsePolicy(config.LivenessCheckPolicy)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid liveness check policy")
+	}
+	check, err := liveness.New(ctx, config.LivenessCheck, vppConn, nscMetrics,
+		liveness.WithPolicy(policy),
+		liveness.WithGateways(config.LivenessCheckGateways),
+		liveness.WithPort(config.LivenessCheckPort),
+		liveness.WithHealthService(config.LivenessCheckService),
+		liveness.WithPacketCount(config.LivenessCheckPacketCount),
+		liveness.WithPacketInterval(config.LivenessCheckPacketInterval),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid liveness check")
+	}
+	return check, nil
+}
+
+// ValidateLivenessCheck returns an error if the liveness check configured by config can't be created
+func ValidateLivenessCheck(config *config.Config) error {
+	if _, ok := plugins.LivenessCheck(config.LivenessCheck); ok {
+		return nil
+	}
+	if _, err := liveness.ParsePolicy(config.LivenessCheckPolicy); err != nil {
+		return errors.Wrap(err, "invalid liveness check policy")
+	}
+	if err := liveness.Validate(config.LivenessCheck, liveness.WithPort(config.LivenessCheckPort)); err != nil {
+		return errors.Wrap(err, "invalid liveness check")
+	}
+	return nil
+}
+
+// NewHealCheck returns the liveness check used by heal: the datapath check of the connection interfaces and their
+// redundancy groups reported to connRegistry. Identical messages of the check are logged once per
+// config.LogRepeatInterval.
+func NewHealCheck(config *config.Config, check heal.LivenessCheck, connRegistry *registry.Registry, redundancyGroups *redundancy.Groups, attacher *attach.Attacher) heal.LivenessCheck {
+	healCheck := connRegistry.LivenessCheck(redundancyGroups.LivenessCheck(attacher.LivenessCheck(check)))
+	if config.LogRepeatInterval > 0 {
+		healCheck = logsampler.New(config.LogRepeatInterval).LivenessCheck(healCheck)
+	}
+	return healCheck
+}
+
+// NewDatapathCheck returns the check of the established connections datapath before they are reported as ready
+func NewDatapathCheck(check heal.LivenessCheck, redundancyGroups *redundancy.Groups, attacher *attach.Attacher) heal.LivenessCheck {
+	return redundancyGroups.DatapathCheck(attacher.LivenessCheck(check))
+}
+
+// NewProbes returns the probes checking VPP is alive and the NSC is ready with readinessChecks
+func NewProbes(vppConn api.Connection, readinessChecks ...probes.Check) *probes.Probes {
+	return probes.New(
+		probes.WithLivenessChecks(probes.VPPAlive(vppConn)),
+		probes.WithReadinessChecks(readinessChecks...),
+	)
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/heal"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/config"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/health"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/plugins"
+)
+
+const (
+	pluginCheck       = "health-test-check"
+	failedPluginCheck = "health-test-failed-check"
+)
+
+func init() {
+	plugins.RegisterLivenessCheck(pluginCheck, func(context.Context, *plugins.Env) (heal.LivenessCheck, error) {
+		return func(context.Context, *networkservice.Connection) bool { return true }, nil
+	})
+	plugins.RegisterLivenessCheck(failedPluginCheck, func(context.Context, *plugins.Env) (heal.LivenessCheck, error) {
+		return nil, errors.New("check is not available")
+	})
+}
+
+func TestNewLivenessCheck(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		strategy string
+		port     int
+		policy   string
+		isNil    bool
+		invalid  bool
+		err      bool
+	}{
+		{name: "none", strategy: "none", isNil: true},
+		{name: "tcp", strategy: "tcp", port: 8080},
+		{name: "grpc health", strategy: "grpc-health", port: 8080, policy: "all"},
+		{name: "plugin", strategy: pluginCheck},
+		{name: "plugin with invalid policy", strategy: pluginCheck, policy: "some"},
+		{name: "failed plugin", strategy: failedPluginCheck, err: true},
+		{name: "tcp without port", strategy: "tcp", invalid: true, err: true},
+		{name: "invalid policy", strategy: "tcp", port: 8080, policy: "some", invalid: true, err: true},
+		{name: "unknown", strategy: "ping", invalid: true, err: true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			c := new(config.Config)
+			require.NoError(t, config.Load(c))
+			c.LivenessCheck = tc.strategy
+			c.LivenessCheckPort = tc.port
+			if tc.policy != "" {
+				c.LivenessCheckPolicy = tc.policy
+			}
+
+			require.Equal(t, tc.invalid, health.ValidateLivenessCheck(c) != nil)
+
+			check, err := health.NewLivenessCheck(ctx, c, nil, nil)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.isNil, check == nil)
+		})
+	}
+}
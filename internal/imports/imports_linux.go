@@ -2,49 +2,161 @@
 package imports
 
 import (
+	_ "archive/tar"
+	_ "bufio"
+	_ "bytes"
+	_ "compress/gzip"
 	_ "context"
+	_ "crypto/rand"
+	_ "crypto/sha256"
 	_ "crypto/tls"
+	_ "crypto/x509"
+	_ "encoding/base64"
+	_ "encoding/binary"
+	_ "encoding/hex"
+	_ "encoding/json"
 	_ "fmt"
+	_ "git.fd.io/govpp.git/adapter/statsclient"
+	_ "git.fd.io/govpp.git/api"
+	_ "git.fd.io/govpp.git/core"
 	_ "github.com/antonfisher/nested-logrus-formatter"
 	_ "github.com/edwarnicke/debug"
 	_ "github.com/edwarnicke/grpcfd"
 	_ "github.com/edwarnicke/vpphelper"
+	_ "github.com/ghodss/yaml"
+	_ "github.com/golang-jwt/jwt/v4"
 	_ "github.com/golang/protobuf/ptypes/empty"
 	_ "github.com/kelseyhightower/envconfig"
 	_ "github.com/networkservicemesh/api/pkg/api/networkservice"
+	_ "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	_ "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/common"
+	_ "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	_ "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+	_ "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/vxlan"
+	_ "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/wireguard"
+	_ "github.com/networkservicemesh/api/pkg/api/networkservice/payload"
+	_ "github.com/networkservicemesh/govpp/binapi/abf"
+	_ "github.com/networkservicemesh/govpp/binapi/acl"
+	_ "github.com/networkservicemesh/govpp/binapi/acl_types"
+	_ "github.com/networkservicemesh/govpp/binapi/af_packet"
+	_ "github.com/networkservicemesh/govpp/binapi/af_xdp"
+	_ "github.com/networkservicemesh/govpp/binapi/fib_types"
+	_ "github.com/networkservicemesh/govpp/binapi/flowprobe"
+	_ "github.com/networkservicemesh/govpp/binapi/interface"
 	_ "github.com/networkservicemesh/govpp/binapi/interface_types"
+	_ "github.com/networkservicemesh/govpp/binapi/ip"
 	_ "github.com/networkservicemesh/govpp/binapi/ip_types"
+	_ "github.com/networkservicemesh/govpp/binapi/ipfix_export"
+	_ "github.com/networkservicemesh/govpp/binapi/l2"
+	_ "github.com/networkservicemesh/govpp/binapi/memclnt"
+	_ "github.com/networkservicemesh/govpp/binapi/memif"
+	_ "github.com/networkservicemesh/govpp/binapi/mss_clamp"
+	_ "github.com/networkservicemesh/govpp/binapi/nat44_ed"
+	_ "github.com/networkservicemesh/govpp/binapi/pg"
 	_ "github.com/networkservicemesh/govpp/binapi/ping"
+	_ "github.com/networkservicemesh/govpp/binapi/policer"
+	_ "github.com/networkservicemesh/govpp/binapi/policer_types"
+	_ "github.com/networkservicemesh/govpp/binapi/qos"
+	_ "github.com/networkservicemesh/govpp/binapi/session"
+	_ "github.com/networkservicemesh/govpp/binapi/span"
+	_ "github.com/networkservicemesh/govpp/binapi/tapv2"
+	_ "github.com/networkservicemesh/govpp/binapi/vhost_user"
+	_ "github.com/networkservicemesh/govpp/binapi/vlib"
+	_ "github.com/networkservicemesh/govpp/binapi/vpe"
 	_ "github.com/networkservicemesh/sdk-vpp/pkg/networkservice/connectioncontext"
+	_ "github.com/networkservicemesh/sdk-vpp/pkg/networkservice/mechanisms/kernel/kerneltap"
 	_ "github.com/networkservicemesh/sdk-vpp/pkg/networkservice/mechanisms/memif"
+	_ "github.com/networkservicemesh/sdk-vpp/pkg/networkservice/mechanisms/memif/memifrxmode"
+	_ "github.com/networkservicemesh/sdk-vpp/pkg/networkservice/mechanisms/vxlan"
+	_ "github.com/networkservicemesh/sdk-vpp/pkg/networkservice/mechanisms/wireguard"
 	_ "github.com/networkservicemesh/sdk-vpp/pkg/networkservice/up"
+	_ "github.com/networkservicemesh/sdk-vpp/pkg/networkservice/vrf"
 	_ "github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+	_ "github.com/networkservicemesh/sdk-vpp/pkg/tools/types"
 	_ "github.com/networkservicemesh/sdk/pkg/networkservice/chains/client"
 	_ "github.com/networkservicemesh/sdk/pkg/networkservice/common/clientinfo"
 	_ "github.com/networkservicemesh/sdk/pkg/networkservice/common/excludedprefixes"
 	_ "github.com/networkservicemesh/sdk/pkg/networkservice/common/heal"
+	_ "github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms"
+	_ "github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/kernel"
 	_ "github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/recvfd"
 	_ "github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/sendfd"
+	_ "github.com/networkservicemesh/sdk/pkg/networkservice/common/null"
 	_ "github.com/networkservicemesh/sdk/pkg/networkservice/common/retry"
 	_ "github.com/networkservicemesh/sdk/pkg/networkservice/common/upstreamrefresh"
+	_ "github.com/networkservicemesh/sdk/pkg/networkservice/core/adapters"
+	_ "github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
 	_ "github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	_ "github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
 	_ "github.com/networkservicemesh/sdk/pkg/tools/awarenessgroups"
+	_ "github.com/networkservicemesh/sdk/pkg/tools/extend"
 	_ "github.com/networkservicemesh/sdk/pkg/tools/grpcutils"
+	_ "github.com/networkservicemesh/sdk/pkg/tools/interdomain"
 	_ "github.com/networkservicemesh/sdk/pkg/tools/log"
 	_ "github.com/networkservicemesh/sdk/pkg/tools/log/logruslogger"
 	_ "github.com/networkservicemesh/sdk/pkg/tools/nsurl"
+	_ "github.com/networkservicemesh/sdk/pkg/tools/opa"
 	_ "github.com/networkservicemesh/sdk/pkg/tools/opentelemetry"
+	_ "github.com/networkservicemesh/sdk/pkg/tools/postpone"
 	_ "github.com/networkservicemesh/sdk/pkg/tools/spiffejwt"
 	_ "github.com/networkservicemesh/sdk/pkg/tools/token"
 	_ "github.com/networkservicemesh/sdk/pkg/tools/tracing"
+	_ "github.com/pkg/errors"
+	_ "github.com/prometheus/client_golang/prometheus/promhttp"
 	_ "github.com/sirupsen/logrus"
+	_ "github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	_ "github.com/spiffe/go-spiffe/v2/spiffeid"
 	_ "github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+	_ "github.com/spiffe/go-spiffe/v2/svid/x509svid"
 	_ "github.com/spiffe/go-spiffe/v2/workloadapi"
+	_ "github.com/vishvananda/netlink"
+	_ "github.com/vishvananda/netns"
+	_ "go.opentelemetry.io/otel"
+	_ "go.opentelemetry.io/otel/attribute"
+	_ "go.opentelemetry.io/otel/exporters/prometheus"
+	_ "go.opentelemetry.io/otel/metric"
+	_ "go.opentelemetry.io/otel/sdk/metric"
+	_ "go.opentelemetry.io/otel/sdk/resource"
+	_ "go.opentelemetry.io/otel/semconv/v1.4.0"
+	_ "golang.org/x/net/proxy"
+	_ "golang.org/x/sync/errgroup"
+	_ "golang.org/x/sys/unix"
 	_ "google.golang.org/grpc"
+	_ "google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/connectivity"
 	_ "google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/encoding/gzip"
+	_ "google.golang.org/grpc/health/grpc_health_v1"
+	_ "google.golang.org/grpc/keepalive"
+	_ "google.golang.org/grpc/peer"
+	_ "google.golang.org/grpc/status"
+	_ "google.golang.org/protobuf/encoding/protojson"
+	_ "google.golang.org/protobuf/types/known/emptypb"
+	_ "google.golang.org/protobuf/types/known/structpb"
+	_ "google.golang.org/protobuf/types/known/wrapperspb"
+	_ "io"
+	_ "math"
+	_ "math/rand"
+	_ "net"
+	_ "net/http"
+	_ "net/http/pprof"
 	_ "net/url"
 	_ "os"
+	_ "os/exec"
 	_ "os/signal"
+	_ "path"
+	_ "path/filepath"
+	_ "reflect"
+	_ "regexp"
+	_ "runtime"
+	_ "runtime/debug"
+	_ "sort"
+	_ "strconv"
+	_ "strings"
+	_ "sync"
+	_ "sync/atomic"
 	_ "syscall"
+	_ "text/template"
 	_ "time"
 )
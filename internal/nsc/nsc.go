@@ -0,0 +1,101 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nsc provides the client chain of the NSC built from the named chain elements, so the additional elements
+// can be inserted before or after the built-in ones without copying the whole chain
+package nsc
+
+import (
+	"context"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/chains/client"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// Names of the built-in elements of the NSC client chain in their order
+const (
+	ConnLog              = "connlog"
+	Metrics              = "metrics"
+	APITrace             = "apitrace"
+	Events               = "events"
+	ClientInfo           = "clientinfo"
+	UpstreamRefresh      = "upstreamrefresh"
+	Policy               = "policy"
+	Locality             = "locality"
+	Redundancy           = "redundancy"
+	Verify               = "verify"
+	Up                   = "up"
+	ECMP                 = "ecmp"
+	ConnectionContext    = "connectioncontext"
+	DNS                  = "dns"
+	ConnFile             = "connfile"
+	VCL                  = "vcl"
+	Registry             = "registry"
+	Attach               = "attach"
+	Routes               = "routes"
+	Isolation            = "isolation"
+	SNAT                 = "snat"
+	ACL                  = "acl"
+	IPFIX                = "ipfix"
+	MTU                  = "mtu"
+	QoS                  = "qos"
+	Mechanisms           = "mechanisms"
+	IfIndex              = "ifindex"
+	SendFD               = "sendfd"
+	RecvFD               = "recvfd"
+	LocalPrefixes        = "localprefixes"
+	ExcludedPrefixesFile = "excludedprefixesfile"
+	ExcludedPrefixes     = "excludedprefixes"
+)
+
+// Element is a named element of the client chain
+type Element struct {
+	Name   string
+	Client networkservice.NetworkServiceClient
+}
+
+// New returns the client chain of the elements from WithElements with the elements from WithBefore and WithAfter
+// inserted around them. The elements inserted around the missing elements are added to the end of the chain.
+func New(ctx context.Context, opts ...Option) networkservice.NetworkServiceClient {
+	o := &options{
+		before: make(map[string][]networkservice.NetworkServiceClient),
+		after:  make(map[string][]networkservice.NetworkServiceClient),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var elements []networkservice.NetworkServiceClient
+	inserted := make(map[string]bool)
+	for _, element := range o.elements {
+		elements = append(elements, o.before[element.Name]...)
+		elements = append(elements, element.Client)
+		elements = append(elements, o.after[element.Name]...)
+		inserted[element.Name] = true
+	}
+	for _, name := range o.names {
+		if inserted[name] {
+			continue
+		}
+		log.FromContext(ctx).Warnf("no %q element in the client chain, adding the elements inserted around it to the end", name)
+		elements = append(elements, o.before[name]...)
+		elements = append(elements, o.after[name]...)
+	}
+
+	return client.NewClient(ctx, append(o.clientOptions, client.WithAdditionalFunctionality(elements...))...)
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nsc
+
+import (
+	"net/url"
+
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/chains/client"
+)
+
+type options struct {
+	clientOptions []client.Option
+	elements      []Element
+	before        map[string][]networkservice.NetworkServiceClient
+	after         map[string][]networkservice.NetworkServiceClient
+	names         []string
+}
+
+// Option is an option pattern for New
+type Option func(o *options)
+
+// WithName sets the name of the client
+func WithName(name string) Option {
+	return func(o *options) {
+		o.clientOptions = append(o.clientOptions, client.WithName(name))
+	}
+}
+
+// WithClientURL sets the NSMgr URL the client connects to
+func WithClientURL(u *url.URL) Option {
+	return func(o *options) {
+		o.clientOptions = append(o.clientOptions, client.WithClientURL(u))
+	}
+}
+
+// WithClientConn sets the gRPC connection to NSMgr
+func WithClientConn(cc grpc.ClientConnInterface) Option {
+	return func(o *options) {
+		o.clientOptions = append(o.clientOptions, client.WithClientConn(cc))
+	}
+}
+
+// WithHealClient sets the heal chain element
+func WithHealClient(healClient networkservice.NetworkServiceClient) Option {
+	return func(o *options) {
+		o.clientOptions = append(o.clientOptions, client.WithHealClient(healClient))
+	}
+}
+
+// WithElements appends the named elements to the client chain
+func WithElements(elements ...Element) Option {
+	return func(o *options) {
+		o.elements = append(o.elements, elements...)
+	}
+}
+
+// WithBefore inserts the elements before the element with the name
+func WithBefore(name string, elements ...networkservice.NetworkServiceClient) Option {
+	return func(o *options) {
+		o.addName(name)
+		o.before[name] = append(o.before[name], elements...)
+	}
+}
+
+// WithAfter inserts the elements after the element with the name, the elements inserted earlier stay closer to it
+func WithAfter(name string, elements ...networkservice.NetworkServiceClient) Option {
+	return func(o *options) {
+		o.addName(name)
+		o.after[name] = append(o.after[name], elements...)
+	}
+}
+
+func (o *options) addName(name string) {
+	if _, ok := o.before[name]; ok {
+		return
+	}
+	if _, ok := o.after[name]; ok {
+		return
+	}
+	o.names = append(o.names, name)
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nsmgr provides the connection of the NSC to the NSMgr: discovering the NSMgr URLs, building the gRPC dial
+// options and dialing the NSMgr with backoff
+package nsmgr
+
+import (
+	"context"
+	"crypto/tls"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/edwarnicke/grpcfd"
+	"github.com/pkg/errors"
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/grpcutils"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/token"
+	"github.com/networkservicemesh/sdk/pkg/tools/tracing"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/config"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/failover"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/k8sdiscovery"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/proxydial"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/spiffeauth"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/tlsprofile"
+)
+
+// TLSConfig returns the mTLS client config authorizing the NSMgr by the SPIFFE IDs and the TLS profile from the config
+func TLSConfig(ctx context.Context, cfg *config.Config, svidSource x509svid.Source, bundleSource x509bundle.Source) (*tls.Config, error) {
+	nsmgrAuth := &spiffeauth.Config{
+		IDs:         cfg.NsmgrSpiffeID,
+		TrustDomain: cfg.NsmgrTrustDomain,
+		Pattern:     cfg.NsmgrSpiffeIDPattern,
+	}
+	if nsmgrAuth.IsEmpty() {
+		log.FromContext(ctx).Warn("NSMgr SPIFFE ID is not restricted, any NSMgr is authorized")
+	}
+	nsmgrAuthorizer, err := spiffeauth.NewAuthorizer(nsmgrAuth)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid NSMgr authorization config")
+	}
+
+	tlsClientConfig := tlsconfig.MTLSClientConfig(svidSource, bundleSource, nsmgrAuthorizer)
+	tlsClientConfig.MinVersion = tls.VersionTLS12
+	if err := tlsprofile.Apply(tlsClientConfig, &tlsprofile.Config{
+		Profile:      cfg.TLSProfile,
+		MinVersion:   cfg.TLSMinVersion,
+		MaxVersion:   cfg.TLSMaxVersion,
+		CipherSuites: cfg.TLSCipherSuites,
+	}); err != nil {
+		return nil, errors.Wrap(err, "invalid TLS config")
+	}
+	return tlsClientConfig, nil
+}
+
+// DialOptions returns the gRPC dial options for the NSMgr connections: the tracing, the token credentials, the TLS
+// transport, the proxy, the message size limits, the compression and the keepalive from the config. Additional
+// options are appended after the TLS transport ones.
+func DialOptions(cfg *config.Config, tlsClientConfig *tls.Config, tokenGenerator token.GeneratorFunc, additionalOptions ...grpc.DialOption) ([]grpc.DialOption, error) {
+	dialOptions := append(tracing.WithTracingDial(),
+		grpc.WithDefaultCallOptions(
+			grpc.PerRPCCredentials(token.NewPerRPCCredentials(tokenGenerator)),
+		),
+		grpc.WithTransportCredentials(
+			grpcfd.TransportCredentials(
+				credentials.NewTLS(tlsClientConfig))),
+		grpcfd.WithChainStreamInterceptor(),
+		grpcfd.WithChainUnaryInterceptor(),
+	)
+	dialOptions = append(dialOptions, additionalOptions...)
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid proxy URL")
+		}
+		dialer, err := proxydial.New(proxyURL, cfg.NoProxy)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid proxy")
+		}
+		dialOptions = append(dialOptions, grpc.WithContextDialer(dialer))
+	}
+	var callOptions []grpc.CallOption
+	if cfg.MaxRecvMsgSize > 0 {
+		callOptions = append(callOptions, grpc.MaxCallRecvMsgSize(cfg.MaxRecvMsgSize))
+	}
+	if cfg.MaxSendMsgSize > 0 {
+		callOptions = append(callOptions, grpc.MaxCallSendMsgSize(cfg.MaxSendMsgSize))
+	}
+	switch cfg.Compression {
+	case "":
+	case gzip.Name:
+		callOptions = append(callOptions, grpc.UseCompressor(gzip.Name))
+	default:
+		return nil, errors.Errorf("invalid compression: %s", cfg.Compression)
+	}
+	if len(callOptions) > 0 {
+		dialOptions = append(dialOptions, grpc.WithDefaultCallOptions(callOptions...))
+	}
+	if cfg.KeepaliveTime > 0 {
+		dialOptions = append(dialOptions, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                cfg.KeepaliveTime,
+			Timeout:             cfg.KeepaliveTimeout,
+			PermitWithoutStream: cfg.KeepaliveWithoutStream,
+		}))
+	}
+	return dialOptions, nil
+}
+
+// Dial waits for the socket of the current NSMgr URL to appear and dials it with backoff until it succeeds or
+// cfg.DialMaxWait has elapsed. Failed attempts fail over to the next NSMgr URL.
+func Dial(ctx context.Context, cfg *config.Config, urls *failover.URLs, dialOptions ...grpc.DialOption) (*url.URL, *grpc.ClientConn, error) {
+	if cfg.DialMaxWait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.DialMaxWait)
+		defer cancel()
+	}
+	dialOptions = append(dialOptions[:len(dialOptions):len(dialOptions)], grpc.WithBlock())
+
+	backoff := cfg.DialBackoff
+	for attempt := 1; ; attempt++ {
+		var err error
+		u := urls.Current()
+		if u.Scheme == "unix" {
+			_, err = os.Stat(u.Path)
+		}
+		if err == nil {
+			dialCtx, cancelDial := context.WithTimeout(ctx, cfg.DialTimeout)
+			var cc *grpc.ClientConn
+			cc, err = grpc.DialContext(dialCtx, grpcutils.URLToTarget(u), dialOptions...)
+			cancelDial()
+			if err == nil {
+				urls.Succeeded(u)
+				return u, cc, nil
+			}
+		}
+		urls.Failed(u)
+		log.FromContext(ctx).Warnf("attempt %d to dial NSMgr %s has failed, retrying in %s: %v", attempt, u.String(), backoff, err.Error())
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, errors.Wrapf(err, "NSMgr is not available after %d attempts", attempt)
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > cfg.DialMaxBackoff {
+			backoff = cfg.DialMaxBackoff
+		}
+	}
+}
+
+// Discover finds the node-local NSMgr pods through the Kubernetes API with backoff until they are found or
+// cfg.DialMaxWait has elapsed
+func Discover(ctx context.Context, cfg *config.Config) ([]url.URL, error) {
+	if cfg.DialMaxWait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.DialMaxWait)
+		defer cancel()
+	}
+
+	backoff := cfg.DialBackoff
+	for attempt := 1; ; attempt++ {
+		urls, err := k8sdiscovery.Discover(ctx, cfg.NodeName, cfg.NsmgrNamespace, cfg.NsmgrLabelSelector, cfg.NsmgrPort)
+		if err == nil {
+			return urls, nil
+		}
+		log.FromContext(ctx).Warnf("attempt %d to discover NSMgr has failed, retrying in %s: %v", attempt, backoff, err.Error())
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrapf(err, "NSMgr is not discovered after %d attempts", attempt)
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > cfg.DialMaxBackoff {
+			backoff = cfg.DialMaxBackoff
+		}
+	}
+}
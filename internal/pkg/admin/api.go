@@ -0,0 +1,316 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package admin provides a local gRPC API to change the set of the NSC connections at runtime
+package admin
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const serviceName = "nsc.admin.Admin"
+
+// AdminClient is the client API for the Admin service
+type AdminClient interface {
+	// AddNetworkService requests a connection for the Network Service URL and returns the connection ID
+	AddNetworkService(ctx context.Context, in *wrapperspb.StringValue, opts ...grpc.CallOption) (*wrapperspb.StringValue, error)
+	// CloseConnection closes the connection with the ID
+	CloseConnection(ctx context.Context, in *wrapperspb.StringValue, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ReselectConnection closes the connection with the ID and requests it again
+	ReselectConnection(ctx context.Context, in *wrapperspb.StringValue, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// CollectDebugBundle writes a debug bundle and returns its path
+	CollectDebugBundle(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*wrapperspb.StringValue, error)
+	// CapturePackets captures the packets of the connection interface and returns the pcap file path. The "id" field
+	// is the connection ID, optional "packets" and "duration", e.g. "10s", fields limit the capture.
+	CapturePackets(ctx context.Context, in *structpb.Struct, opts ...grpc.CallOption) (*wrapperspb.StringValue, error)
+	// StartPacketTrace starts VPP packet trace on the input node of the connection. The "id" field is the connection
+	// ID, optional "node" field overrides the input node and "packets" limits the number of traced packets.
+	StartPacketTrace(ctx context.Context, in *structpb.Struct, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// StopPacketTrace stops and clears VPP packet trace
+	StopPacketTrace(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// DumpPacketTrace returns the traced packets
+	DumpPacketTrace(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*wrapperspb.StringValue, error)
+	// StartMirror mirrors the traffic of the connection to a new interface and returns its description. The "id"
+	// field is the connection ID, "type" is memif or tap, "name" is the target name, optional "direction" is rx, tx
+	// or both.
+	StartMirror(ctx context.Context, in *structpb.Struct, opts ...grpc.CallOption) (*wrapperspb.StringValue, error)
+	// StopMirror stops mirroring the traffic of the connection with the ID and deletes the target interface
+	StopMirror(ctx context.Context, in *wrapperspb.StringValue, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// GetVersion returns the build info of the NSC and the version and the plugins of VPP
+	GetVersion(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*structpb.Struct, error)
+}
+
+type adminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewAdminClient creates a new AdminClient
+func NewAdminClient(cc grpc.ClientConnInterface) AdminClient {
+	return &adminClient{cc: cc}
+}
+
+func (c *adminClient) AddNetworkService(ctx context.Context, in *wrapperspb.StringValue, opts ...grpc.CallOption) (*wrapperspb.StringValue, error) {
+	out := new(wrapperspb.StringValue)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/AddNetworkService", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) CloseConnection(ctx context.Context, in *wrapperspb.StringValue, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/CloseConnection", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ReselectConnection(ctx context.Context, in *wrapperspb.StringValue, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/ReselectConnection", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) CollectDebugBundle(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*wrapperspb.StringValue, error) {
+	out := new(wrapperspb.StringValue)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/CollectDebugBundle", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) CapturePackets(ctx context.Context, in *structpb.Struct, opts ...grpc.CallOption) (*wrapperspb.StringValue, error) {
+	out := new(wrapperspb.StringValue)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/CapturePackets", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) StartPacketTrace(ctx context.Context, in *structpb.Struct, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/StartPacketTrace", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) StopPacketTrace(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/StopPacketTrace", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) DumpPacketTrace(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*wrapperspb.StringValue, error) {
+	out := new(wrapperspb.StringValue)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/DumpPacketTrace", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) StartMirror(ctx context.Context, in *structpb.Struct, opts ...grpc.CallOption) (*wrapperspb.StringValue, error) {
+	out := new(wrapperspb.StringValue)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/StartMirror", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) StopMirror(ctx context.Context, in *wrapperspb.StringValue, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/StopMirror", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetVersion(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*structpb.Struct, error) {
+	out := new(structpb.Struct)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/GetVersion", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServer is the server API for the Admin service
+type AdminServer interface {
+	// AddNetworkService requests a connection for the Network Service URL and returns the connection ID
+	AddNetworkService(context.Context, *wrapperspb.StringValue) (*wrapperspb.StringValue, error)
+	// CloseConnection closes the connection with the ID
+	CloseConnection(context.Context, *wrapperspb.StringValue) (*emptypb.Empty, error)
+	// ReselectConnection closes the connection with the ID and requests it again
+	ReselectConnection(context.Context, *wrapperspb.StringValue) (*emptypb.Empty, error)
+	// CollectDebugBundle writes a debug bundle and returns its path
+	CollectDebugBundle(context.Context, *emptypb.Empty) (*wrapperspb.StringValue, error)
+	// CapturePackets captures the packets of the connection interface and returns the pcap file path
+	CapturePackets(context.Context, *structpb.Struct) (*wrapperspb.StringValue, error)
+	// StartPacketTrace starts VPP packet trace on the input node of the connection
+	StartPacketTrace(context.Context, *structpb.Struct) (*emptypb.Empty, error)
+	// StopPacketTrace stops and clears VPP packet trace
+	StopPacketTrace(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	// DumpPacketTrace returns the traced packets
+	DumpPacketTrace(context.Context, *emptypb.Empty) (*wrapperspb.StringValue, error)
+	// StartMirror mirrors the traffic of the connection to a new interface and returns its description
+	StartMirror(context.Context, *structpb.Struct) (*wrapperspb.StringValue, error)
+	// StopMirror stops mirroring the traffic of the connection with the ID and deletes the target interface
+	StopMirror(context.Context, *wrapperspb.StringValue) (*emptypb.Empty, error)
+	// GetVersion returns the build info of the NSC and the version and the plugins of VPP
+	GetVersion(context.Context, *emptypb.Empty) (*structpb.Struct, error)
+}
+
+// UnimplementedAdminServer can be embedded to have forward compatible implementations
+type UnimplementedAdminServer struct{}
+
+// AddNetworkService is not implemented
+func (*UnimplementedAdminServer) AddNetworkService(context.Context, *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddNetworkService not implemented")
+}
+
+// CloseConnection is not implemented
+func (*UnimplementedAdminServer) CloseConnection(context.Context, *wrapperspb.StringValue) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CloseConnection not implemented")
+}
+
+// ReselectConnection is not implemented
+func (*UnimplementedAdminServer) ReselectConnection(context.Context, *wrapperspb.StringValue) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReselectConnection not implemented")
+}
+
+// CollectDebugBundle is not implemented
+func (*UnimplementedAdminServer) CollectDebugBundle(context.Context, *emptypb.Empty) (*wrapperspb.StringValue, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CollectDebugBundle not implemented")
+}
+
+// CapturePackets is not implemented
+func (*UnimplementedAdminServer) CapturePackets(context.Context, *structpb.Struct) (*wrapperspb.StringValue, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CapturePackets not implemented")
+}
+
+// StartPacketTrace is not implemented
+func (*UnimplementedAdminServer) StartPacketTrace(context.Context, *structpb.Struct) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartPacketTrace not implemented")
+}
+
+// StopPacketTrace is not implemented
+func (*UnimplementedAdminServer) StopPacketTrace(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopPacketTrace not implemented")
+}
+
+// DumpPacketTrace is not implemented
+func (*UnimplementedAdminServer) DumpPacketTrace(context.Context, *emptypb.Empty) (*wrapperspb.StringValue, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DumpPacketTrace not implemented")
+}
+
+// StartMirror is not implemented
+func (*UnimplementedAdminServer) StartMirror(context.Context, *structpb.Struct) (*wrapperspb.StringValue, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartMirror not implemented")
+}
+
+// StopMirror is not implemented
+func (*UnimplementedAdminServer) StopMirror(context.Context, *wrapperspb.StringValue) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopMirror not implemented")
+}
+
+// GetVersion is not implemented
+func (*UnimplementedAdminServer) GetVersion(context.Context, *emptypb.Empty) (*structpb.Struct, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVersion not implemented")
+}
+
+// RegisterAdminServer registers srv on the gRPC server s
+func RegisterAdminServer(s grpc.ServiceRegistrar, srv AdminServer) {
+	s.RegisterService(&adminServiceDesc, srv)
+}
+
+func unaryHandler(call func(srv AdminServer, ctx context.Context, in interface{}) (interface{}, error), method string, newIn func() interface{}) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: method,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := newIn()
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return call(srv.(AdminServer), ctx, in)
+			}
+			info := &grpc.UnaryServerInfo{
+				Server:     srv,
+				FullMethod: "/" + serviceName + "/" + method,
+			}
+			return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return call(srv.(AdminServer), ctx, req)
+			})
+		},
+	}
+}
+
+func newStringValue() interface{} { return new(wrapperspb.StringValue) }
+
+func newEmpty() interface{} { return new(emptypb.Empty) }
+
+func newStruct() interface{} { return new(structpb.Struct) }
+
+var adminServiceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryHandler(func(srv AdminServer, ctx context.Context, in interface{}) (interface{}, error) {
+			return srv.AddNetworkService(ctx, in.(*wrapperspb.StringValue))
+		}, "AddNetworkService", newStringValue),
+		unaryHandler(func(srv AdminServer, ctx context.Context, in interface{}) (interface{}, error) {
+			return srv.CloseConnection(ctx, in.(*wrapperspb.StringValue))
+		}, "CloseConnection", newStringValue),
+		unaryHandler(func(srv AdminServer, ctx context.Context, in interface{}) (interface{}, error) {
+			return srv.ReselectConnection(ctx, in.(*wrapperspb.StringValue))
+		}, "ReselectConnection", newStringValue),
+		unaryHandler(func(srv AdminServer, ctx context.Context, in interface{}) (interface{}, error) {
+			return srv.CollectDebugBundle(ctx, in.(*emptypb.Empty))
+		}, "CollectDebugBundle", newEmpty),
+		unaryHandler(func(srv AdminServer, ctx context.Context, in interface{}) (interface{}, error) {
+			return srv.CapturePackets(ctx, in.(*structpb.Struct))
+		}, "CapturePackets", newStruct),
+		unaryHandler(func(srv AdminServer, ctx context.Context, in interface{}) (interface{}, error) {
+			return srv.StartPacketTrace(ctx, in.(*structpb.Struct))
+		}, "StartPacketTrace", newStruct),
+		unaryHandler(func(srv AdminServer, ctx context.Context, in interface{}) (interface{}, error) {
+			return srv.StopPacketTrace(ctx, in.(*emptypb.Empty))
+		}, "StopPacketTrace", newEmpty),
+		unaryHandler(func(srv AdminServer, ctx context.Context, in interface{}) (interface{}, error) {
+			return srv.DumpPacketTrace(ctx, in.(*emptypb.Empty))
+		}, "DumpPacketTrace", newEmpty),
+		unaryHandler(func(srv AdminServer, ctx context.Context, in interface{}) (interface{}, error) {
+			return srv.StartMirror(ctx, in.(*structpb.Struct))
+		}, "StartMirror", newStruct),
+		unaryHandler(func(srv AdminServer, ctx context.Context, in interface{}) (interface{}, error) {
+			return srv.StopMirror(ctx, in.(*wrapperspb.StringValue))
+		}, "StopMirror", newStringValue),
+		unaryHandler(func(srv AdminServer, ctx context.Context, in interface{}) (interface{}, error) {
+			return srv.GetVersion(ctx, in.(*emptypb.Empty))
+		}, "GetVersion", newEmpty),
+	},
+	Streams: []grpc.StreamDesc{},
+}
@@ -0,0 +1,315 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/url"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/extend"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// Manager is the set of the NSC connections changed by the Admin service
+type Manager interface {
+	Add(ctx context.Context, u *url.URL) (string, error)
+	Close(ctx context.Context, id string) error
+	Reselect(ctx context.Context, id string) error
+}
+
+const defaultTracePackets = 50
+
+// CollectFunc writes a debug bundle and returns its path
+type CollectFunc func(ctx context.Context) (string, error)
+
+// CaptureFunc captures up to packets packets of the connection interface for the duration and returns the pcap
+// file path, zero values mean the defaults
+type CaptureFunc func(ctx context.Context, id string, packets uint32, duration time.Duration) (string, error)
+
+// VersionFunc returns the version info marshaled to JSON object
+type VersionFunc func(ctx context.Context) (interface{}, error)
+
+// PacketTracer runs VPP packet traces
+type PacketTracer interface {
+	Start(ctx context.Context, id, node string, packets uint32) error
+	Stop(ctx context.Context) error
+	Dump(ctx context.Context) (string, error)
+}
+
+// Mirror mirrors the traffic of the connections
+type Mirror interface {
+	Start(ctx context.Context, id, targetType, name, direction string) (string, error)
+	Stop(ctx context.Context, id string) error
+}
+
+type adminServer struct {
+	chainCtx context.Context
+	manager  Manager
+	collect  CollectFunc
+	capture  CaptureFunc
+	tracer   PacketTracer
+	mirror   Mirror
+	version  VersionFunc
+}
+
+// Option is an option pattern for NewServer
+type Option func(s *adminServer)
+
+// WithDebugBundle sets the function writing debug bundles, CollectDebugBundle fails if it isn't set
+func WithDebugBundle(collect CollectFunc) Option {
+	return func(s *adminServer) {
+		s.collect = collect
+	}
+}
+
+// WithPacketCapture sets the function capturing the packets, CapturePackets fails if it isn't set
+func WithPacketCapture(capture CaptureFunc) Option {
+	return func(s *adminServer) {
+		s.capture = capture
+	}
+}
+
+// WithPacketTracer sets the VPP packet tracer, packet trace calls fail if it isn't set
+func WithPacketTracer(tracer PacketTracer) Option {
+	return func(s *adminServer) {
+		s.tracer = tracer
+	}
+}
+
+// WithMirror sets the traffic mirror, mirror calls fail if it isn't set
+func WithMirror(mirror Mirror) Option {
+	return func(s *adminServer) {
+		s.mirror = mirror
+	}
+}
+
+// WithVersion sets the function returning the version info, GetVersion fails if it isn't set
+func WithVersion(version VersionFunc) Option {
+	return func(s *adminServer) {
+		s.version = version
+	}
+}
+
+// NewServer creates a new AdminServer changing the connections of the manager. Connections are requested with the
+// values of chainCtx, e.g. the logger, bounded by the deadline of the gRPC call. The connection lifetime is bound to
+// the manager context, so the connections outlive the gRPC calls.
+func NewServer(chainCtx context.Context, manager Manager, opts ...Option) AdminServer {
+	s := &adminServer{
+		chainCtx: chainCtx,
+		manager:  manager,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// requestContext returns the context of the gRPC call with the values of chainCtx, so the manager requests are
+// canceled with the call
+func (s *adminServer) requestContext(ctx context.Context) context.Context {
+	return extend.WithValuesFromContext(ctx, s.chainCtx)
+}
+
+func (s *adminServer) AddNetworkService(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+	u, err := url.Parse(in.GetValue())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid Network Service URL %q: %s", in.GetValue(), err.Error())
+	}
+	log.FromContext(ctx).Infof("admin: adding Network Service %s", u.String())
+
+	id, err := s.manager.Add(s.requestContext(ctx), u)
+	if err != nil {
+		return nil, err
+	}
+	return wrapperspb.String(id), nil
+}
+
+func (s *adminServer) CloseConnection(ctx context.Context, in *wrapperspb.StringValue) (*emptypb.Empty, error) {
+	log.FromContext(ctx).Infof("admin: closing connection %s", in.GetValue())
+
+	if err := s.manager.Close(s.requestContext(ctx), in.GetValue()); err != nil {
+		return nil, err
+	}
+	return new(emptypb.Empty), nil
+}
+
+func (s *adminServer) ReselectConnection(ctx context.Context, in *wrapperspb.StringValue) (*emptypb.Empty, error) {
+	log.FromContext(ctx).Infof("admin: reselecting connection %s", in.GetValue())
+
+	if err := s.manager.Reselect(s.requestContext(ctx), in.GetValue()); err != nil {
+		return nil, err
+	}
+	return new(emptypb.Empty), nil
+}
+
+func (s *adminServer) CollectDebugBundle(ctx context.Context, _ *emptypb.Empty) (*wrapperspb.StringValue, error) {
+	if s.collect == nil {
+		return nil, status.Error(codes.FailedPrecondition, "debug bundles are disabled")
+	}
+	log.FromContext(ctx).Info("admin: collecting debug bundle")
+
+	path, err := s.collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return wrapperspb.String(path), nil
+}
+
+func (s *adminServer) CapturePackets(ctx context.Context, in *structpb.Struct) (*wrapperspb.StringValue, error) {
+	if s.capture == nil {
+		return nil, status.Error(codes.FailedPrecondition, "packet capture is disabled")
+	}
+	fields := in.GetFields()
+	id := fields["id"].GetStringValue()
+	if id == "" {
+		return nil, status.Error(codes.InvalidArgument, "connection id is required")
+	}
+	packets, err := packetsField(in)
+	if err != nil {
+		return nil, err
+	}
+	var duration time.Duration
+	if value := fields["duration"].GetStringValue(); value != "" {
+		if duration, err = time.ParseDuration(value); err != nil || duration < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid duration %q", value)
+		}
+	}
+	log.FromContext(ctx).Infof("admin: capturing packets of connection %s", id)
+
+	path, err := s.capture(ctx, id, packets, duration)
+	if err != nil {
+		return nil, err
+	}
+	return wrapperspb.String(path), nil
+}
+
+func (s *adminServer) StartPacketTrace(ctx context.Context, in *structpb.Struct) (*emptypb.Empty, error) {
+	if s.tracer == nil {
+		return nil, status.Error(codes.FailedPrecondition, "packet trace is disabled")
+	}
+	id := in.GetFields()["id"].GetStringValue()
+	node := in.GetFields()["node"].GetStringValue()
+	if id == "" && node == "" {
+		return nil, status.Error(codes.InvalidArgument, "connection id or node is required")
+	}
+	packets, err := packetsField(in)
+	if err != nil {
+		return nil, err
+	}
+	if packets == 0 {
+		packets = defaultTracePackets
+	}
+	log.FromContext(ctx).Infof("admin: starting packet trace of connection %s", id)
+
+	if err = s.tracer.Start(ctx, id, node, packets); err != nil {
+		return nil, err
+	}
+	return new(emptypb.Empty), nil
+}
+
+func (s *adminServer) StopPacketTrace(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	if s.tracer == nil {
+		return nil, status.Error(codes.FailedPrecondition, "packet trace is disabled")
+	}
+	log.FromContext(ctx).Info("admin: stopping packet trace")
+
+	if err := s.tracer.Stop(ctx); err != nil {
+		return nil, err
+	}
+	return new(emptypb.Empty), nil
+}
+
+func (s *adminServer) DumpPacketTrace(ctx context.Context, _ *emptypb.Empty) (*wrapperspb.StringValue, error) {
+	if s.tracer == nil {
+		return nil, status.Error(codes.FailedPrecondition, "packet trace is disabled")
+	}
+	trace, err := s.tracer.Dump(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return wrapperspb.String(trace), nil
+}
+
+func (s *adminServer) StartMirror(ctx context.Context, in *structpb.Struct) (*wrapperspb.StringValue, error) {
+	if s.mirror == nil {
+		return nil, status.Error(codes.FailedPrecondition, "traffic mirroring is disabled")
+	}
+	fields := in.GetFields()
+	id := fields["id"].GetStringValue()
+	targetType := fields["type"].GetStringValue()
+	name := fields["name"].GetStringValue()
+	if id == "" || targetType == "" || name == "" {
+		return nil, status.Error(codes.InvalidArgument, "connection id, target type and name are required")
+	}
+	log.FromContext(ctx).Infof("admin: mirroring traffic of connection %s to %s %s", id, targetType, name)
+
+	target, err := s.mirror.Start(ctx, id, targetType, name, fields["direction"].GetStringValue())
+	if err != nil {
+		return nil, err
+	}
+	return wrapperspb.String(target), nil
+}
+
+func (s *adminServer) StopMirror(ctx context.Context, in *wrapperspb.StringValue) (*emptypb.Empty, error) {
+	if s.mirror == nil {
+		return nil, status.Error(codes.FailedPrecondition, "traffic mirroring is disabled")
+	}
+	log.FromContext(ctx).Infof("admin: stopping mirror of connection %s", in.GetValue())
+
+	if err := s.mirror.Stop(ctx, in.GetValue()); err != nil {
+		return nil, err
+	}
+	return new(emptypb.Empty), nil
+}
+
+func (s *adminServer) GetVersion(ctx context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	if s.version == nil {
+		return nil, status.Error(codes.FailedPrecondition, "version info is disabled")
+	}
+
+	info, err := s.version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal version info: %s", err.Error())
+	}
+	fields := make(map[string]interface{})
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, status.Errorf(codes.Internal, "version info is not a JSON object: %s", err.Error())
+	}
+	return structpb.NewStruct(fields)
+}
+
+// packetsField returns the optional "packets" field of the request
+func packetsField(in *structpb.Struct) (uint32, error) {
+	packets := in.GetFields()["packets"].GetNumberValue()
+	if packets < 0 || packets > math.MaxUint32 || packets != math.Trunc(packets) {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid number of packets %v", packets)
+	}
+	return uint32(packets), nil
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/admin"
+)
+
+type chainKey struct{}
+
+// testManager records the contexts of the manager calls
+type testManager struct {
+	ctx context.Context
+}
+
+func (m *testManager) Add(ctx context.Context, _ *url.URL) (string, error) {
+	m.ctx = ctx
+	return "id", nil
+}
+
+func (m *testManager) Close(ctx context.Context, _ string) error {
+	m.ctx = ctx
+	return nil
+}
+
+func (m *testManager) Reselect(ctx context.Context, _ string) error {
+	m.ctx = ctx
+	return nil
+}
+
+func TestServer_RequestContext(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		call func(ctx context.Context, s admin.AdminServer) error
+	}{
+		{
+			name: "add",
+			call: func(ctx context.Context, s admin.AdminServer) error {
+				_, err := s.AddNetworkService(ctx, wrapperspb.String("kernel://my-service/nsm-1"))
+				return err
+			},
+		},
+		{
+			name: "close",
+			call: func(ctx context.Context, s admin.AdminServer) error {
+				_, err := s.CloseConnection(ctx, wrapperspb.String("id"))
+				return err
+			},
+		},
+		{
+			name: "reselect",
+			call: func(ctx context.Context, s admin.AdminServer) error {
+				_, err := s.ReselectConnection(ctx, wrapperspb.String("id"))
+				return err
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			chainCtx := context.WithValue(context.Background(), chainKey{}, "chain")
+			manager := new(testManager)
+			s := admin.NewServer(chainCtx, manager)
+
+			rpcDeadline := time.Now().Add(time.Minute)
+			rpcCtx, cancelRPC := context.WithDeadline(context.Background(), rpcDeadline)
+			require.NoError(t, tc.call(rpcCtx, s))
+
+			deadline, ok := manager.ctx.Deadline()
+			require.True(t, ok)
+			require.Equal(t, rpcDeadline, deadline)
+			require.Equal(t, "chain", manager.ctx.Value(chainKey{}))
+
+			cancelRPC()
+			require.ErrorIs(t, manager.ctx.Err(), context.Canceled)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apitrace
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/loglevels"
+)
+
+type apiTraceClient struct {
+	trace *Trace
+}
+
+// NewClient returns a client logging the VPP API trace when the Request fails after a failed VPP API call
+func (t *Trace) NewClient() networkservice.NetworkServiceClient {
+	return &apiTraceClient{trace: t}
+}
+
+func (c *apiTraceClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	failures := c.trace.Failures()
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil && c.trace.Failures() != failures {
+		log.FromContext(ctx).WithField(loglevels.ComponentField, loglevels.VPPComponent).Errorf("VPP API call has failed, recent VPP API messages:\n%s", c.trace.Dump())
+	}
+	return conn, err
+}
+
+func (c *apiTraceClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
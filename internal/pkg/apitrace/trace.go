@@ -0,0 +1,209 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package apitrace records the recent VPP API messages, so the failed VPP calls can be reproduced offline
+package apitrace
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/edwarnicke/vpphelper"
+)
+
+// ignored are the messages sent periodically by the liveness checks
+var ignored = map[string]struct{}{
+	"control_ping":       {},
+	"control_ping_reply": {},
+}
+
+// Record is a traced VPP API message
+type Record struct {
+	Time     time.Time
+	Message  api.Message
+	Received bool
+	Err      error
+}
+
+// String returns a single line representation of the record
+func (r *Record) String() string {
+	direction := "->"
+	if r.Received {
+		direction = "<-"
+	}
+	if r.Err != nil {
+		return fmt.Sprintf("%s %s error: %s", r.Time.Format(time.RFC3339Nano), direction, r.Err.Error())
+	}
+	return fmt.Sprintf("%s %s %s %+v", r.Time.Format(time.RFC3339Nano), direction, r.Message.GetMessageName(), r.Message)
+}
+
+// Trace keeps the recent VPP API messages sent and received through the wrapped connections
+type Trace struct {
+	mu       sync.Mutex
+	records  []Record
+	next     int
+	failures uint64
+}
+
+// New creates a Trace keeping up to size recent messages
+func New(size int) *Trace {
+	return &Trace{
+		records: make([]Record, 0, size),
+	}
+}
+
+// Wrap returns the connection recording the messages of Invoke and NewStream calls to the trace
+func (t *Trace) Wrap(conn vpphelper.Connection) vpphelper.Connection {
+	if conn == nil {
+		return nil
+	}
+	return &tracedConnection{
+		Connection: conn,
+		trace:      t,
+	}
+}
+
+// Records returns the recorded messages, the oldest first
+func (t *Trace) Records() []Record {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make([]Record, 0, len(t.records))
+	for i := range t.records {
+		result = append(result, t.records[(t.next+i)%len(t.records)])
+	}
+	return result
+}
+
+// Dump returns the recorded messages one per line
+func (t *Trace) Dump() string {
+	var buf bytes.Buffer
+	for _, record := range t.Records() {
+		buf.WriteString(record.String())
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// Source returns the recorded messages for the debug bundle
+func (t *Trace) Source(_ context.Context) ([]byte, error) {
+	return []byte(t.Dump()), nil
+}
+
+// Failures returns the number of the failed VPP API calls
+func (t *Trace) Failures() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.failures
+}
+
+func (t *Trace) record(msg api.Message, received bool, err error) {
+	if msg != nil {
+		if _, ok := ignored[msg.GetMessageName()]; ok {
+			return
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err != nil || failed(msg) {
+		t.failures++
+	}
+	if cap(t.records) == 0 {
+		return
+	}
+	r := Record{
+		Time:     time.Now(),
+		Message:  msg,
+		Received: received,
+		Err:      err,
+	}
+	if len(t.records) < cap(t.records) {
+		t.records = append(t.records, r)
+		return
+	}
+	t.records[t.next] = r
+	t.next = (t.next + 1) % len(t.records)
+}
+
+// failed returns true if the message is a reply with non zero retval
+func failed(msg api.Message) bool {
+	if msg == nil || msg.GetMessageType() != api.ReplyMessage {
+		return false
+	}
+	v := reflect.Indirect(reflect.ValueOf(msg))
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+	retval := v.FieldByName("Retval")
+	return retval.IsValid() && retval.CanInt() && retval.Int() != 0
+}
+
+type tracedConnection struct {
+	vpphelper.Connection
+	trace *Trace
+}
+
+func (c *tracedConnection) Invoke(ctx context.Context, req, reply api.Message) error {
+	c.trace.record(req, false, nil)
+	err := c.Connection.Invoke(ctx, req, reply)
+	if err != nil {
+		c.trace.record(nil, true, err)
+		return err
+	}
+	c.trace.record(reply, true, nil)
+	return nil
+}
+
+func (c *tracedConnection) NewStream(ctx context.Context, options ...api.StreamOption) (api.Stream, error) {
+	stream, err := c.Connection.NewStream(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedStream{
+		Stream: stream,
+		trace:  c.trace,
+	}, nil
+}
+
+type tracedStream struct {
+	api.Stream
+	trace *Trace
+}
+
+func (s *tracedStream) SendMsg(msg api.Message) error {
+	s.trace.record(msg, false, nil)
+	return s.Stream.SendMsg(msg)
+}
+
+func (s *tracedStream) RecvMsg() (api.Message, error) {
+	msg, err := s.Stream.RecvMsg()
+	if err != nil {
+		if s.Context().Err() == nil {
+			s.trace.record(nil, true, err)
+		}
+		return nil, err
+	}
+	s.trace.record(msg, true, nil)
+	return msg, nil
+}
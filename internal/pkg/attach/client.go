@@ -0,0 +1,201 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package attach cross connects the NSM connection interfaces with the local VPP interfaces exposing the
+// connections to the workloads not able to use memif, or with each other
+package attach
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/l2"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/heal"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/postpone"
+)
+
+// Interface creates and deletes the local VPP interface attached to the NSM connection interface
+type Interface interface {
+	Create(ctx context.Context, vppConn api.Connection) (interface_types.InterfaceIndex, error)
+	Delete(ctx context.Context, vppConn api.Connection, swIfIndex interface_types.InterfaceIndex) error
+	String() string
+}
+
+// Configurer is implemented by the Interfaces configured with the connection on every request
+type Configurer interface {
+	Configure(ctx context.Context, conn *networkservice.Connection) error
+}
+
+type contextKey struct{}
+
+// WithInterface returns a context requesting the connection with the local interface iface attached
+func WithInterface(ctx context.Context, iface Interface) context.Context {
+	return context.WithValue(ctx, contextKey{}, iface)
+}
+
+// Attacher keeps the connections with the attached local interfaces
+type Attacher struct {
+	vppConn  api.Connection
+	attached sync.Map
+	peers    *peers
+}
+
+// New creates a new Attacher
+func New(vppConn api.Connection) *Attacher {
+	return &Attacher{
+		vppConn: vppConn,
+		peers:   newPeers(),
+	}
+}
+
+// NewClient returns a client chain element attaching the local interface requested with WithInterface or the peer
+// connection requested with WithPeer. It should be placed before the mechanism client to see the interface index.
+func (a *Attacher) NewClient() networkservice.NetworkServiceClient {
+	return &attachClient{attacher: a}
+}
+
+// LivenessCheck wraps the check, so the connections with the attached interfaces are always alive: the traffic
+// coming from the NSM connection is passed to the local interface and never reaches VPP itself. Nil check is
+// returned as is.
+func (a *Attacher) LivenessCheck(check heal.LivenessCheck) heal.LivenessCheck {
+	if check == nil {
+		return nil
+	}
+	return func(deadlineCtx context.Context, conn *networkservice.Connection) bool {
+		if _, ok := a.attached.Load(conn.GetId()); ok {
+			return true
+		}
+		return check(deadlineCtx, conn)
+	}
+}
+
+type attachClient struct {
+	attacher *Attacher
+}
+
+func (c *attachClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	iface, _ := ctx.Value(contextKey{}).(Interface)
+	if a, ok := load(ctx); ok {
+		iface = a.iface
+	}
+
+	postponeCtxFunc := postpone.ContextWithValues(ctx)
+
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if iface != nil {
+		err = c.attach(ctx, conn, iface)
+	} else {
+		err = c.attacher.connect(ctx, conn)
+	}
+	if err != nil {
+		closeCtx, cancelClose := postponeCtxFunc()
+		defer cancelClose()
+
+		if _, closeErr := c.Close(closeCtx, conn, opts...); closeErr != nil {
+			err = errors.Wrapf(err, "connection closed with error: %s", closeErr.Error())
+		}
+
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (c *attachClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	c.attacher.disconnect(ctx, conn)
+	if a, ok := loadAndDelete(ctx); ok {
+		c.attacher.attached.Delete(conn.GetId())
+		if err := xconnect(ctx, c.attacher.vppConn, a.nsmIfIndex, a.swIfIndex, false); err != nil {
+			log.FromContext(ctx).Error(err)
+		}
+		if err := a.iface.Delete(ctx, c.attacher.vppConn, a.swIfIndex); err != nil {
+			log.FromContext(ctx).Error(err)
+		}
+	}
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
+
+func (c *attachClient) attach(ctx context.Context, conn *networkservice.Connection, iface Interface) error {
+	nsmIfIndex, ok := ifindex.Load(ctx, true)
+	if !ok {
+		return errors.Errorf("no VPP interface found to attach %s", iface.String())
+	}
+
+	a, loaded := load(ctx)
+	if !loaded {
+		swIfIndex, err := iface.Create(ctx, c.attacher.vppConn)
+		if err != nil {
+			return err
+		}
+		a = &attachment{
+			iface:     iface,
+			swIfIndex: swIfIndex,
+		}
+		store(ctx, a)
+	}
+	if !loaded || a.nsmIfIndex != nsmIfIndex {
+		// The NSM interface is created again on reselect, so the cross connect is updated to the new one
+		a.nsmIfIndex = nsmIfIndex
+		if err := xconnect(ctx, c.attacher.vppConn, a.nsmIfIndex, a.swIfIndex, true); err != nil {
+			return err
+		}
+		c.attacher.attached.Store(conn.GetId(), struct{}{})
+
+		log.FromContext(ctx).Infof("%s is attached to the connection %s", iface.String(), conn.GetId())
+	}
+
+	// The connection context may change on every request
+	if configurer, ok := a.iface.(Configurer); ok {
+		return configurer.Configure(ctx, conn)
+	}
+	return nil
+}
+
+func xconnect(ctx context.Context, vppConn api.Connection, nsmIfIndex, swIfIndex interface_types.InterfaceIndex, enable bool) error {
+	for _, pair := range [][2]interface_types.InterfaceIndex{{nsmIfIndex, swIfIndex}, {swIfIndex, nsmIfIndex}} {
+		now := time.Now()
+		if _, err := l2.NewServiceClient(vppConn).SwInterfaceSetL2Xconnect(ctx, &l2.SwInterfaceSetL2Xconnect{
+			RxSwIfIndex: pair[0],
+			TxSwIfIndex: pair[1],
+			Enable:      enable,
+		}); err != nil {
+			return errors.Wrap(err, "vppapi SwInterfaceSetL2Xconnect returned error")
+		}
+		log.FromContext(ctx).
+			WithField("RxSwIfIndex", pair[0]).
+			WithField("TxSwIfIndex", pair[1]).
+			WithField("Enable", enable).
+			WithField("duration", time.Since(now)).
+			WithField("vppapi", "SwInterfaceSetL2Xconnect").Debug("completed")
+	}
+	return nil
+}
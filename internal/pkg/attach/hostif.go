@@ -0,0 +1,135 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attach
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/networkservicemesh/govpp/binapi/af_packet"
+	"github.com/networkservicemesh/govpp/binapi/af_xdp"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+type afPacket struct {
+	hostIfName string
+}
+
+// NewAfPacket returns an Interface attaching the existing host interface hostIfName to VPP with AF_PACKET
+func NewAfPacket(hostIfName string) Interface {
+	return &afPacket{
+		hostIfName: hostIfName,
+	}
+}
+
+func (a *afPacket) Create(ctx context.Context, vppConn api.Connection) (interface_types.InterfaceIndex, error) {
+	now := time.Now()
+	rsp, err := af_packet.NewServiceClient(vppConn).AfPacketCreateV3(ctx, &af_packet.AfPacketCreateV3{
+		Mode:            af_packet.AF_PACKET_API_MODE_ETHERNET,
+		UseRandomHwAddr: true,
+		HostIfName:      a.hostIfName,
+		NumRxQueues:     1,
+		NumTxQueues:     1,
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "vppapi AfPacketCreateV3 returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", rsp.SwIfIndex).
+		WithField("HostIfName", a.hostIfName).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "AfPacketCreateV3").Debug("completed")
+
+	if err := up(ctx, vppConn, rsp.SwIfIndex); err != nil {
+		return 0, err
+	}
+	return rsp.SwIfIndex, nil
+}
+
+func (a *afPacket) Delete(ctx context.Context, vppConn api.Connection, _ interface_types.InterfaceIndex) error {
+	now := time.Now()
+	if _, err := af_packet.NewServiceClient(vppConn).AfPacketDelete(ctx, &af_packet.AfPacketDelete{
+		HostIfName: a.hostIfName,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi AfPacketDelete returned error")
+	}
+	log.FromContext(ctx).
+		WithField("HostIfName", a.hostIfName).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "AfPacketDelete").Debug("completed")
+	return nil
+}
+
+func (a *afPacket) String() string {
+	return fmt.Sprintf("af_packet %s", a.hostIfName)
+}
+
+type afXDP struct {
+	hostIfName string
+}
+
+// NewAfXDP returns an Interface attaching the existing host interface hostIfName to VPP with AF_XDP
+func NewAfXDP(hostIfName string) Interface {
+	return &afXDP{
+		hostIfName: hostIfName,
+	}
+}
+
+func (a *afXDP) Create(ctx context.Context, vppConn api.Connection) (interface_types.InterfaceIndex, error) {
+	now := time.Now()
+	rsp, err := af_xdp.NewServiceClient(vppConn).AfXdpCreateV2(ctx, &af_xdp.AfXdpCreateV2{
+		HostIf: a.hostIfName,
+		RxqNum: 1,
+		Mode:   af_xdp.AF_XDP_API_MODE_AUTO,
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "vppapi AfXdpCreateV2 returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", rsp.SwIfIndex).
+		WithField("HostIf", a.hostIfName).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "AfXdpCreateV2").Debug("completed")
+
+	if err := up(ctx, vppConn, rsp.SwIfIndex); err != nil {
+		return 0, err
+	}
+	return rsp.SwIfIndex, nil
+}
+
+func (a *afXDP) Delete(ctx context.Context, vppConn api.Connection, swIfIndex interface_types.InterfaceIndex) error {
+	now := time.Now()
+	if _, err := af_xdp.NewServiceClient(vppConn).AfXdpDelete(ctx, &af_xdp.AfXdpDelete{
+		SwIfIndex: swIfIndex,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi AfXdpDelete returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", swIfIndex).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "AfXdpDelete").Debug("completed")
+	return nil
+}
+
+func (a *afXDP) String() string {
+	return fmt.Sprintf("af_xdp %s", a.hostIfName)
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attach
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/memif"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+type memifMaster struct {
+	socketFile string
+	socketID   uint32
+}
+
+// NewMemif returns an ethernet memif Interface listening as the master on the socketFile, so a container sharing
+// the socket file directory connects to it
+func NewMemif(socketFile string) Interface {
+	return &memifMaster{
+		socketFile: socketFile,
+	}
+}
+
+func (m *memifMaster) Create(ctx context.Context, vppConn api.Connection) (interface_types.InterfaceIndex, error) {
+	now := time.Now()
+	socketRsp, err := memif.NewServiceClient(vppConn).MemifSocketFilenameAddDelV2(ctx, &memif.MemifSocketFilenameAddDelV2{
+		IsAdd:          true,
+		SocketID:       ^uint32(0),
+		SocketFilename: m.socketFile,
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "vppapi MemifSocketFilenameAddDelV2 returned error")
+	}
+	log.FromContext(ctx).
+		WithField("SocketID", socketRsp.SocketID).
+		WithField("SocketFilename", m.socketFile).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "MemifSocketFilenameAddDelV2").Debug("completed")
+	m.socketID = socketRsp.SocketID
+
+	now = time.Now()
+	rsp, err := memif.NewServiceClient(vppConn).MemifCreate(ctx, &memif.MemifCreate{
+		Role:     memif.MEMIF_ROLE_API_MASTER,
+		Mode:     memif.MEMIF_MODE_API_ETHERNET,
+		RxQueues: 1,
+		TxQueues: 1,
+		SocketID: m.socketID,
+	})
+	if err != nil {
+		m.deleteSocket(ctx, vppConn)
+		return 0, errors.Wrap(err, "vppapi MemifCreate returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", rsp.SwIfIndex).
+		WithField("SocketID", m.socketID).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "MemifCreate").Debug("completed")
+
+	if err := up(ctx, vppConn, rsp.SwIfIndex); err != nil {
+		return 0, err
+	}
+	return rsp.SwIfIndex, nil
+}
+
+func (m *memifMaster) Delete(ctx context.Context, vppConn api.Connection, swIfIndex interface_types.InterfaceIndex) error {
+	now := time.Now()
+	if _, err := memif.NewServiceClient(vppConn).MemifDelete(ctx, &memif.MemifDelete{
+		SwIfIndex: swIfIndex,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi MemifDelete returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", swIfIndex).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "MemifDelete").Debug("completed")
+
+	m.deleteSocket(ctx, vppConn)
+	return nil
+}
+
+// deleteSocket deletes the socket file registration, the file itself is removed by VPP
+func (m *memifMaster) deleteSocket(ctx context.Context, vppConn api.Connection) {
+	now := time.Now()
+	if _, err := memif.NewServiceClient(vppConn).MemifSocketFilenameAddDelV2(ctx, &memif.MemifSocketFilenameAddDelV2{
+		IsAdd:    false,
+		SocketID: m.socketID,
+	}); err != nil {
+		log.FromContext(ctx).Warnf("failed to delete memif socket %s: %s", m.socketFile, err.Error())
+		return
+	}
+	log.FromContext(ctx).
+		WithField("SocketID", m.socketID).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "MemifSocketFilenameAddDelV2").Debug("completed")
+}
+
+func (m *memifMaster) String() string {
+	return fmt.Sprintf("memif %s", m.socketFile)
+}
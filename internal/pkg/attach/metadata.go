@@ -0,0 +1,55 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attach
+
+import (
+	"context"
+
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+)
+
+type attachment struct {
+	iface      Interface
+	swIfIndex  interface_types.InterfaceIndex
+	nsmIfIndex interface_types.InterfaceIndex
+}
+
+type metadataKey struct{}
+
+func store(ctx context.Context, a *attachment) {
+	metadata.Map(ctx, true).Store(metadataKey{}, a)
+}
+
+func load(ctx context.Context) (*attachment, bool) {
+	rawValue, ok := metadata.Map(ctx, true).Load(metadataKey{})
+	if !ok {
+		return nil, false
+	}
+	a, ok := rawValue.(*attachment)
+	return a, ok
+}
+
+func loadAndDelete(ctx context.Context) (*attachment, bool) {
+	rawValue, ok := metadata.Map(ctx, true).LoadAndDelete(metadataKey{})
+	if !ok {
+		return nil, false
+	}
+	a, ok := rawValue.(*attachment)
+	return a, ok
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attach
+
+import (
+	"context"
+	"sync"
+
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+type peerContextKey struct{}
+
+// WithPeer returns a context requesting the connection L2 cross connected to the connection of the peer Network
+// Service, so VPP passes the traffic between the connections as a bump in the wire
+func WithPeer(ctx context.Context, networkService string) context.Context {
+	return context.WithValue(ctx, peerContextKey{}, networkService)
+}
+
+type peerConnection struct {
+	id        string
+	swIfIndex interface_types.InterfaceIndex
+	// xconnected is the peer interface index the connection interface is cross connected to
+	xconnected interface_types.InterfaceIndex
+}
+
+// peers keeps the pairs of the Network Services which connections are cross connected to each other. A Network
+// Service is expected to have a single connection.
+type peers struct {
+	mu          sync.Mutex
+	pairs       map[string]string
+	connections map[string]*peerConnection
+}
+
+func newPeers() *peers {
+	return &peers{
+		pairs:       make(map[string]string),
+		connections: make(map[string]*peerConnection),
+	}
+}
+
+// connect cross connects the connection interface to the interface of the peer connection if both are established
+func (a *Attacher) connect(ctx context.Context, conn *networkservice.Connection) error {
+	swIfIndex, ok := ifindex.Load(ctx, true)
+	if !ok {
+		return nil
+	}
+	service := conn.GetNetworkService()
+
+	a.peers.mu.Lock()
+	defer a.peers.mu.Unlock()
+
+	if peer, ok := ctx.Value(peerContextKey{}).(string); ok {
+		a.peers.pairs[service] = peer
+		a.peers.pairs[peer] = service
+	}
+	c, ok := a.peers.connections[service]
+	if !ok || c.swIfIndex != swIfIndex {
+		c = &peerConnection{id: conn.GetId(), swIfIndex: swIfIndex}
+		a.peers.connections[service] = c
+	}
+
+	peer, ok := a.peers.pairs[service]
+	if !ok {
+		return nil
+	}
+	a.attached.Store(c.id, struct{}{})
+	peerConn, ok := a.peers.connections[peer]
+	if !ok {
+		log.FromContext(ctx).Infof("connection %s waits for the peer Network Service %s", c.id, peer)
+		return nil
+	}
+	a.attached.Store(peerConn.id, struct{}{})
+	if c.xconnected == peerConn.swIfIndex && peerConn.xconnected == c.swIfIndex {
+		return nil
+	}
+
+	// Any of the interfaces is created again on reselect, so the cross connect is updated to the new one
+	if err := xconnect(ctx, a.vppConn, c.swIfIndex, peerConn.swIfIndex, true); err != nil {
+		return err
+	}
+	c.xconnected = peerConn.swIfIndex
+	peerConn.xconnected = c.swIfIndex
+
+	log.FromContext(ctx).Infof("connection %s is cross connected to the connection %s", c.id, peerConn.id)
+	return nil
+}
+
+// disconnect removes the cross connect of the closed connection, so the peer interface is back in L3 mode
+func (a *Attacher) disconnect(ctx context.Context, conn *networkservice.Connection) {
+	service := conn.GetNetworkService()
+
+	a.peers.mu.Lock()
+	defer a.peers.mu.Unlock()
+
+	c, ok := a.peers.connections[service]
+	if !ok || c.id != conn.GetId() {
+		return
+	}
+	delete(a.peers.connections, service)
+	a.attached.Delete(c.id)
+
+	peerConn, ok := a.peers.connections[a.peers.pairs[service]]
+	if !ok || peerConn.xconnected != c.swIfIndex {
+		return
+	}
+	peerConn.xconnected = 0
+	if err := xconnect(ctx, a.vppConn, c.swIfIndex, peerConn.swIfIndex, false); err != nil {
+		log.FromContext(ctx).Error(err)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attach
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/tapv2"
+	"github.com/pkg/errors"
+	"github.com/vishvananda/netlink"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+const tapRingSize = 1024
+
+type tap struct {
+	hostIfName string
+}
+
+// NewTap returns an Interface creating the tap interface hostIfName in the VPP network namespace, which is the pod
+// one for the started VPP. The IP context of the connection is applied to the kernel side of the tap, so the
+// standard socket applications use the connection.
+func NewTap(hostIfName string) Interface {
+	return &tap{
+		hostIfName: hostIfName,
+	}
+}
+
+func (t *tap) Create(ctx context.Context, vppConn api.Connection) (interface_types.InterfaceIndex, error) {
+	now := time.Now()
+	rsp, err := tapv2.NewServiceClient(vppConn).TapCreateV2(ctx, &tapv2.TapCreateV2{
+		ID:            ^uint32(0),
+		UseRandomMac:  true,
+		NumRxQueues:   1,
+		TxRingSz:      tapRingSize,
+		RxRingSz:      tapRingSize,
+		HostIfNameSet: true,
+		HostIfName:    t.hostIfName,
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "vppapi TapCreateV2 returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", rsp.SwIfIndex).
+		WithField("HostIfName", t.hostIfName).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "TapCreateV2").Debug("completed")
+
+	if err := up(ctx, vppConn, rsp.SwIfIndex); err != nil {
+		return 0, err
+	}
+	return rsp.SwIfIndex, nil
+}
+
+func (t *tap) Delete(ctx context.Context, vppConn api.Connection, swIfIndex interface_types.InterfaceIndex) error {
+	now := time.Now()
+	if _, err := tapv2.NewServiceClient(vppConn).TapDeleteV2(ctx, &tapv2.TapDeleteV2{
+		SwIfIndex: swIfIndex,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi TapDeleteV2 returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", swIfIndex).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "TapDeleteV2").Debug("completed")
+	return nil
+}
+
+// Configure applies the MTU, the source addresses and the routes of the connection to the kernel side of the tap
+func (t *tap) Configure(ctx context.Context, conn *networkservice.Connection) error {
+	link, err := netlink.LinkByName(t.hostIfName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find tap %s", t.hostIfName)
+	}
+	if mtu := conn.GetContext().GetMTU(); mtu > 0 {
+		if err = netlink.LinkSetMTU(link, int(mtu)); err != nil {
+			return errors.Wrapf(err, "failed to set MTU %d on tap %s", mtu, t.hostIfName)
+		}
+	}
+	if err = netlink.LinkSetUp(link); err != nil {
+		return errors.Wrapf(err, "failed to set tap %s up", t.hostIfName)
+	}
+
+	ipContext := conn.GetContext().GetIpContext()
+	for _, ipNet := range ipContext.GetSrcIPNets() {
+		if err = netlink.AddrReplace(link, &netlink.Addr{IPNet: ipNet}); err != nil {
+			return errors.Wrapf(err, "failed to add address %s to tap %s", ipNet.String(), t.hostIfName)
+		}
+	}
+	// The routes to reach the destination addresses go first, as they are the next hops of the other routes
+	routes := ipContext.GetDstIPRoutes()
+	routes = append(routes, ipContext.GetSrcRoutesWithExplicitNextHop()...)
+	for _, route := range routes {
+		if err = t.routeReplace(link, route); err != nil {
+			return err
+		}
+	}
+
+	log.FromContext(ctx).
+		WithField("HostIfName", t.hostIfName).
+		WithField("addresses", ipContext.GetSrcIpAddrs()).
+		WithField("routes", len(routes)).Debug("tap is configured")
+	return nil
+}
+
+func (t *tap) routeReplace(link netlink.Link, route *networkservice.Route) error {
+	dst := route.GetPrefixIPNet()
+	if dst == nil {
+		return errors.Errorf("invalid route prefix %s", route.GetPrefix())
+	}
+	kernelRoute := &netlink.Route{
+		LinkIndex: link.Attrs().Index,
+		Dst:       dst,
+		Scope:     netlink.SCOPE_LINK,
+	}
+	if nextHop := route.GetNextHopIP(); nextHop != nil && !dst.Contains(nextHop) {
+		kernelRoute.Gw = nextHop
+		kernelRoute.Scope = netlink.SCOPE_UNIVERSE
+	}
+	if err := netlink.RouteReplace(kernelRoute); err != nil {
+		return errors.Wrapf(err, "failed to add route %s to tap %s", route.GetPrefix(), t.hostIfName)
+	}
+	return nil
+}
+
+func (t *tap) String() string {
+	return fmt.Sprintf("tap %s", t.hostIfName)
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attach
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	interfaces "github.com/networkservicemesh/govpp/binapi/interface"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/vhost_user"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+type vhostUser struct {
+	socketFile string
+}
+
+// NewVhostUser returns a vhost-user Interface listening on the socketFile, so a QEMU/Kata VM can connect to it
+func NewVhostUser(socketFile string) Interface {
+	return &vhostUser{
+		socketFile: socketFile,
+	}
+}
+
+func (v *vhostUser) Create(ctx context.Context, vppConn api.Connection) (interface_types.InterfaceIndex, error) {
+	now := time.Now()
+	rsp, err := vhost_user.NewServiceClient(vppConn).CreateVhostUserIfV2(ctx, &vhost_user.CreateVhostUserIfV2{
+		IsServer:     true,
+		SockFilename: v.socketFile,
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "vppapi CreateVhostUserIfV2 returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", rsp.SwIfIndex).
+		WithField("SockFilename", v.socketFile).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "CreateVhostUserIfV2").Debug("completed")
+
+	if err := up(ctx, vppConn, rsp.SwIfIndex); err != nil {
+		return 0, err
+	}
+	return rsp.SwIfIndex, nil
+}
+
+func (v *vhostUser) Delete(ctx context.Context, vppConn api.Connection, swIfIndex interface_types.InterfaceIndex) error {
+	now := time.Now()
+	if _, err := vhost_user.NewServiceClient(vppConn).DeleteVhostUserIf(ctx, &vhost_user.DeleteVhostUserIf{
+		SwIfIndex: swIfIndex,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi DeleteVhostUserIf returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", swIfIndex).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "DeleteVhostUserIf").Debug("completed")
+	return nil
+}
+
+func (v *vhostUser) String() string {
+	return fmt.Sprintf("vhost-user %s", v.socketFile)
+}
+
+func up(ctx context.Context, vppConn api.Connection, swIfIndex interface_types.InterfaceIndex) error {
+	now := time.Now()
+	if _, err := interfaces.NewServiceClient(vppConn).SwInterfaceSetFlags(ctx, &interfaces.SwInterfaceSetFlags{
+		SwIfIndex: swIfIndex,
+		Flags:     interface_types.IF_STATUS_API_FLAG_ADMIN_UP,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi SwInterfaceSetFlags returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", swIfIndex).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "SwInterfaceSetFlags").Debug("completed")
+	return nil
+}
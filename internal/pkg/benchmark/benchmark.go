@@ -0,0 +1,311 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+// Package benchmark pushes the traffic generated by the VPP packet generator into the connections and reports
+// the achieved throughput and drops, so the datapath performance is validated without a separate tool
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"git.fd.io/govpp.git/adapter/statsclient"
+	"git.fd.io/govpp.git/api"
+	"git.fd.io/govpp.git/core"
+	interfaces "github.com/networkservicemesh/govpp/binapi/interface"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/pg"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/registry"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/vppcli"
+)
+
+const (
+	// pgInterfaceID is the ID of the pg interface the generated packets are received on, pg<ID> in VPP
+	pgInterfaceID = 4095
+	streamName    = "nsc-benchmark"
+	udpPort       = 5001
+	// MinPacketSize is the minimal size of the generated IP packets, big enough for the IPv6 and UDP headers
+	MinPacketSize = 64
+	// drainTime is the time given to the generated packets to leave VPP after the stream is completed
+	drainTime = time.Second
+)
+
+// Sizes of the IP and UDP headers of the generated packets
+const (
+	ipv4UDPHeaders = 28
+	ipv6UDPHeaders = 48
+)
+
+// Result is the result of the benchmark of a single connection
+type Result struct {
+	ID             string  `json:"id"`
+	NetworkService string  `json:"networkService"`
+	Generated      uint64  `json:"generated"`
+	Transmitted    uint64  `json:"transmitted"`
+	Drops          uint64  `json:"drops"`
+	PacketsPerSec  float64 `json:"packetsPerSec"`
+	BitsPerSec     float64 `json:"bitsPerSec"`
+	Error          string  `json:"error,omitempty"`
+}
+
+// Benchmark generates UDP traffic from the source to the destination address of a connection at the configured
+// rate and measures the packets sent into the connection interface
+type Benchmark struct {
+	vppConn     api.Connection
+	statsSocket string
+	duration    time.Duration
+	rate        uint64
+	packetSize  int
+}
+
+// New creates a Benchmark generating packetSize bytes long IP packets at rate packets per second for duration.
+// The interface counters are read from the VPP stats socket statsSocket.
+func New(vppConn api.Connection, statsSocket string, duration time.Duration, rate uint64, packetSize int) *Benchmark {
+	if packetSize < MinPacketSize {
+		packetSize = MinPacketSize
+	}
+	return &Benchmark{
+		vppConn:     vppConn,
+		statsSocket: statsSocket,
+		duration:    duration,
+		rate:        rate,
+		packetSize:  packetSize,
+	}
+}
+
+// RunAll runs the benchmark for the connections one by one and logs the results
+func (b *Benchmark) RunAll(ctx context.Context, connections []*registry.Info) []*Result {
+	var results []*Result
+	for _, info := range connections {
+		if ctx.Err() != nil {
+			break
+		}
+		result, err := b.Run(ctx, info)
+		if err != nil {
+			log.FromContext(ctx).Errorf("benchmark of %s has failed: %+v", info.ID, err)
+			result = &Result{ID: info.ID, NetworkService: info.NetworkService, Error: err.Error()}
+		} else {
+			log.FromContext(ctx).Infof("benchmark of %s (%s): generated %d, transmitted %d, dropped %d packets, %.0f pps, %.2f Mbps",
+				result.ID, result.NetworkService, result.Generated, result.Transmitted, result.Drops,
+				result.PacketsPerSec, result.BitsPerSec/1e6)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// Run pushes the traffic into the connection and returns the result. The packets are received on a pg interface
+// bound to the table of the connection interface, so they are routed to the destination like the application ones.
+func (b *Benchmark) Run(ctx context.Context, info *registry.Info) (*Result, error) {
+	if info.IfIndex == 0 || len(info.SrcIPs) == 0 || len(info.DstIPs) == 0 {
+		return nil, errors.Errorf("connection %s has no interface or addresses", info.ID)
+	}
+	src, dst := address(info.SrcIPs[0]), address(info.DstIPs[0])
+	if src == nil || dst == nil || (src.To4() == nil) != (dst.To4() == nil) {
+		return nil, errors.Errorf("invalid addresses of the connection %s: %v -> %v", info.ID, info.SrcIPs, info.DstIPs)
+	}
+	isIPv6 := src.To4() == nil
+	swIfIndex := interface_types.InterfaceIndex(info.IfIndex)
+
+	statsConn, err := core.ConnectStats(statsclient.NewStatsClient(b.statsSocket))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to VPP stats socket %s", b.statsSocket)
+	}
+	defer statsConn.Disconnect()
+
+	pgIfIndex, err := b.attachPg(ctx, swIfIndex, isIPv6)
+	if err != nil {
+		return nil, err
+	}
+	defer b.detachPg(ctx, pgIfIndex, swIfIndex)
+
+	count := b.rate * uint64(b.duration/time.Millisecond) / 1000
+	if err = vppcli.Exec(ctx, b.vppConn, b.stream(src, dst, isIPv6, count)); err != nil {
+		return nil, err
+	}
+	defer func() { _ = vppcli.Exec(ctx, b.vppConn, "packet-generator delete "+streamName) }()
+
+	before, err := counters(statsConn, pgIfIndex, swIfIndex)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if _, err = pg.NewServiceClient(b.vppConn).PgEnableDisable(ctx, &pg.PgEnableDisable{
+		IsEnabled:  true,
+		StreamName: streamName,
+	}); err != nil {
+		return nil, errors.Wrap(err, "vppapi PgEnableDisable returned error")
+	}
+	log.FromContext(ctx).
+		WithField("stream", streamName).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "PgEnableDisable").Debug("completed")
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(b.duration + drainTime):
+	}
+	after, err := counters(statsConn, pgIfIndex, swIfIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		ID:             info.ID,
+		NetworkService: info.NetworkService,
+		Generated:      after.generated - before.generated,
+		Transmitted:    after.transmitted - before.transmitted,
+	}
+	if result.Generated > result.Transmitted {
+		result.Drops = result.Generated - result.Transmitted
+	}
+	seconds := b.duration.Seconds()
+	result.PacketsPerSec = float64(result.Transmitted) / seconds
+	result.BitsPerSec = float64(after.transmittedBytes-before.transmittedBytes) * 8 / seconds
+	return result, nil
+}
+
+// stream returns the CLI command creating the pg stream of count UDP packets from src to dst
+func (b *Benchmark) stream(src, dst net.IP, isIPv6 bool, count uint64) string {
+	node, headers := "ip4-input", ipv4UDPHeaders
+	if isIPv6 {
+		node, headers = "ip6-input", ipv6UDPHeaders
+	}
+	var cmd strings.Builder
+	_, _ = fmt.Fprintf(&cmd, "packet-generator new {\n")
+	_, _ = fmt.Fprintf(&cmd, "  name %s\n  limit %d\n  rate %d\n", streamName, count, b.rate)
+	_, _ = fmt.Fprintf(&cmd, "  interface pg%d\n  node %s\n", pgInterfaceID, node)
+	_, _ = fmt.Fprintf(&cmd, "  data {\n    UDP: %s -> %s\n    UDP: %d -> %d\n    incrementing %d\n  }\n}",
+		src, dst, udpPort, udpPort, b.packetSize-headers)
+	return cmd.String()
+}
+
+// attachPg creates the pg interface, binds it to the table of the connection interface and makes it unnumbered to
+// it, so the generated packets are accepted by the IP input and routed like the ones received from the application
+func (b *Benchmark) attachPg(ctx context.Context, swIfIndex interface_types.InterfaceIndex, isIPv6 bool) (interface_types.InterfaceIndex, error) {
+	mode := pg.PG_API_MODE_IP4
+	if isIPv6 {
+		mode = pg.PG_API_MODE_IP6
+	}
+	now := time.Now()
+	created, err := pg.NewServiceClient(b.vppConn).PgCreateInterfaceV2(ctx, &pg.PgCreateInterfaceV2{
+		InterfaceID: pgInterfaceID,
+		Mode:        mode,
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "vppapi PgCreateInterfaceV2 returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", created.SwIfIndex).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "PgCreateInterfaceV2").Debug("completed")
+
+	client := interfaces.NewServiceClient(b.vppConn)
+	table, err := client.SwInterfaceGetTable(ctx, &interfaces.SwInterfaceGetTable{
+		SwIfIndex: swIfIndex,
+		IsIPv6:    isIPv6,
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "vppapi SwInterfaceGetTable returned error")
+	}
+	if _, err = client.SwInterfaceSetTable(ctx, &interfaces.SwInterfaceSetTable{
+		SwIfIndex: created.SwIfIndex,
+		IsIPv6:    isIPv6,
+		VrfID:     table.VrfID,
+	}); err != nil {
+		return 0, errors.Wrap(err, "vppapi SwInterfaceSetTable returned error")
+	}
+	if _, err = client.SwInterfaceSetUnnumbered(ctx, &interfaces.SwInterfaceSetUnnumbered{
+		SwIfIndex:           swIfIndex,
+		UnnumberedSwIfIndex: created.SwIfIndex,
+		IsAdd:               true,
+	}); err != nil {
+		return 0, errors.Wrap(err, "vppapi SwInterfaceSetUnnumbered returned error")
+	}
+	if _, err = client.SwInterfaceSetFlags(ctx, &interfaces.SwInterfaceSetFlags{
+		SwIfIndex: created.SwIfIndex,
+		Flags:     interface_types.IF_STATUS_API_FLAG_ADMIN_UP,
+	}); err != nil {
+		return 0, errors.Wrap(err, "vppapi SwInterfaceSetFlags returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", created.SwIfIndex).
+		WithField("unnumberedTo", swIfIndex).
+		WithField("vrfID", table.VrfID).Debug("pg interface is attached")
+	return created.SwIfIndex, nil
+}
+
+// detachPg sets the pg interface down and removes the unnumbered configuration. VPP can't delete pg interfaces, so
+// the interface is reused by the next benchmarks.
+func (b *Benchmark) detachPg(ctx context.Context, pgIfIndex, swIfIndex interface_types.InterfaceIndex) {
+	client := interfaces.NewServiceClient(b.vppConn)
+	if _, err := client.SwInterfaceSetFlags(ctx, &interfaces.SwInterfaceSetFlags{
+		SwIfIndex: pgIfIndex,
+	}); err != nil {
+		log.FromContext(ctx).Warnf("failed to set pg interface down: %v", err.Error())
+	}
+	if _, err := client.SwInterfaceSetUnnumbered(ctx, &interfaces.SwInterfaceSetUnnumbered{
+		SwIfIndex:           swIfIndex,
+		UnnumberedSwIfIndex: pgIfIndex,
+	}); err != nil {
+		log.FromContext(ctx).Warnf("failed to remove unnumbered pg interface: %v", err.Error())
+	}
+}
+
+type sample struct {
+	generated        uint64
+	transmitted      uint64
+	transmittedBytes uint64
+}
+
+// counters returns the packets received on the pg interface and sent on the connection interface
+func counters(statsConn *core.StatsConnection, pgIfIndex, swIfIndex interface_types.InterfaceIndex) (*sample, error) {
+	stats := new(api.InterfaceStats)
+	if err := statsConn.GetInterfaceStats(stats); err != nil {
+		return nil, errors.Wrap(err, "failed to get VPP interface stats")
+	}
+	result := new(sample)
+	for idx := range stats.Interfaces {
+		iface := &stats.Interfaces[idx]
+		switch iface.InterfaceIndex {
+		case uint32(pgIfIndex):
+			result.generated = iface.Rx.Packets
+		case uint32(swIfIndex):
+			result.transmitted = iface.Tx.Packets
+			result.transmittedBytes = iface.Tx.Bytes
+		}
+	}
+	return result, nil
+}
+
+// address returns the IP of the address with or without the prefix length
+func address(addr string) net.IP {
+	if ip, _, err := net.ParseCIDR(addr); err == nil {
+		return ip
+	}
+	return net.ParseIP(addr)
+}
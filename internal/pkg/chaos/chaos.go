@@ -0,0 +1,167 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package chaos injects faults for the resilience testing of the applications using the NSC: it closes random
+// connections, drops the VPP API session and delays the heals at random times
+package chaos
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/edwarnicke/vpphelper"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/heal"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// Faults injected by Chaos
+const (
+	// CloseFault closes a random connection, the NSC requests it again
+	CloseFault = "close"
+	// VPPFault fails all the VPP API calls for the fault duration as if the API session is dropped
+	VPPFault = "vpp"
+	// HealFault hides the datapath failures from heal for the fault duration
+	HealFault = "heal"
+)
+
+// errDropped is returned by the VPP API calls while the VPP API session is dropped
+var errDropped = errors.New("VPP API session is dropped by chaos")
+
+// Chaos injects a random fault of the configured ones at random intervals averaging interval. The VPP and heal faults
+// last for duration.
+type Chaos struct {
+	faults   []string
+	interval time.Duration
+	duration time.Duration
+
+	mu           sync.RWMutex
+	vppDropped   time.Time
+	healsDelayed time.Time
+}
+
+// New creates Chaos injecting the faults
+func New(faults []string, interval, duration time.Duration) (*Chaos, error) {
+	for _, fault := range faults {
+		switch fault {
+		case CloseFault, VPPFault, HealFault:
+		default:
+			return nil, errors.Errorf("unknown fault %q, supported: %s, %s, %s", fault, CloseFault, VPPFault, HealFault)
+		}
+	}
+	if len(faults) == 0 {
+		return nil, errors.New("no faults to inject")
+	}
+	if interval <= 0 {
+		return nil, errors.Errorf("invalid fault interval %s", interval)
+	}
+	return &Chaos{
+		faults:   faults,
+		interval: interval,
+		duration: duration,
+	}, nil
+}
+
+// Run injects the faults until ctx is done. The close fault closes a connection with an ID returned by connections
+// with closeConnection.
+func (c *Chaos) Run(ctx context.Context, connections func() []string, closeConnection func(ctx context.Context, id string) error) {
+	logger := log.FromContext(ctx).WithField("chaos", "fault")
+	for {
+		// The interval is random in [interval/2, interval*3/2)
+		delay := c.interval/2 + time.Duration(rand.Int63n(int64(c.interval))) // nolint:gosec
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		switch fault := c.faults[rand.Intn(len(c.faults))]; fault { // nolint:gosec
+		case CloseFault:
+			ids := connections()
+			if len(ids) == 0 {
+				continue
+			}
+			id := ids[rand.Intn(len(ids))] // nolint:gosec
+			logger.Warnf("closing connection %s", id)
+			if err := closeConnection(ctx, id); err != nil {
+				logger.Warnf("connection %s has been restored with error: %v", id, err.Error())
+			}
+		case VPPFault:
+			logger.Warnf("dropping VPP API session for %s", c.duration)
+			c.mu.Lock()
+			c.vppDropped = time.Now().Add(c.duration)
+			c.mu.Unlock()
+		case HealFault:
+			logger.Warnf("delaying heals for %s", c.duration)
+			c.mu.Lock()
+			c.healsDelayed = time.Now().Add(c.duration)
+			c.mu.Unlock()
+		}
+	}
+}
+
+// Wrap returns the connection failing the VPP API calls while the VPP API session is dropped
+func (c *Chaos) Wrap(conn vpphelper.Connection) vpphelper.Connection {
+	if conn == nil {
+		return nil
+	}
+	return &chaosConnection{
+		Connection: conn,
+		chaos:      c,
+	}
+}
+
+// LivenessCheck wraps the check, so the failures are not detected while the heals are delayed
+func (c *Chaos) LivenessCheck(check heal.LivenessCheck) heal.LivenessCheck {
+	if check == nil {
+		return nil
+	}
+	return func(deadlineCtx context.Context, conn *networkservice.Connection) bool {
+		return c.active(&c.healsDelayed) || check(deadlineCtx, conn)
+	}
+}
+
+func (c *Chaos) active(until *time.Time) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return time.Now().Before(*until)
+}
+
+type chaosConnection struct {
+	vpphelper.Connection
+	chaos *Chaos
+}
+
+func (c *chaosConnection) Invoke(ctx context.Context, req, reply api.Message) error {
+	if c.chaos.active(&c.chaos.vppDropped) {
+		return errDropped
+	}
+	return c.Connection.Invoke(ctx, req, reply)
+}
+
+func (c *chaosConnection) NewStream(ctx context.Context, options ...api.StreamOption) (api.Stream, error) {
+	if c.chaos.active(&c.chaos.vppDropped) {
+		return nil, errDropped
+	}
+	return c.Connection.NewStream(ctx, options...)
+}
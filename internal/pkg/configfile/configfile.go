@@ -0,0 +1,144 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configfile provides loading of envconfig specifications from YAML/JSON files
+package configfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ghodss/yaml"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/pkg/errors"
+)
+
+var (
+	mu       sync.Mutex
+	exported = make(map[string]string)
+)
+
+// Apply reads YAML/JSON file located at path and exports its values as the environment variables for
+// the given envconfig spec. Variables already present in the environment are not touched, so the
+// following envconfig.Process(prefix, spec) call gets env vars overriding the file values.
+//
+// File keys can be either the spec field names (`dialTimeout`, `DialTimeout`) or the environment
+// variable names with or without the prefix (`DIAL_TIMEOUT`, `NSM_DIAL_TIMEOUT`), case-insensitive.
+// Lists are joined with ",", maps are joined as "key:value" pairs.
+//
+// Values exported by the previous Apply calls are dropped first, so Apply can be called again to reload the file.
+func Apply(prefix string, spec interface{}, path string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for key, value := range exported {
+		if current, ok := os.LookupEnv(key); ok && current == value {
+			_ = os.Unsetenv(key)
+		}
+	}
+	exported = make(map[string]string)
+
+	data, err := os.ReadFile(path) // nolint:gosec
+	if err != nil {
+		return errors.Wrapf(err, "failed to read config file %s", path)
+	}
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse config file %s", path)
+	}
+	values := make(map[string]interface{})
+	if err = json.Unmarshal(jsonData, &values); err != nil {
+		return errors.Wrapf(err, "failed to parse config file %s", path)
+	}
+
+	keys, err := envKeys(prefix, spec)
+	if err != nil {
+		return err
+	}
+
+	for name, value := range values {
+		key, ok := keys[strings.ToUpper(name)]
+		if !ok {
+			return errors.Errorf("unknown config file key: %s", name)
+		}
+		if _, ok := os.LookupEnv(key); ok || value == nil {
+			continue
+		}
+		envValue := toEnvValue(value)
+		if err := os.Setenv(key, envValue); err != nil {
+			return errors.Wrapf(err, "failed to set %s", key)
+		}
+		exported[key] = envValue
+	}
+	return nil
+}
+
+// envKeys returns mapping from every accepted upper-cased file key to the environment variable name.
+// It uses envconfig itself to get the variable names, so `split_words` and `envconfig` tags are honored.
+func envKeys(prefix string, spec interface{}) (map[string]string, error) {
+	buf := new(bytes.Buffer)
+	if err := envconfig.Usagef(prefix, spec, buf, "{{range .}}{{.Name}} {{.Key}}\n{{end}}"); err != nil {
+		return nil, errors.Wrap(err, "failed to gather config keys")
+	}
+
+	keys := make(map[string]string)
+	envPrefix := strings.ToUpper(prefix) + "_"
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		name, key := fields[0], fields[1]
+		keys[strings.ToUpper(name)] = key
+		keys[key] = key
+		keys[strings.TrimPrefix(key, envPrefix)] = key
+	}
+	return keys, nil
+}
+
+func toEnvValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		var items []string
+		for _, item := range v {
+			items = append(items, toEnvValue(item))
+		}
+		return strings.Join(items, ",")
+	case map[string]interface{}:
+		var items []string
+		for k, item := range v {
+			items = append(items, fmt.Sprintf("%s:%s", k, toEnvValue(item)))
+		}
+		sort.Strings(items)
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configfile_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/configfile"
+)
+
+const prefix = "configfile_test"
+
+type testConfig struct {
+	Name            string            `default:"nsc"`
+	DialTimeout     time.Duration     `default:"5s" split_words:"true"`
+	NetworkServices []string          `split_words:"true"`
+	Labels          map[string]string `default:""`
+	MaxTokenLife    int               `envconfig:"max_token_lifetime"`
+	Insecure        bool
+}
+
+func writeFile(t *testing.T, data string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	return path
+}
+
+// apply applies the file with the data and drops the exported values once the test is done
+func apply(t *testing.T, data string) error {
+	t.Cleanup(func() {
+		require.NoError(t, configfile.Apply(prefix, new(testConfig), writeFile(t, "")))
+	})
+	return configfile.Apply(prefix, new(testConfig), writeFile(t, data))
+}
+
+func TestApply(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		data     string
+		env      map[string]string
+		expected testConfig
+		err      bool
+	}{
+		{
+			name:     "field names",
+			data:     "name: nsc-1\ndialTimeout: 10s\ninsecure: true\n",
+			expected: testConfig{Name: "nsc-1", DialTimeout: 10 * time.Second, Insecure: true},
+		},
+		{
+			name:     "env names",
+			data:     "NAME: nsc-1\nDIAL_TIMEOUT: 10s\nCONFIGFILE_TEST_INSECURE: true\n",
+			expected: testConfig{Name: "nsc-1", DialTimeout: 10 * time.Second, Insecure: true},
+		},
+		{
+			name: "lists and maps",
+			data: "networkServices:\n  - kernel://a\n  - kernel://b\nlabels:\n  app: nsc\n  zone: a\n",
+			expected: testConfig{
+				Name:            "nsc",
+				DialTimeout:     5 * time.Second,
+				NetworkServices: []string{"kernel://a", "kernel://b"},
+				Labels:          map[string]string{"app": "nsc", "zone": "a"},
+			},
+		},
+		{
+			name:     "envconfig tag",
+			data:     "max_token_lifetime: 10\n",
+			expected: testConfig{Name: "nsc", DialTimeout: 5 * time.Second, MaxTokenLife: 10},
+		},
+		{
+			name:     "json",
+			data:     `{"name": "nsc-1", "dialTimeout": "1s"}`,
+			expected: testConfig{Name: "nsc-1", DialTimeout: time.Second},
+		},
+		{
+			name:     "env overrides file",
+			data:     "name: nsc-1\ndialTimeout: 10s\n",
+			env:      map[string]string{"CONFIGFILE_TEST_NAME": "nsc-2"},
+			expected: testConfig{Name: "nsc-2", DialTimeout: 10 * time.Second},
+		},
+		{
+			name:     "null value",
+			data:     "name: null\n",
+			expected: testConfig{Name: "nsc", DialTimeout: 5 * time.Second},
+		},
+		{
+			name: "unknown key",
+			data: "unknown: value\n",
+			err:  true,
+		},
+		{
+			name: "invalid yaml",
+			data: "name: [nsc\n",
+			err:  true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+			err := apply(t, tc.data)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			config := new(testConfig)
+			require.NoError(t, envconfig.Process(prefix, config))
+			require.Equal(t, tc.expected, *config)
+		})
+	}
+}
+
+func TestApply_Reload(t *testing.T) {
+	require.NoError(t, apply(t, "name: nsc-1\ninsecure: true\n"))
+	require.NoError(t, apply(t, "name: nsc-2\n"))
+
+	config := new(testConfig)
+	require.NoError(t, envconfig.Process(prefix, config))
+	require.Equal(t, testConfig{Name: "nsc-2", DialTimeout: 5 * time.Second}, *config)
+}
+
+func TestApply_MissingFile(t *testing.T) {
+	require.Error(t, configfile.Apply(prefix, new(testConfig), filepath.Join(t.TempDir(), "missing.yaml")))
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package connacl applies the ACL rules of the Network Service to the NSM connection interfaces in VPP
+package connacl
+
+import (
+	"context"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/govpp/binapi/acl"
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/postpone"
+)
+
+type metadataKey struct{}
+
+// aclState is the interface the ACLs are applied to and the input (ingress) and output (egress) ACL indices
+type aclState struct {
+	swIfIndex interface_types.InterfaceIndex
+	nInput    uint8
+	indices   []uint32
+}
+
+type aclClient struct {
+	vppConn api.Connection
+	config  Config
+}
+
+// NewClient returns a client chain element applying the ACL rules of the Network Service to the connection
+// interface. It should be placed before the mechanism client to see the interface index.
+func NewClient(vppConn api.Connection, config Config) networkservice.NetworkServiceClient {
+	return &aclClient{
+		vppConn: vppConn,
+		config:  config,
+	}
+}
+
+func (c *aclClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	postponeCtxFunc := postpone.ContextWithValues(ctx)
+
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.apply(ctx, conn); err != nil {
+		closeCtx, cancelClose := postponeCtxFunc()
+		defer cancelClose()
+
+		if _, closeErr := c.Close(closeCtx, conn, opts...); closeErr != nil {
+			err = errors.Wrapf(err, "connection closed with error: %s", closeErr.Error())
+		}
+
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (c *aclClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	state, ok := loadAndDelete(ctx)
+	if ok {
+		if err := setACLList(ctx, c.vppConn, state.swIfIndex, 0, nil); err != nil {
+			log.FromContext(ctx).Warnf("failed to clear ACLs of interface %d: %s", state.swIfIndex, err.Error())
+		}
+	}
+
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+
+	if ok {
+		for _, aclIndex := range state.indices {
+			if delErr := del(ctx, c.vppConn, aclIndex); delErr != nil {
+				log.FromContext(ctx).Warnf("failed to delete ACL %d: %s", aclIndex, delErr.Error())
+			}
+		}
+	}
+	return rv, err
+}
+
+func (c *aclClient) apply(ctx context.Context, conn *networkservice.Connection) error {
+	swIfIndex, ok := ifindex.Load(ctx, true)
+	if !ok {
+		return nil
+	}
+	rules, ok := c.config.rules(conn.GetNetworkService())
+	if !ok {
+		return nil
+	}
+
+	state, loaded := load(ctx)
+	if loaded && state.swIfIndex == swIfIndex {
+		return nil
+	}
+	if !loaded {
+		state = &aclState{}
+		for _, r := range [][]acl_types.ACLRule{rules.ingress, rules.egress} {
+			if len(r) == 0 {
+				continue
+			}
+			aclIndex, err := add(ctx, c.vppConn, conn.GetId(), r)
+			if err != nil {
+				for _, added := range state.indices {
+					if delErr := del(ctx, c.vppConn, added); delErr != nil {
+						log.FromContext(ctx).Warnf("failed to delete ACL %d: %s", added, delErr.Error())
+					}
+				}
+				return err
+			}
+			state.indices = append(state.indices, aclIndex)
+		}
+		if len(rules.ingress) > 0 {
+			state.nInput = 1
+		}
+		store(ctx, state)
+	} else if err := setACLList(ctx, c.vppConn, state.swIfIndex, 0, nil); err != nil {
+		// The NSM interface is created again on reselect, so the ACLs are moved to the new one
+		log.FromContext(ctx).Warnf("failed to clear ACLs of interface %d: %s", state.swIfIndex, err.Error())
+	}
+
+	state.swIfIndex = swIfIndex
+	if err := setACLList(ctx, c.vppConn, swIfIndex, state.nInput, state.indices); err != nil {
+		return err
+	}
+
+	log.FromContext(ctx).Infof("ACLs %v are applied to interface %d", state.indices, swIfIndex)
+	return nil
+}
+
+func add(ctx context.Context, vppConn api.Connection, tag string, rules []acl_types.ACLRule) (uint32, error) {
+	now := time.Now()
+	rsp, err := acl.NewServiceClient(vppConn).ACLAddReplace(ctx, &acl.ACLAddReplace{
+		ACLIndex: ^uint32(0),
+		Tag:      tag,
+		Count:    uint32(len(rules)),
+		R:        rules,
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "vppapi ACLAddReplace returned error")
+	}
+	log.FromContext(ctx).
+		WithField("aclIndex", rsp.ACLIndex).
+		WithField("tag", tag).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "ACLAddReplace").Debug("completed")
+	return rsp.ACLIndex, nil
+}
+
+func del(ctx context.Context, vppConn api.Connection, aclIndex uint32) error {
+	now := time.Now()
+	if _, err := acl.NewServiceClient(vppConn).ACLDel(ctx, &acl.ACLDel{
+		ACLIndex: aclIndex,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi ACLDel returned error")
+	}
+	log.FromContext(ctx).
+		WithField("aclIndex", aclIndex).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "ACLDel").Debug("completed")
+	return nil
+}
+
+// setACLList applies the ACLs to the interface, the first nInput ones are the input ACLs
+func setACLList(ctx context.Context, vppConn api.Connection, swIfIndex interface_types.InterfaceIndex, nInput uint8, indices []uint32) error {
+	now := time.Now()
+	if _, err := acl.NewServiceClient(vppConn).ACLInterfaceSetACLList(ctx, &acl.ACLInterfaceSetACLList{
+		SwIfIndex: swIfIndex,
+		Count:     uint8(len(indices)),
+		NInput:    nInput,
+		Acls:      indices,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi ACLInterfaceSetACLList returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", swIfIndex).
+		WithField("acls", indices).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "ACLInterfaceSetACLList").Debug("completed")
+	return nil
+}
+
+func store(ctx context.Context, state *aclState) {
+	metadata.Map(ctx, true).Store(metadataKey{}, state)
+}
+
+func load(ctx context.Context) (*aclState, bool) {
+	rawValue, ok := metadata.Map(ctx, true).Load(metadataKey{})
+	if !ok {
+		return nil, false
+	}
+	state, ok := rawValue.(*aclState)
+	return state, ok
+}
+
+func loadAndDelete(ctx context.Context) (*aclState, bool) {
+	rawValue, ok := metadata.Map(ctx, true).LoadAndDelete(metadataKey{})
+	if !ok {
+		return nil, false
+	}
+	state, ok := rawValue.(*aclState)
+	return state, ok
+}
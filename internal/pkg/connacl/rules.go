@@ -0,0 +1,242 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connacl
+
+import (
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/govpp/binapi/ip_types"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/types"
+)
+
+// DefaultService is the key of the rules applied to the connections of the Network Services without their own rules
+const DefaultService = "*"
+
+// Rule is a single ACL rule
+type Rule struct {
+	// Action is permit, deny or permit-reflect - permit and allow the reply traffic
+	Action string `json:"action"`
+	// Proto is tcp, udp, icmp, icmpv6 or the IP protocol number, any if empty
+	Proto string `json:"proto,omitempty"`
+	// Src is the source prefix, any if empty
+	Src string `json:"src,omitempty"`
+	// Dst is the destination prefix, any if empty
+	Dst string `json:"dst,omitempty"`
+	// SrcPorts is the source port or the first-last range, the ICMP type for ICMP, any if empty
+	SrcPorts string `json:"srcPorts,omitempty"`
+	// DstPorts is the destination port or the first-last range, the ICMP code for ICMP, any if empty
+	DstPorts string `json:"dstPorts,omitempty"`
+}
+
+// Rules are the ACL rules of the connection interface. The traffic not matching any rule of the direction with the
+// rules is denied.
+type Rules struct {
+	// Ingress rules match the traffic coming from the connection
+	Ingress []Rule `json:"ingress,omitempty"`
+	// Egress rules match the traffic sent to the connection
+	Egress []Rule `json:"egress,omitempty"`
+}
+
+// vppRules are the ACL rules converted to the VPP ones
+type vppRules struct {
+	ingress []acl_types.ACLRule
+	egress  []acl_types.ACLRule
+}
+
+// Config are the ACL rules by the Network Service, DefaultService rules are applied to the other Network Services
+type Config map[string]*vppRules
+
+// Load reads the YAML/JSON file with the Rules by the Network Service
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path) // nolint:gosec
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read ACL file %s", path)
+	}
+	var services map[string]*Rules
+	if err = yaml.Unmarshal(data, &services); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse ACL file %s", path)
+	}
+
+	config := make(Config)
+	for service, rules := range services {
+		r := new(vppRules)
+		if r.ingress, err = convert(rules.Ingress); err != nil {
+			return nil, errors.Wrapf(err, "invalid ingress rules of %s", service)
+		}
+		if r.egress, err = convert(rules.Egress); err != nil {
+			return nil, errors.Wrapf(err, "invalid egress rules of %s", service)
+		}
+		config[service] = r
+	}
+	return config, nil
+}
+
+func (c Config) rules(networkService string) (*vppRules, bool) {
+	if r, ok := c[networkService]; ok {
+		return r, true
+	}
+	r, ok := c[DefaultService]
+	return r, ok
+}
+
+func convert(rules []Rule) ([]acl_types.ACLRule, error) {
+	var result []acl_types.ACLRule
+	for i := range rules {
+		converted, err := convertRule(&rules[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "rule %d", i)
+		}
+		result = append(result, converted...)
+	}
+	return result, nil
+}
+
+// convertRule returns the VPP rules for the rule, a rule without prefixes is converted to the IPv4 and IPv6 ones
+func convertRule(rule *Rule) ([]acl_types.ACLRule, error) {
+	base := acl_types.ACLRule{}
+	switch strings.ToLower(rule.Action) {
+	case "permit":
+		base.IsPermit = acl_types.ACL_ACTION_API_PERMIT
+	case "deny":
+		base.IsPermit = acl_types.ACL_ACTION_API_DENY
+	case "permit-reflect":
+		base.IsPermit = acl_types.ACL_ACTION_API_PERMIT_REFLECT
+	default:
+		return nil, errors.Errorf("invalid action %q", rule.Action)
+	}
+
+	proto, err := parseProto(rule.Proto)
+	if err != nil {
+		return nil, err
+	}
+	base.Proto = proto
+	maxPort := uint64(math.MaxUint16)
+	if proto == ip_types.IP_API_PROTO_ICMP || proto == ip_types.IP_API_PROTO_ICMP6 {
+		maxPort = math.MaxUint8
+	}
+	if base.SrcportOrIcmptypeFirst, base.SrcportOrIcmptypeLast, err = parsePorts(rule.SrcPorts, maxPort); err != nil {
+		return nil, err
+	}
+	if base.DstportOrIcmpcodeFirst, base.DstportOrIcmpcodeLast, err = parsePorts(rule.DstPorts, maxPort); err != nil {
+		return nil, err
+	}
+
+	src, err := parsePrefix(rule.Src)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := parsePrefix(rule.Dst)
+	if err != nil {
+		return nil, err
+	}
+	if src != nil && dst != nil && (src.IP.To4() == nil) != (dst.IP.To4() == nil) {
+		return nil, errors.Errorf("src %s and dst %s are of different address families", rule.Src, rule.Dst)
+	}
+
+	var families []bool
+	switch {
+	case src != nil:
+		families = []bool{src.IP.To4() == nil}
+	case dst != nil:
+		families = []bool{dst.IP.To4() == nil}
+	default:
+		families = []bool{false, true}
+	}
+	var result []acl_types.ACLRule
+	for _, isIPv6 := range families {
+		if (proto == ip_types.IP_API_PROTO_ICMP && isIPv6) || (proto == ip_types.IP_API_PROTO_ICMP6 && !isIPv6) {
+			continue
+		}
+		r := base
+		r.SrcPrefix = types.ToVppPrefix(orAny(src, isIPv6))
+		r.DstPrefix = types.ToVppPrefix(orAny(dst, isIPv6))
+		result = append(result, r)
+	}
+	if len(result) == 0 {
+		return nil, errors.Errorf("protocol %s doesn't match the address family", rule.Proto)
+	}
+	return result, nil
+}
+
+func parseProto(s string) (ip_types.IPProto, error) {
+	switch strings.ToLower(s) {
+	case "", "any":
+		return ip_types.IP_API_PROTO_HOPOPT, nil
+	case "tcp":
+		return ip_types.IP_API_PROTO_TCP, nil
+	case "udp":
+		return ip_types.IP_API_PROTO_UDP, nil
+	case "icmp":
+		return ip_types.IP_API_PROTO_ICMP, nil
+	case "icmpv6":
+		return ip_types.IP_API_PROTO_ICMP6, nil
+	}
+	proto, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, errors.Errorf("invalid protocol %q", s)
+	}
+	return ip_types.IPProto(proto), nil
+}
+
+// parsePorts parses the port or the first-last range, the empty string is the whole range up to maxPort
+func parsePorts(s string, maxPort uint64) (first, last uint16, err error) {
+	if s == "" {
+		return 0, uint16(maxPort), nil
+	}
+	firstStr, lastStr, isRange := strings.Cut(s, "-")
+	if !isRange {
+		lastStr = firstStr
+	}
+	from, err := strconv.ParseUint(strings.TrimSpace(firstStr), 10, 16)
+	if err != nil || from > maxPort {
+		return 0, 0, errors.Errorf("invalid ports %q", s)
+	}
+	to, err := strconv.ParseUint(strings.TrimSpace(lastStr), 10, 16)
+	if err != nil || to > maxPort || to < from {
+		return 0, 0, errors.Errorf("invalid ports %q", s)
+	}
+	return uint16(from), uint16(to), nil
+}
+
+func parsePrefix(s string) (*net.IPNet, error) {
+	if s == "" {
+		return nil, nil
+	}
+	_, prefix, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid prefix %s", s)
+	}
+	return prefix, nil
+}
+
+func orAny(prefix *net.IPNet, isIPv6 bool) *net.IPNet {
+	if prefix != nil {
+		return prefix
+	}
+	if isIPv6 {
+		return &net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, net.IPv6len*8)}
+	}
+	return &net.IPNet{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, net.IPv4len*8)}
+}
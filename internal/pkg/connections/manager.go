@@ -0,0 +1,701 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package connections manages the set of Network Service connections requested by the NSC
+package connections
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/vxlan"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/wireguard"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/heal"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/attach"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/ecmp"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/isolation"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/labels"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/mtu"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/qos"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/redundancy"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/routes"
+)
+
+// ClientFunc creates a Network Service client dialing NSMgr with the given timeout
+type ClientFunc func(dialTimeout time.Duration) networkservice.NetworkServiceClient
+
+const verifyInterval = time.Second
+
+// connectionIDHashLen is the number of the URL hash hex digits used in the connection IDs
+const connectionIDHashLen = 10
+
+// supportedMechanisms are the mechanism types the NSC chain can handle:
+//   - memif - VPP memif interface
+//   - kernel - VPP tapv2 interface with the kernel side in the pod network namespace
+//   - wireguard - VPP wireguard tunnel to the remote side, requires the tunnel IP to be configured
+//   - vxlan - VPP vxlan tunnel to the remote side, requires the tunnel IP to be configured
+var supportedMechanisms = map[string]bool{
+	memif.MECHANISM:     true,
+	kernel.MECHANISM:    true,
+	wireguard.MECHANISM: true,
+	vxlan.MECHANISM:     true,
+}
+
+type connection struct {
+	id          string
+	service     *service
+	client      networkservice.NetworkServiceClient
+	conn        *networkservice.Connection
+	verified    bool
+	runtime     bool
+	cancelWatch context.CancelFunc
+	cancelRetry context.CancelFunc
+	failure     error
+	attempts    int
+}
+
+func ids(conns []*connection) []string {
+	var result []string
+	for _, c := range conns {
+		result = append(result, c.id)
+	}
+	return result
+}
+
+// Manager requests and closes Network Service connections for the list of Network Service URLs
+type Manager struct {
+	ctx            context.Context
+	name           string
+	newClient      ClientFunc
+	monitorClient  networkservice.MonitorConnectionClient
+	requestTimeout time.Duration
+	dialTimeout    time.Duration
+	datapathCheck  heal.LivenessCheck
+	stateFile      string
+	labels         map[string]string
+	variables      labels.Variables
+
+	maxParallelRequests int
+	startupJitter       time.Duration
+	retryJitter         time.Duration
+	startOnce           sync.Once
+
+	established atomic.Bool
+	states      sync.Map
+
+	mu      sync.Mutex
+	clients map[time.Duration]networkservice.NetworkServiceClient
+	conns   []*connection
+	saved   map[string]*networkservice.Connection
+}
+
+// NewManager creates a new connections Manager
+//   - ctx - context of the Manager lifetime, the connections are watched until it is done
+//   - name - NSC name used as a prefix for the connection IDs
+//   - newClient - creates clients used to request and close connections
+//   - monitorClient - client used to find connections left from the previous NSC run and to watch the connections
+//   - opts - additional options
+func NewManager(ctx context.Context, name string, newClient ClientFunc, monitorClient networkservice.MonitorConnectionClient, opts ...Option) *Manager {
+	m := &Manager{
+		ctx:            ctx,
+		name:           name,
+		newClient:      newClient,
+		monitorClient:  monitorClient,
+		requestTimeout: time.Second * 15,
+		dialTimeout:    time.Second * 5,
+		clients:        make(map[time.Duration]networkservice.NetworkServiceClient),
+
+		maxParallelRequests: 1,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.loadState(ctx)
+	return m
+}
+
+// Update requests connections for the services missing in the current set and closes connections for
+// the services not present in networkServices anymore. Repeated URLs are treated as separate connections.
+// Connections added with Add are not affected. Failed requests don't fail the Update, they are retried with
+// backoff in background until established. The first Update is delayed by the random startup jitter.
+func (m *Manager) Update(ctx context.Context, networkServices []url.URL) error {
+	m.startOnce.Do(func() { m.delayStart(ctx) })
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	err := m.update(ctx, networkServices)
+	m.established.Store(err == nil && m.allConnected())
+	m.cleanupSaved(ctx)
+	m.saveState(ctx)
+	return err
+}
+
+func (m *Manager) update(ctx context.Context, networkServices []url.URL) error {
+	networkServices, expandErr := expand(networkServices)
+	if expandErr != nil {
+		return expandErr
+	}
+
+	wanted := make(map[string]int)
+	for i := range networkServices {
+		wanted[networkServices[i].String()]++
+	}
+
+	var conns, removed []*connection
+	for i := len(m.conns) - 1; i >= 0; i-- {
+		c := m.conns[i]
+		key := c.service.url.String()
+		if c.runtime {
+			conns = append([]*connection{c}, conns...)
+			continue
+		}
+		if wanted[key] > 0 {
+			wanted[key]--
+			conns = append([]*connection{c}, conns...)
+			continue
+		}
+		removed = append(removed, c)
+	}
+
+	// The new services are parsed and checked against the kept connections before any connection is closed, so
+	// an invalid update leaves the current connections as they are
+	all := m.conns
+	m.conns = conns
+	pending, err := m.newPending(networkServices, wanted)
+	if err != nil {
+		m.conns = all
+		return err
+	}
+	for _, c := range removed {
+		m.close(ctx, c)
+	}
+
+	for len(pending) > 0 {
+		var ready, failed, rest []*connection
+		var errs []error
+		for _, c := range pending {
+			ok, err := m.dependenciesReady(ctx, c, pending)
+			switch {
+			case err != nil:
+				failed = append(failed, c)
+				errs = append(errs, err)
+			case ok:
+				ready = append(ready, c)
+			default:
+				rest = append(rest, c)
+			}
+		}
+		if len(ready) == 0 && len(failed) == 0 {
+			return errors.Errorf("circular %s dependency between the services: %v", AfterParam, ids(rest))
+		}
+		for i, c := range failed {
+			m.conns = append(m.conns, c)
+			m.retryLater(ctx, c, errs[i])
+		}
+		m.requestAll(ctx, ready, true)
+		pending = rest
+	}
+	return nil
+}
+
+// newPending returns the new connections for the networkServices still wanted, it fails if some of them are invalid
+// or depend on the services which are not requested
+func (m *Manager) newPending(networkServices []url.URL, wanted map[string]int) ([]*connection, error) {
+	var pending []*connection
+	for i := range networkServices {
+		key := networkServices[i].String()
+		if wanted[key] == 0 {
+			continue
+		}
+		wanted[key]--
+
+		s, err := m.parseService(&networkServices[i])
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, m.newConnection(s, pending))
+	}
+	for _, c := range pending {
+		if err := m.checkDependencies(c, pending); err != nil {
+			return nil, err
+		}
+	}
+	return pending, nil
+}
+
+// requestAll requests conns with at most maxParallelRequests requests running at the same time. If retryFailed
+// is set, the failed connections are kept and retried with backoff, otherwise they are dropped and the first
+// error is returned.
+func (m *Manager) requestAll(ctx context.Context, conns []*connection, retryFailed bool) error {
+	// The saved connections are taken before the requests run in parallel, so m.saved is accessed under m.mu only
+	saved := make([]*networkservice.Connection, len(conns))
+	for i, c := range conns {
+		saved[i] = m.savedConnection(c)
+	}
+
+	var g errgroup.Group
+	g.SetLimit(m.maxParallelRequests)
+	errs := make([]error, len(conns))
+	for i, c := range conns {
+		i, c := i, c
+		g.Go(func() error {
+			errs[i] = m.request(ctx, c, saved[i])
+			return errs[i]
+		})
+	}
+	err := g.Wait()
+
+	// Keep the established connections even on error, so they are closed later
+	for i, c := range conns {
+		switch {
+		case c.conn != nil:
+			m.conns = append(m.conns, c)
+			m.setState(c)
+		case retryFailed:
+			m.conns = append(m.conns, c)
+			m.retryLater(ctx, c, errs[i])
+		}
+	}
+	if retryFailed {
+		return nil
+	}
+	return err
+}
+
+// Established returns an error if the last Update has not established all the connections yet
+func (m *Manager) Established(_ context.Context) error {
+	if !m.established.Load() {
+		if retrying := m.retrying(); len(retrying) > 0 {
+			return errors.Errorf("not all network services are connected, retrying: %v", retrying)
+		}
+		return errors.New("not all network services are connected")
+	}
+	return nil
+}
+
+// Add requests a new connection for the Network Service URL u and returns its ID. The connection is kept
+// until it is closed with Close or CloseAll.
+func (m *Manager) Add(ctx context.Context, u *url.URL) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	defer m.saveState(ctx)
+
+	s, err := m.parseService(u)
+	if err != nil {
+		return "", err
+	}
+	c := m.newConnection(s, nil)
+	c.runtime = true
+
+	if _, err := m.dependenciesReady(ctx, c, nil); err != nil {
+		return "", err
+	}
+	if err := m.requestAll(ctx, []*connection{c}, false); err != nil {
+		return "", err
+	}
+	return c.id, nil
+}
+
+// Close closes the connection with the given ID
+func (m *Manager) Close(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	defer m.saveState(ctx)
+
+	for i, c := range m.conns {
+		if c.id == id {
+			retrying := c.cancelRetry != nil
+			m.close(ctx, c)
+			m.conns = append(m.conns[:i], m.conns[i+1:]...)
+			if retrying && !c.runtime {
+				m.established.Store(m.allConnected())
+			}
+			return nil
+		}
+	}
+	return errors.Errorf("connection %s not found", id)
+}
+
+// Reselect closes the connection with the given ID and requests it again, so NSMgr selects the path from scratch.
+// If the request fails, the connection is retried with backoff.
+func (m *Manager) Reselect(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	defer m.saveState(ctx)
+
+	for _, c := range m.conns {
+		if c.id != id {
+			continue
+		}
+		if !c.runtime {
+			m.established.Store(false)
+		}
+		m.close(ctx, c)
+		c.conn = nil
+		c.verified = false
+		if err := m.request(ctx, c, m.savedConnection(c)); err != nil {
+			m.retryLater(ctx, c, err)
+			return err
+		}
+		m.setState(c)
+		if !c.runtime {
+			m.established.Store(true)
+		}
+		return nil
+	}
+	return errors.Errorf("connection %s not found", id)
+}
+
+// Reconnect closes all managed connections and requests them again in the same order, so the datapath is restored
+// after it has been lost with the VPP restart. Connections failed to reconnect are retried with backoff.
+func (m *Manager) Reconnect(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	defer m.saveState(ctx)
+
+	for i := len(m.conns) - 1; i >= 0; i-- {
+		m.close(ctx, m.conns[i])
+		m.conns[i].conn = nil
+		m.conns[i].verified = false
+	}
+
+	var failed []*connection
+	for _, c := range m.conns {
+		_, err := m.dependenciesReady(ctx, c, nil)
+		if err == nil {
+			err = m.request(ctx, c, m.savedConnection(c))
+		}
+		if err != nil {
+			m.retryLater(ctx, c, err)
+			failed = append(failed, c)
+			continue
+		}
+		m.setState(c)
+	}
+
+	if len(failed) > 0 {
+		m.established.Store(false)
+		return errors.Errorf("failed to reconnect connections: %v", ids(failed))
+	}
+	return nil
+}
+
+// CloseAll closes all managed connections in parallel and waits until they are closed or ctx is done
+func (m *Manager) CloseAll(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	defer m.saveState(ctx)
+
+	var wg sync.WaitGroup
+	for _, c := range m.conns {
+		c := c
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.close(ctx, c)
+		}()
+	}
+	wg.Wait()
+	m.conns = nil
+}
+
+// newConnection creates a connection for the service s with the ID derived from the NSC name and the normalized
+// service URL, so the ID doesn't depend on the order of the services. Repeated URLs get the ID suffixed with the
+// number of the repetition.
+func (m *Manager) newConnection(s *service, pending []*connection) *connection {
+	u := s.url
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.RawQuery = u.Query().Encode()
+	u.Fragment = ""
+	hash := sha256.Sum256([]byte(m.name + "\n" + u.String()))
+	base := fmt.Sprintf("%s-%s", m.name, hex.EncodeToString(hash[:])[:connectionIDHashLen])
+
+	id := base
+	for i := 1; m.hasID(id, pending); i++ {
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+	return &connection{
+		id:      id,
+		service: s,
+		client:  m.client(s.dialTimeout),
+	}
+}
+
+// parseService parses the Network Service URL and expands the service labels merged with the common ones
+func (m *Manager) parseService(u *url.URL) (*service, error) {
+	s, err := parseService(u, m.requestTimeout, m.dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[string]string, len(m.labels)+len(s.labels))
+	for key, value := range m.labels {
+		merged[key] = value
+	}
+	for key, value := range s.labels {
+		merged[key] = value
+	}
+	if s.labels, err = m.variables.ExpandAll(merged); err != nil {
+		return nil, errors.Wrapf(err, "invalid labels of %s", u.String())
+	}
+	return s, nil
+}
+
+func (m *Manager) hasID(id string, pending []*connection) bool {
+	for _, c := range append(m.conns[:len(m.conns):len(m.conns)], pending...) {
+		if c.id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// client returns a shared client for the dial timeout, so the connections with the same timeout use the same chain
+func (m *Manager) client(dialTimeout time.Duration) networkservice.NetworkServiceClient {
+	if c, ok := m.clients[dialTimeout]; ok {
+		return c
+	}
+	c := m.newClient(dialTimeout)
+	m.clients[dialTimeout] = c
+	return c
+}
+
+// checkDependencies returns an error if some of the services c depends on are not requested
+func (m *Manager) checkDependencies(c *connection, pending []*connection) error {
+	for _, name := range c.service.after {
+		var found bool
+		for _, dep := range append(m.conns[:len(m.conns):len(m.conns)], pending...) {
+			if dep != c && dep.service.networkService == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.Errorf("%s depends on %s which is not in the requested services", c.id, name)
+		}
+	}
+	return nil
+}
+
+// dependenciesReady returns true if all the services c depends on are connected and have verified datapath
+func (m *Manager) dependenciesReady(ctx context.Context, c *connection, pending []*connection) (bool, error) {
+	if err := m.checkDependencies(c, pending); err != nil {
+		return false, err
+	}
+	for _, name := range c.service.after {
+		for _, p := range pending {
+			if p != c && p.service.networkService == name {
+				return false, nil
+			}
+		}
+		for _, dep := range m.conns {
+			if dep.service.networkService != name {
+				continue
+			}
+			if dep.conn == nil {
+				return false, errors.Errorf("dependency %s of %s is not connected", dep.id, c.id)
+			}
+			if err := m.verify(ctx, dep); err != nil {
+				return false, errors.Wrapf(err, "dependency %s of %s is not ready", dep.id, c.id)
+			}
+		}
+	}
+	return true, nil
+}
+
+// verify waits for the datapath of the connection c to become alive
+func (m *Manager) verify(ctx context.Context, c *connection) error {
+	if c.verified || m.datapathCheck == nil {
+		return nil
+	}
+
+	verifyCtx, cancelVerify := context.WithTimeout(ctx, c.service.requestTimeout)
+	defer cancelVerify()
+
+	for {
+		checkCtx, cancelCheck := context.WithTimeout(verifyCtx, verifyInterval)
+		ok := m.datapathCheck(checkCtx, c.conn)
+		cancelCheck()
+		if ok {
+			c.verified = true
+			return nil
+		}
+
+		select {
+		case <-verifyCtx.Done():
+			return errors.Errorf("datapath of connection %s is not verified", c.id)
+		case <-time.After(verifyInterval):
+		}
+	}
+}
+
+// request requests the connection c resuming the connection with the same ID returned by the NSMgr monitor or saved
+// by the previous NSC run
+func (m *Manager) request(ctx context.Context, c *connection, saved *networkservice.Connection) error {
+	var preferences []*networkservice.Mechanism
+	for _, mech := range c.service.mechanisms {
+		if !supportedMechanisms[mech.Type] {
+			return errors.Errorf("mechanism type: %v is not supported", mech.Type)
+		}
+		preferences = append(preferences, mech.Clone())
+	}
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id:                         c.id,
+			NetworkService:             c.service.networkService,
+			NetworkServiceEndpointName: c.service.nse,
+			Labels:                     c.service.labels,
+			Payload:                    c.service.payload,
+		},
+		MechanismPreferences: preferences,
+	}
+	if len(c.service.srcIPs) > 0 || c.service.family != "" {
+		request.Connection.Context = &networkservice.ConnectionContext{
+			IpContext: &networkservice.IPContext{
+				SrcIpAddrs:       append([]string(nil), c.service.srcIPs...),
+				ExcludedPrefixes: c.service.family.excludedPrefixes(),
+			},
+		}
+	}
+
+	resumed := false
+	for _, conn := range m.monitoredConnections(ctx, c.id, c.service.requestTimeout) {
+		path := conn.GetPath()
+		if path.Index == 1 && path.PathSegments[0].Id == c.id && c.service.hasMechanism(conn.GetMechanism().GetType()) {
+			request.Connection = conn
+			request.Connection.Path.Index = 0
+			request.Connection.Id = c.id
+			resumed = true
+			break
+		}
+	}
+	if !resumed && saved != nil && saved.GetPath() != nil {
+		log.FromContext(ctx).Infof("resuming connection %s from the state file", c.id)
+		request.Connection = saved
+		request.Connection.Path.Index = 0
+	}
+
+	// Each request is bounded by the service timeout, so the failed one returns an error and is retried with
+	// backoff instead of blocking the caller holding the Manager lock
+	requestCtx, cancelRequest := context.WithTimeout(ctx, c.service.requestTimeout)
+	defer cancelRequest()
+	if c.service.attachment != nil {
+		requestCtx = attach.WithInterface(requestCtx, c.service.attachment)
+	}
+	if c.service.peer != "" {
+		requestCtx = attach.WithPeer(requestCtx, c.service.peer)
+	}
+	if c.service.group != "" {
+		requestCtx = redundancy.WithGroup(requestCtx, c.service.group)
+	}
+	if c.service.ecmpGroup != "" {
+		requestCtx = ecmp.WithGroup(requestCtx, c.service.ecmpGroup)
+	}
+	if c.service.routes != nil {
+		requestCtx = routes.WithRoutes(requestCtx, c.service.routes)
+	}
+	if c.service.leaking != nil {
+		requestCtx = isolation.WithLeaking(requestCtx, c.service.leaking)
+	}
+	if c.service.mtu != nil {
+		requestCtx = mtu.WithConfig(requestCtx, c.service.mtu)
+	}
+	if c.service.qos != nil {
+		requestCtx = qos.WithConfig(requestCtx, c.service.qos)
+	}
+	conn, err := c.client.Request(requestCtx, request)
+	if err != nil {
+		return errors.Wrapf(err, "request has failed for %s", c.service.url.String())
+	}
+	if err = c.service.family.check(conn); err != nil {
+		closeCtx, cancelClose := context.WithTimeout(ctx, c.service.requestTimeout)
+		defer cancelClose()
+		if _, closeErr := c.client.Close(closeCtx, conn); closeErr != nil {
+			err = errors.Wrapf(err, "connection closed with error: %s", closeErr.Error())
+		}
+		return errors.Wrapf(err, "unexpected response for %s", c.service.url.String())
+	}
+	c.conn = conn
+	m.startWatch(c)
+
+	log.FromContext(ctx).Infof("connection %s to %s is established", c.id, c.service.url.String())
+	return nil
+}
+
+func (m *Manager) monitoredConnections(ctx context.Context, id string, timeout time.Duration) map[string]*networkservice.Connection {
+	monitorCtx, cancelMonitor := context.WithTimeout(ctx, timeout)
+	defer cancelMonitor()
+
+	stream, err := m.monitorClient.MonitorConnections(monitorCtx, &networkservice.MonitorScopeSelector{
+		PathSegments: []*networkservice.PathSegment{
+			{
+				Id: id,
+			},
+		},
+	})
+	if err != nil {
+		log.FromContext(ctx).Errorf("error from monitorConnectionClient: %s", err.Error())
+		return nil
+	}
+
+	event, err := stream.Recv()
+	if err != nil {
+		log.FromContext(ctx).Errorf("error from monitorConnection stream: %s", err.Error())
+		return nil
+	}
+	return event.Connections
+}
+
+func (m *Manager) close(ctx context.Context, c *connection) {
+	if c.cancelWatch != nil {
+		c.cancelWatch()
+		c.cancelWatch = nil
+	}
+	if c.cancelRetry != nil {
+		c.cancelRetry()
+		c.cancelRetry = nil
+	}
+	m.states.Delete(c.id)
+	if c.conn == nil {
+		return
+	}
+
+	closeCtx, cancelClose := context.WithTimeout(ctx, c.service.requestTimeout)
+	defer cancelClose()
+
+	if _, err := c.client.Close(closeCtx, c.conn); err != nil {
+		log.FromContext(ctx).Errorf("failed to close connection %s: %s", c.id, err.Error())
+		return
+	}
+	log.FromContext(ctx).Infof("connection %s to %s is closed", c.id, c.service.url.String())
+}
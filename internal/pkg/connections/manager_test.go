@@ -0,0 +1,158 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections_test
+
+import (
+	"context"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/connections"
+)
+
+// testClient establishes the requested connections except for the failed Network Services: it returns an error
+// for them or blocks until the request context is done if hang is set
+type testClient struct {
+	failed map[string]bool
+	hang   bool
+	closed int32
+}
+
+func (c *testClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, _ ...grpc.CallOption) (*networkservice.Connection, error) {
+	if !c.failed[request.GetConnection().GetNetworkService()] {
+		conn := request.GetConnection().Clone()
+		conn.Mechanism = request.GetMechanismPreferences()[0]
+		return conn, nil
+	}
+	if c.hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return nil, errors.New("network service is not available")
+}
+
+func (c *testClient) Close(context.Context, *networkservice.Connection, ...grpc.CallOption) (*emptypb.Empty, error) {
+	atomic.AddInt32(&c.closed, 1)
+	return new(emptypb.Empty), nil
+}
+
+// testMonitorClient has no connections to resume or watch
+type testMonitorClient struct{}
+
+func (testMonitorClient) MonitorConnections(context.Context, *networkservice.MonitorScopeSelector, ...grpc.CallOption) (networkservice.MonitorConnection_MonitorConnectionsClient, error) {
+	return nil, errors.New("monitor is not available")
+}
+
+func newTestManager(ctx context.Context, client networkservice.NetworkServiceClient, opts ...connections.Option) *connections.Manager {
+	return connections.NewManager(ctx, "nsc", func(time.Duration) networkservice.NetworkServiceClient {
+		return client
+	}, testMonitorClient{}, append([]connections.Option{connections.WithRequestTimeout(time.Second / 10)}, opts...)...)
+}
+
+func parseURLs(t *testing.T, rawURLs ...string) []url.URL {
+	var result []url.URL
+	for _, rawURL := range rawURLs {
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		result = append(result, *u)
+	}
+	return result
+}
+
+func TestManager_Update_FailedService(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		hang bool
+	}{
+		{name: "error"},
+		{name: "hang", hang: true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			m := newTestManager(ctx, &testClient{failed: map[string]bool{"bad": true}, hang: tc.hang},
+				connections.WithMaxParallelRequests(2))
+
+			networkServices := parseURLs(t, "kernel://good-1/nsm-1", "kernel://bad/nsm-2", "kernel://good-2/nsm-3")
+			done := make(chan error, 1)
+			go func() {
+				done <- m.Update(ctx, networkServices)
+			}()
+			select {
+			case err := <-done:
+				require.NoError(t, err)
+			case <-time.After(5 * time.Second):
+				require.FailNow(t, "Update is blocked by the failed request")
+			}
+
+			states := make(map[string]connections.State)
+			for _, s := range m.Services() {
+				u, err := url.Parse(s.URL)
+				require.NoError(t, err)
+				states[u.Host] = s.State
+			}
+			require.Equal(t, map[string]connections.State{
+				"good-1": connections.StateConnected,
+				"bad":    connections.StateRetrying,
+				"good-2": connections.StateConnected,
+			}, states)
+			require.Error(t, m.Established(ctx))
+		})
+	}
+}
+
+func TestManager_Update_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		url  string
+	}{
+		{name: "invalid url", url: "kernel://nsm-3?mtu=big"},
+		{name: "missing dependency", url: "kernel://nsm-3?after=nsm-4"},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			client := new(testClient)
+			m := newTestManager(ctx, client)
+			require.NoError(t, m.Update(ctx, parseURLs(t, "kernel://nsm-1", "kernel://nsm-2")))
+			services := m.Services()
+			require.Len(t, services, 2)
+
+			// nsm-2 is removed by the update, but it must be kept as the update fails
+			require.Error(t, m.Update(ctx, parseURLs(t, "kernel://nsm-1", tc.url)))
+			require.Equal(t, services, m.Services())
+			require.Zero(t, atomic.LoadInt32(&client.closed))
+
+			require.NoError(t, m.Update(ctx, parseURLs(t, "kernel://nsm-1")))
+			require.Len(t, m.Services(), 1)
+			require.Equal(t, int32(1), atomic.LoadInt32(&client.closed))
+		})
+	}
+}
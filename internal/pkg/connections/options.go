@@ -0,0 +1,90 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"time"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/heal"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/labels"
+)
+
+// Option is an option pattern for NewManager
+type Option func(m *Manager)
+
+// WithRequestTimeout sets the default timeout for a single request and for the close
+func WithRequestTimeout(requestTimeout time.Duration) Option {
+	return func(m *Manager) {
+		m.requestTimeout = requestTimeout
+	}
+}
+
+// WithDialTimeout sets the default NSMgr dial timeout
+func WithDialTimeout(dialTimeout time.Duration) Option {
+	return func(m *Manager) {
+		m.dialTimeout = dialTimeout
+	}
+}
+
+// WithDatapathCheck sets the check used to verify the datapath of the connections other services depend on
+func WithDatapathCheck(datapathCheck heal.LivenessCheck) Option {
+	return func(m *Manager) {
+		m.datapathCheck = datapathCheck
+	}
+}
+
+// WithMaxParallelRequests sets the number of services requested at the same time
+func WithMaxParallelRequests(maxParallelRequests int) Option {
+	return func(m *Manager) {
+		if maxParallelRequests > 0 {
+			m.maxParallelRequests = maxParallelRequests
+		}
+	}
+}
+
+// WithLabels sets the labels added to the requests of all the services, the URL labels take precedence. The label
+// values may reference the variables as {name}.
+func WithLabels(l map[string]string, variables labels.Variables) Option {
+	return func(m *Manager) {
+		m.labels = l
+		m.variables = variables
+	}
+}
+
+// WithStateFile sets the file the established connections are saved to, so they can be resumed after restart even
+// if NSMgr doesn't return them in the monitor initial state, and the connections not requested anymore are closed
+func WithStateFile(stateFile string) Option {
+	return func(m *Manager) {
+		m.stateFile = stateFile
+	}
+}
+
+// WithStartupJitter sets the maximum random delay before the first Update requests the services, so the NSC pods
+// restarted at the same time don't request NSMgr and NSEs all at once
+func WithStartupJitter(startupJitter time.Duration) Option {
+	return func(m *Manager) {
+		m.startupJitter = startupJitter
+	}
+}
+
+// WithRetryJitter sets the maximum random delay added to the backoff between the retries of a failed request
+func WithRetryJitter(retryJitter time.Duration) Option {
+	return func(m *Manager) {
+		m.retryJitter = retryJitter
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+const (
+	minRetryDelay = time.Second
+	maxRetryDelay = time.Minute
+)
+
+// State is a state of the requested Network Service
+type State string
+
+const (
+	// StateConnected - the connection is established
+	StateConnected State = "connected"
+	// StateRetrying - the request has failed and is retried with backoff
+	StateRetrying State = "retrying"
+)
+
+// ServiceState is a state of a single requested Network Service connection
+type ServiceState struct {
+	ID       string `json:"id"`
+	URL      string `json:"url"`
+	State    State  `json:"state"`
+	Attempts int    `json:"attempts,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Services returns the states of all managed connections sorted by ID
+func (m *Manager) Services() []ServiceState {
+	var result []ServiceState
+	m.states.Range(func(_, value any) bool {
+		result = append(result, value.(ServiceState))
+		return true
+	})
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// ServeHTTP writes the states of all managed connections as JSON
+func (m *Manager) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(m.Services())
+}
+
+// retrying returns IDs of the connections being retried
+func (m *Manager) retrying() []string {
+	var result []string
+	for _, s := range m.Services() {
+		if s.State == StateRetrying {
+			result = append(result, s.ID)
+		}
+	}
+	return result
+}
+
+func (m *Manager) setState(c *connection) {
+	s := ServiceState{
+		ID:    c.id,
+		URL:   c.service.url.String(),
+		State: StateConnected,
+	}
+	if c.conn == nil {
+		s.State = StateRetrying
+		s.Attempts = c.attempts
+		if c.failure != nil {
+			s.Error = c.failure.Error()
+		}
+	}
+	m.states.Store(c.id, s)
+}
+
+// retryLater records the failure of the connection c and requests it again with exponential backoff until it is
+// established or closed
+func (m *Manager) retryLater(ctx context.Context, c *connection, err error) {
+	log.FromContext(ctx).Errorf("failed to connect %s, retrying: %+v", c.id, err)
+
+	c.failure = err
+	m.setState(c)
+	if c.cancelRetry != nil {
+		return
+	}
+	retryCtx, cancelRetry := context.WithCancel(m.ctx)
+	c.cancelRetry = cancelRetry
+	go m.retry(retryCtx, c)
+}
+
+func (m *Manager) retry(ctx context.Context, c *connection) {
+	delay := minRetryDelay
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay + jitter(m.retryJitter)):
+		}
+		if m.retryOnce(ctx, c) {
+			return
+		}
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// retryOnce requests the connection c again and returns true if it does not need to be retried anymore
+func (m *Manager) retryOnce(ctx context.Context, c *connection) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	defer m.saveState(m.ctx)
+
+	if ctx.Err() != nil {
+		return true
+	}
+
+	c.attempts++
+	_, err := m.dependenciesReady(m.ctx, c, nil)
+	if err == nil {
+		err = m.request(m.ctx, c, m.savedConnection(c))
+	}
+	if err != nil {
+		log.FromContext(m.ctx).Warnf("attempt %d to connect %s has failed: %v", c.attempts, c.id, err.Error())
+		c.failure = err
+		m.setState(c)
+		return false
+	}
+
+	c.cancelRetry()
+	c.cancelRetry = nil
+	c.failure = nil
+	c.attempts = 0
+	m.setState(c)
+	m.established.Store(m.allConnected())
+	return true
+}
+
+// allConnected returns true if all the connections requested with Update are established
+func (m *Manager) allConnected() bool {
+	for _, c := range m.conns {
+		if !c.runtime && c.conn == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// jitter returns a random duration in [0, maxJitter), so the NSC instances started at the same time don't request
+// NSMgr at the same moments
+func jitter(maxJitter time.Duration) time.Duration {
+	if maxJitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(maxJitter))) // nolint:gosec
+}
+
+// delayStart waits for the random startup jitter before the first Update requests anything
+func (m *Manager) delayStart(ctx context.Context) {
+	delay := jitter(m.startupJitter)
+	if delay == 0 {
+		return
+	}
+	log.FromContext(ctx).Infof("delaying the initial requests by %s", delay)
+	select {
+	case <-ctx.Done():
+	case <-time.After(delay):
+	}
+}
@@ -0,0 +1,724 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/common"
+	memifmech "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/vxlan"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/wireguard"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/payload"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/interdomain"
+	"github.com/networkservicemesh/sdk/pkg/tools/nsurl"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/attach"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/isolation"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/memif"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/mtu"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/qos"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/routes"
+)
+
+// Query parameters of the Network Service URL configuring the NSC itself. They are not sent as labels.
+const (
+	// RequestTimeoutParam overrides the request timeout for the service, e.g. memif://my-service?requestTimeout=60s
+	RequestTimeoutParam = "requestTimeout"
+	// DialTimeoutParam overrides the NSMgr dial timeout for the service, e.g. memif://my-service?dialTimeout=10s
+	DialTimeoutParam = "dialTimeout"
+	// AfterParam lists comma separated Network Services which connections should be established and verified
+	// before requesting the service, e.g. memif://data-service?after=management-service
+	AfterParam = "after"
+	// FallbackParam lists comma separated mechanisms requested if the URL scheme mechanism is not available,
+	// e.g. memif://my-service/nsm-1?fallback=kernel
+	FallbackParam = "fallback"
+	// VhostUserParam sets the vhost-user socket file of the VPP interface L2 cross connected to the connection
+	// interface, e.g. memif://my-service?vhostUser=/var/run/vhost/my-service.sock
+	VhostUserParam = "vhostUser"
+	// HostInterfaceParam sets the existing host interface attached to VPP and L2 cross connected to the connection
+	// interface, e.g. memif://my-service?hostInterface=eth1
+	HostInterfaceParam = "hostInterface"
+	// TapParam sets the name of the tap interface created in the pod network namespace, L2 cross connected to the
+	// connection interface and configured with the connection IP context, so the standard socket applications use
+	// the connection, e.g. memif://my-service?tap=nsm-1
+	TapParam = "tap"
+	// XconnectParam sets the peer Network Service which connection interface is L2 cross connected to the connection
+	// interface, so VPP passes the traffic between the connections as a bump in the wire, e.g.
+	// memif://service-b?xconnect=service-a. The peer connection is cross connected once both are established.
+	XconnectParam = "xconnect"
+	// RedundancyParam requests the service twice, so the standby connection takes over the routing once the
+	// datapath of the active one is lost, e.g. memif://my-service?redundancy=active-standby. Only active-standby is
+	// supported.
+	RedundancyParam = "redundancy"
+	// RedundancyRoleParam is set by the NSC on the redundant connection requests: primary or standby. It is sent as
+	// a label, so the Network Service matches select different endpoints for the connections.
+	RedundancyRoleParam = "redundancyRole"
+	// ReplicasParam requests the number of the connections to the service, the traffic is balanced over them with
+	// the ECMP routes for the prefixes they share, e.g. memif://my-service?replicas=2
+	ReplicasParam = "replicas"
+	// ReplicaParam is set by the NSC on the replica connection requests: the replica number from 1. It is sent as a
+	// label, so the Network Service matches select different endpoints for the replicas.
+	ReplicaParam = "replica"
+	// HostInterfaceModeParam selects how the host interface is attached to VPP: af_packet (default) or af_xdp
+	HostInterfaceModeParam = "hostInterfaceMode"
+	// RouteParam lists comma separated destination prefixes routed in VPP via the connection interface in addition
+	// to the IP context routes, e.g. memif://my-service?route=10.10.0.0/16
+	RouteParam = "route"
+	// PolicyRouteParam lists comma separated source prefixes which traffic coming from the other NSM connections
+	// is forwarded via the connection interface, e.g. memif://my-service?policyRoute=172.16.0.0/24
+	PolicyRouteParam = "policyRoute"
+	// VrfExportParam lists comma separated prefixes reachable via the connection which are routed from the default
+	// VPP table into the connection VRF, used with the VRF isolation, e.g. memif://my-service?vrfExport=10.10.0.0/16
+	VrfExportParam = "vrfExport"
+	// VrfImportParam lists comma separated prefixes of the default VPP table which are routed from the connection
+	// VRF, used with the VRF isolation, e.g. memif://my-service?vrfImport=0.0.0.0/0
+	VrfImportParam = "vrfImport"
+	// SrcIPParam lists comma separated source addresses requested for the connection, so the workload keeps them
+	// across reconnects, e.g. memif://my-service?srcip=172.16.1.10/32. The address without the prefix length is
+	// requested as a host address.
+	SrcIPParam = "srcip"
+	// FamilyParam selects the address families of the connection: ipv4, ipv6 or dual, e.g.
+	// memif://my-service?family=ipv6. The other family is excluded in the request and the response is checked to
+	// have the addresses of the requested families only.
+	FamilyParam = "family"
+	// MTUParam overrides the MTU of the connection interface, e.g. memif://my-service?mtu=1400
+	MTUParam = "mtu"
+	// MSSClampParam enables the TCP MSS clamping to the MTU on the connection interface, e.g.
+	// memif://my-service?mssClamp=true
+	MSSClampParam = "mssClamp"
+	// IngressRateParam polices the traffic coming from the connection to the rate in bit/s with an optional k, M or
+	// G suffix, e.g. memif://my-service?ingressRate=100M. The traffic exceeding the rate is dropped.
+	IngressRateParam = "ingressRate"
+	// IngressBurstParam overrides the burst of the ingress rate in bytes, by default the traffic of 100ms at the rate
+	IngressBurstParam = "ingressBurst"
+	// EgressRateParam limits the traffic sent to the connection to the rate in bit/s with an optional k, M or G
+	// suffix, e.g. memif://my-service?egressRate=50M
+	EgressRateParam = "egressRate"
+	// EgressBurstParam overrides the burst of the egress rate in bytes, by default the traffic of 100ms at the rate
+	EgressBurstParam = "egressBurst"
+	// DSCPParam rewrites the DSCP of the IP packets sent to the connection to the value from 0 to 63 or its name,
+	// e.g. memif://my-service?dscp=ef, so the forwarders and the underlay prioritize the traffic
+	DSCPParam = "dscp"
+	// PayloadParam selects the payload of the connection: ip or ethernet, e.g. memif://my-service?payload=ip. The
+	// memif interface is created in the matching mode. By default the payload is selected by the mechanism.
+	PayloadParam = "payload"
+	// NSEParam selects the Network Service Endpoint instead of the NSMgr selection, e.g. memif://my-service?nse=nse-1.
+	// For the interdomain service the endpoint of the service domain is selected, e.g.
+	// memif://my-service@dc.example.com?nse=nse-1.
+	NSEParam = "nse"
+)
+
+// Redundancy modes and roles of the connections
+const (
+	activeStandby = "active-standby"
+	primaryRole   = "primary"
+	standbyRole   = "standby"
+)
+
+// maxReplicas is the largest number of the replica connections of a service
+const maxReplicas = 16
+
+// remoteMechanisms are the mechanisms connecting to the remote NSE through the tunnels
+var remoteMechanisms = map[string]bool{
+	wireguard.MECHANISM: true,
+	vxlan.MECHANISM:     true,
+}
+
+// mechanismPayloads are the only payloads the mechanisms support: VPP wireguard tunnels carry IP payload and VPP vxlan
+// tunnels carry Ethernet payload
+var mechanismPayloads = map[string]string{
+	wireguard.MECHANISM: payload.IP,
+	vxlan.MECHANISM:     payload.Ethernet,
+}
+
+// Payloads of the connection
+const (
+	ipPayload       = "ip"
+	ethernetPayload = "ethernet"
+)
+
+// Address families of the connection
+const (
+	ipv4Family family = "ipv4"
+	ipv6Family family = "ipv6"
+	dualFamily family = "dual"
+)
+
+// family is a set of the address families of the connection, empty if any families are accepted
+type family string
+
+func parseFamily(s string) (family, error) {
+	switch f := family(strings.ToLower(s)); f {
+	case "", ipv4Family, ipv6Family, dualFamily:
+		return f, nil
+	default:
+		return "", errors.Errorf("unknown address family %s", s)
+	}
+}
+
+// excludedPrefixes returns the prefixes excluding the families not requested
+func (f family) excludedPrefixes() []string {
+	switch f {
+	case ipv4Family:
+		return []string{"::/0"}
+	case ipv6Family:
+		return []string{"0.0.0.0/0"}
+	default:
+		return nil
+	}
+}
+
+// check returns an error if the source addresses of conn don't match the families
+func (f family) check(conn *networkservice.Connection) error {
+	if f == "" {
+		return nil
+	}
+	var hasIPv4, hasIPv6 bool
+	for _, ipNet := range conn.GetContext().GetIpContext().GetSrcIPNets() {
+		if ipNet.IP.To4() != nil {
+			hasIPv4 = true
+		} else {
+			hasIPv6 = true
+		}
+	}
+	wantIPv4 := f == ipv4Family || f == dualFamily
+	wantIPv6 := f == ipv6Family || f == dualFamily
+	if hasIPv4 != wantIPv4 || hasIPv6 != wantIPv6 {
+		return errors.Errorf("%s addresses are requested, got %v", f,
+			conn.GetContext().GetIpContext().GetSrcIpAddrs())
+	}
+	return nil
+}
+
+// Validate returns an error if any of the Network Service URLs or the URLs they expand into is invalid or requests
+// the mechanisms other than mechanisms if they are set
+func Validate(networkServices []url.URL, mechanisms ...string) error {
+	supported := make(map[string]bool, len(mechanisms))
+	for _, mechanism := range mechanisms {
+		supported[mechanism] = true
+	}
+
+	expanded, err := expand(networkServices)
+	if err != nil {
+		return err
+	}
+	for i := range expanded {
+		s, parseErr := parseService(&expanded[i], 0, 0)
+		if parseErr != nil {
+			return parseErr
+		}
+		for _, mechanism := range s.mechanisms {
+			if len(supported) > 0 && !supported[mechanism.GetType()] {
+				return errors.Errorf("unsupported mechanism %s in %s", mechanism.GetType(), expanded[i].String())
+			}
+		}
+	}
+	return nil
+}
+
+// expand replaces the URL templates with the URLs they expand into, the URLs with the RedundancyParam with
+// the primary and standby ones and the URLs with the ReplicasParam with the replica ones
+func expand(networkServices []url.URL) ([]url.URL, error) {
+	var result []url.URL
+	for i := range networkServices {
+		urls, err := expandTemplate(&networkServices[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range urls {
+			expanded, expandErr := expandService(u)
+			if expandErr != nil {
+				return nil, expandErr
+			}
+			result = append(result, expanded...)
+		}
+	}
+	return result, nil
+}
+
+// expandService returns the URLs of the redundant or replica connections requested by the URL u, u itself if none
+func expandService(u url.URL) ([]url.URL, error) {
+	query := u.Query()
+	mode, replicas := query.Get(RedundancyParam), query.Get(ReplicasParam)
+	if (mode != "" || replicas != "") && (query.Get(RedundancyRoleParam) != "" || query.Get(ReplicaParam) != "") {
+		return nil, errors.Errorf("%s, %s are set by the NSC in %s", RedundancyRoleParam, ReplicaParam, u.String())
+	}
+	switch {
+	case mode != "" && replicas != "":
+		return nil, errors.Errorf("%s can't be used with %s in %s", RedundancyParam, ReplicasParam, u.String())
+	case mode != "":
+		if mode != activeStandby {
+			return nil, errors.Errorf("invalid %s in %s: %s", RedundancyParam, u.String(), mode)
+		}
+		return expandParam(u, RedundancyRoleParam, primaryRole, standbyRole), nil
+	case replicas != "":
+		n, err := strconv.Atoi(replicas)
+		if err != nil || n < 1 || n > maxReplicas {
+			return nil, errors.Errorf("invalid %s in %s: %s", ReplicasParam, u.String(), replicas)
+		}
+		var values []string
+		for replica := 1; replica <= n; replica++ {
+			values = append(values, strconv.Itoa(replica))
+		}
+		return expandParam(u, ReplicaParam, values...), nil
+	default:
+		return []url.URL{u}, nil
+	}
+}
+
+// expandParam returns the copies of the URL with the param set to the values
+func expandParam(u url.URL, param string, values ...string) []url.URL {
+	var result []url.URL
+	query := u.Query()
+	for _, value := range values {
+		query.Set(param, value)
+		expanded := u
+		expanded.RawQuery = query.Encode()
+		result = append(result, expanded)
+	}
+	return result
+}
+
+// parseGroup returns the redundancy group of the expanded URL: the URL without the role
+func parseGroup(u *url.URL, query url.Values) (string, error) {
+	if query.Get(RedundancyParam) == "" {
+		return "", nil
+	}
+	switch role := query.Get(RedundancyRoleParam); role {
+	case primaryRole, standbyRole:
+	case "":
+		return "", errors.Errorf("%s is supported for the configured Network Services only in %s", RedundancyParam, u.String())
+	default:
+		return "", errors.Errorf("invalid %s in %s: %s", RedundancyRoleParam, u.String(), role)
+	}
+	groupQuery := u.Query()
+	groupQuery.Del(RedundancyRoleParam)
+	group := *u
+	group.RawQuery = groupQuery.Encode()
+	return group.String(), nil
+}
+
+// parseECMPGroup returns the ECMP group of the expanded replica URL: the URL without the replica number
+func parseECMPGroup(u *url.URL, query url.Values) (string, error) {
+	if query.Get(ReplicasParam) == "" {
+		return "", nil
+	}
+	if query.Get(ReplicaParam) == "" {
+		return "", errors.Errorf("%s is supported for the configured Network Services only in %s", ReplicasParam, u.String())
+	}
+	groupQuery := u.Query()
+	groupQuery.Del(ReplicaParam)
+	group := *u
+	group.RawQuery = groupQuery.Encode()
+	return group.String(), nil
+}
+
+// parseLimit returns the rate limit set by the rate and burst parameters, nil if the rate is not set
+func parseLimit(u *url.URL, query url.Values, rateParam, burstParam string) (*qos.Limit, error) {
+	value := query.Get(rateParam)
+	if value == "" {
+		if query.Get(burstParam) != "" {
+			return nil, errors.Errorf("%s requires %s in %s", burstParam, rateParam, u.String())
+		}
+		return nil, nil
+	}
+	limit := new(qos.Limit)
+	var err error
+	if limit.Rate, err = qos.ParseRate(value); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s in %s", rateParam, u.String())
+	}
+	if value = query.Get(burstParam); value != "" {
+		if limit.Burst, err = strconv.ParseUint(value, 10, 64); err != nil || limit.Burst == 0 {
+			return nil, errors.Errorf("invalid %s in %s: %s", burstParam, u.String(), value)
+		}
+	}
+	return limit, nil
+}
+
+// Memif interface parameters, e.g. memif://my-service?rx-queues=4&tx-queues=4&ring-size=2048&buffer-size=4096 or
+// memif://my-service?role=master&socket-file=/var/run/memif/my-service.sock, are passed in the memif mechanism
+// parameters instead of the labels. See memif.ParameterKeys.
+
+// Host interface attach modes
+const (
+	AfPacketMode = "af_packet"
+	AfXDPMode    = "af_xdp"
+)
+
+// service is a parsed Network Service URL
+type service struct {
+	url            url.URL
+	networkService string
+	mechanisms     []*networkservice.Mechanism
+	labels         map[string]string
+	nse            string
+	payload        string
+	requestTimeout time.Duration
+	dialTimeout    time.Duration
+	after          []string
+	attachment     attach.Interface
+	peer           string
+	group          string
+	ecmpGroup      string
+	routes         *routes.Routes
+	leaking        *isolation.Leaking
+	srcIPs         []string
+	family         family
+	mtu            *mtu.Config
+	qos            *qos.Config
+}
+
+func parseService(u *url.URL, requestTimeout, dialTimeout time.Duration) (*service, error) {
+	s := &service{
+		url:            *u,
+		requestTimeout: requestTimeout,
+		dialTimeout:    dialTimeout,
+	}
+
+	query := u.Query()
+	var err error
+	if value := query.Get(RequestTimeoutParam); value != "" {
+		if s.requestTimeout, err = time.ParseDuration(value); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s in %s", RequestTimeoutParam, u.String())
+		}
+	}
+	if value := query.Get(DialTimeoutParam); value != "" {
+		if s.dialTimeout, err = time.ParseDuration(value); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s in %s", DialTimeoutParam, u.String())
+		}
+	}
+	for _, value := range query[AfterParam] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				s.after = append(s.after, name)
+			}
+		}
+	}
+
+	if value := query.Get(VhostUserParam); value != "" {
+		s.attachment = attach.NewVhostUser(value)
+	}
+	if value := query.Get(HostInterfaceParam); value != "" {
+		if s.attachment != nil {
+			return nil, errors.Errorf("only one of %s, %s, %s can be set in %s", VhostUserParam, HostInterfaceParam, TapParam, u.String())
+		}
+		switch mode := query.Get(HostInterfaceModeParam); mode {
+		case "", AfPacketMode:
+			s.attachment = attach.NewAfPacket(value)
+		case AfXDPMode:
+			s.attachment = attach.NewAfXDP(value)
+		default:
+			return nil, errors.Errorf("invalid %s in %s: %s", HostInterfaceModeParam, u.String(), mode)
+		}
+	}
+	if value := query.Get(TapParam); value != "" {
+		if s.attachment != nil {
+			return nil, errors.Errorf("only one of %s, %s, %s can be set in %s", VhostUserParam, HostInterfaceParam, TapParam, u.String())
+		}
+		s.attachment = attach.NewTap(value)
+	}
+	if s.peer = query.Get(XconnectParam); s.peer != "" && s.attachment != nil {
+		return nil, errors.Errorf("%s can't be used with %s, %s, %s in %s", XconnectParam, VhostUserParam, HostInterfaceParam, TapParam, u.String())
+	}
+
+	if s.group, err = parseGroup(u, query); err != nil {
+		return nil, err
+	}
+	if s.ecmpGroup, err = parseECMPGroup(u, query); err != nil {
+		return nil, err
+	}
+	if s.group != "" && s.ecmpGroup != "" {
+		return nil, errors.Errorf("%s can't be used with %s in %s", RedundancyParam, ReplicasParam, u.String())
+	}
+	if (s.group != "" || s.ecmpGroup != "") && (s.attachment != nil || s.peer != "") {
+		return nil, errors.Errorf("%s, %s can't be used with the L2 attachment in %s", RedundancyParam, ReplicasParam, u.String())
+	}
+
+	staticRoutes, err := routes.ParsePrefixes(query[RouteParam]...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s in %s", RouteParam, u.String())
+	}
+	policyRoutes, err := routes.ParsePrefixes(query[PolicyRouteParam]...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s in %s", PolicyRouteParam, u.String())
+	}
+	if len(staticRoutes) > 0 || len(policyRoutes) > 0 {
+		s.routes = &routes.Routes{
+			Static: staticRoutes,
+			Policy: policyRoutes,
+		}
+	}
+
+	vrfExport, err := routes.ParsePrefixes(query[VrfExportParam]...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s in %s", VrfExportParam, u.String())
+	}
+	vrfImport, err := routes.ParsePrefixes(query[VrfImportParam]...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s in %s", VrfImportParam, u.String())
+	}
+	if len(vrfExport) > 0 || len(vrfImport) > 0 {
+		s.leaking = &isolation.Leaking{
+			Export: vrfExport,
+			Import: vrfImport,
+		}
+	}
+
+	if s.srcIPs, err = parseAddresses(query[SrcIPParam]...); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s in %s", SrcIPParam, u.String())
+	}
+
+	if s.family, err = parseFamily(query.Get(FamilyParam)); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s in %s", FamilyParam, u.String())
+	}
+
+	mtuConfig := new(mtu.Config)
+	if value := query.Get(MTUParam); value != "" {
+		var v uint64
+		if v, err = strconv.ParseUint(value, 10, 32); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s in %s", MTUParam, u.String())
+		}
+		mtuConfig.MTU = uint32(v)
+	}
+	if value := query.Get(MSSClampParam); value != "" {
+		if mtuConfig.MSSClamp, err = strconv.ParseBool(value); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s in %s", MSSClampParam, u.String())
+		}
+	}
+	if mtuConfig.MTU != 0 || mtuConfig.MSSClamp {
+		s.mtu = mtuConfig
+	}
+
+	qosConfig := new(qos.Config)
+	if qosConfig.Ingress, err = parseLimit(u, query, IngressRateParam, IngressBurstParam); err != nil {
+		return nil, err
+	}
+	if qosConfig.Egress, err = parseLimit(u, query, EgressRateParam, EgressBurstParam); err != nil {
+		return nil, err
+	}
+	if value := query.Get(DSCPParam); value != "" {
+		var dscp uint8
+		if dscp, err = qos.ParseDSCP(value); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s in %s", DSCPParam, u.String())
+		}
+		qosConfig.DSCP = &dscp
+	}
+	if qosConfig.Ingress != nil || qosConfig.Egress != nil || qosConfig.DSCP != nil {
+		s.qos = qosConfig
+	}
+
+	switch value := query.Get(PayloadParam); value {
+	case "":
+	case ipPayload:
+		s.payload = payload.IP
+	case ethernetPayload:
+		s.payload = payload.Ethernet
+	default:
+		return nil, errors.Errorf("invalid %s in %s: %s", PayloadParam, u.String(), value)
+	}
+	if s.payload == payload.IP && (s.attachment != nil || s.peer != "") {
+		return nil, errors.Errorf("%s=%s can't be used with the L2 attachment in %s", PayloadParam, ipPayload, u.String())
+	}
+
+	memifParams := make(map[string]string)
+	for _, key := range memif.ParameterKeys {
+		if value := query.Get(key); value != "" {
+			memifParams[key] = value
+		}
+		query.Del(key)
+	}
+	if err = memif.ValidateParameters(memifParams); err != nil {
+		return nil, errors.Wrapf(err, "invalid memif parameters in %s", u.String())
+	}
+
+	var fallbacks []string
+	for _, value := range query[FallbackParam] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				fallbacks = append(fallbacks, name)
+			}
+		}
+	}
+
+	query.Del(RequestTimeoutParam)
+	query.Del(DialTimeoutParam)
+	query.Del(AfterParam)
+	query.Del(FallbackParam)
+	query.Del(VhostUserParam)
+	query.Del(HostInterfaceParam)
+	query.Del(HostInterfaceModeParam)
+	query.Del(TapParam)
+	query.Del(XconnectParam)
+	query.Del(RedundancyParam)
+	query.Del(ReplicasParam)
+	query.Del(RouteParam)
+	query.Del(PolicyRouteParam)
+	query.Del(VrfExportParam)
+	query.Del(VrfImportParam)
+	query.Del(SrcIPParam)
+	query.Del(FamilyParam)
+	query.Del(MTUParam)
+	query.Del(MSSClampParam)
+	query.Del(IngressRateParam)
+	query.Del(IngressBurstParam)
+	query.Del(EgressRateParam)
+	query.Del(EgressBurstParam)
+	query.Del(DSCPParam)
+	query.Del(PayloadParam)
+	nse := query.Get(NSEParam)
+	query.Del(NSEParam)
+	labelsURL := *u
+	labelsURL.RawQuery = query.Encode()
+
+	n := nsurl.NSURL(labelsURL)
+	s.networkService = n.NetworkService()
+	s.labels = n.Labels()
+	if err = validateNetworkService(u); err != nil {
+		return nil, errors.Wrapf(err, "invalid network service in %s", u.String())
+	}
+	if s.nse, err = parseNSE(s.networkService, nse); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s in %s", NSEParam, u.String())
+	}
+
+	ifName := n.Mechanism().GetParameters()[common.InterfaceNameKey]
+	for _, name := range append([]string{u.Scheme}, fallbacks...) {
+		mechanism := newMechanism(strings.ToUpper(name), ifName)
+		if mechanismPayload, ok := mechanismPayloads[mechanism.Type]; ok {
+			if s.payload != "" && s.payload != mechanismPayload {
+				return nil, errors.Errorf("%s mechanism doesn't support %s payload in %s", mechanism.Type, s.payload, u.String())
+			}
+			s.payload = mechanismPayload
+		}
+		s.mechanisms = append(s.mechanisms, mechanism)
+	}
+	for _, m := range s.mechanisms {
+		if m.Type != memifmech.MECHANISM || len(memifParams) == 0 {
+			continue
+		}
+		if m.Parameters == nil {
+			m.Parameters = make(map[string]string)
+		}
+		for key, value := range memifParams {
+			m.Parameters[key] = value
+		}
+	}
+
+	return s, nil
+}
+
+// newMechanism returns the mechanism of the type with the class of the type and the interface name if it isn't empty
+func newMechanism(mechanismType, ifName string) *networkservice.Mechanism {
+	mechanism := &networkservice.Mechanism{Cls: cls.LOCAL, Type: mechanismType}
+	if remoteMechanisms[mechanismType] {
+		mechanism.Cls = cls.REMOTE
+	}
+	if ifName != "" {
+		mechanism.Parameters = map[string]string{common.InterfaceNameKey: ifName}
+	}
+	return mechanism
+}
+
+// parseAddresses parses the comma separated addresses with the optional prefix length from values
+func parseAddresses(values ...string) ([]string, error) {
+	var result []string
+	for _, value := range values {
+		for _, addr := range strings.Split(value, ",") {
+			if addr = strings.TrimSpace(addr); addr == "" {
+				continue
+			}
+			if !strings.Contains(addr, "/") {
+				ip := net.ParseIP(addr)
+				if ip == nil {
+					return nil, errors.Errorf("invalid address %s", addr)
+				}
+				bits := net.IPv6len * 8
+				if ip.To4() != nil {
+					bits = net.IPv4len * 8
+				}
+				addr = fmt.Sprintf("%s/%d", addr, bits)
+			}
+			if _, _, err := net.ParseCIDR(addr); err != nil {
+				return nil, errors.Wrapf(err, "invalid address %s", addr)
+			}
+			result = append(result, addr)
+		}
+	}
+	return result, nil
+}
+
+// hasMechanism returns true if the mechanismType is one of the service mechanisms
+func (s *service) hasMechanism(mechanismType string) bool {
+	for _, mechanism := range s.mechanisms {
+		if mechanism.GetType() == mechanismType {
+			return true
+		}
+	}
+	return false
+}
+
+// validateNetworkService checks the Network Service name and the domain of the interdomain service
+func validateNetworkService(u *url.URL) error {
+	if _, ok := u.User.Password(); ok {
+		return errors.New("password is not allowed")
+	}
+	if u.Port() != "" {
+		return errors.New("port is not allowed")
+	}
+	if u.User == nil {
+		if u.Hostname() == "" {
+			return errors.New("network service name is empty")
+		}
+		return nil
+	}
+	if u.User.Username() == "" {
+		return errors.New("network service name is empty")
+	}
+	if u.Hostname() == "" {
+		return errors.New("domain is empty")
+	}
+	return nil
+}
+
+// parseNSE returns the Network Service Endpoint name requested for the Network Service. The endpoint of the
+// interdomain service is qualified with the service domain.
+func parseNSE(networkService, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if strings.Count(value, "@") > 1 || interdomain.Target(value) == "" || (interdomain.Is(value) && interdomain.Domain(value) == "") {
+		return "", errors.Errorf("malformed endpoint name: %s", value)
+	}
+	domain := interdomain.Domain(networkService)
+	switch {
+	case interdomain.Not(value) && domain != "":
+		return interdomain.Join(value, domain), nil
+	case interdomain.Is(value) && interdomain.Domain(value) != domain:
+		return "", errors.Errorf("endpoint %s is not in the domain of the service %s", value, networkService)
+	}
+	return value, nil
+}
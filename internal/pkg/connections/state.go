@@ -0,0 +1,136 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// loadState reads the connections saved by the previous NSC run from the state file
+func (m *Manager) loadState(ctx context.Context) {
+	if m.stateFile == "" {
+		return
+	}
+	data, err := os.ReadFile(m.stateFile)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		log.FromContext(ctx).Warnf("failed to read state file %s: %s", m.stateFile, err.Error())
+		return
+	}
+	state := new(networkservice.ConnectionEvent)
+	if err = protojson.Unmarshal(data, state); err != nil {
+		log.FromContext(ctx).Warnf("failed to parse state file %s: %s", m.stateFile, err.Error())
+		return
+	}
+	m.saved = state.GetConnections()
+	log.FromContext(ctx).Infof("loaded %d connections from state file %s", len(m.saved), m.stateFile)
+}
+
+// saveState writes the established connections to the state file, so they can be resumed after restart
+func (m *Manager) saveState(ctx context.Context) {
+	if m.stateFile == "" {
+		return
+	}
+	state := &networkservice.ConnectionEvent{
+		Type:        networkservice.ConnectionEventType_INITIAL_STATE_TRANSFER,
+		Connections: make(map[string]*networkservice.Connection),
+	}
+	for _, c := range m.conns {
+		if c.conn != nil {
+			state.Connections[c.id] = c.conn
+		}
+	}
+	if err := writeState(m.stateFile, state); err != nil {
+		log.FromContext(ctx).Warnf("failed to write state file %s: %+v", m.stateFile, err)
+	}
+}
+
+func writeState(stateFile string, state *networkservice.ConnectionEvent) error {
+	data, err := protojson.Marshal(state)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal connections")
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(stateFile), filepath.Base(stateFile)+".*")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary state file")
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, "failed to write temporary state file")
+	}
+	if err = tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to close temporary state file")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), stateFile), "failed to replace state file")
+}
+
+// savedConnection removes the connection c saved by the previous NSC run and returns it if its mechanism is still
+// requested. It must be called with m.mu locked.
+func (m *Manager) savedConnection(c *connection) *networkservice.Connection {
+	conn, ok := m.saved[c.id]
+	if !ok {
+		return nil
+	}
+	delete(m.saved, c.id)
+	if !c.service.hasMechanism(conn.GetMechanism().GetType()) {
+		return nil
+	}
+	return conn
+}
+
+// cleanupSaved closes the connections left from the previous NSC run which are not managed anymore. The
+// connection is requested first, so the chain knows it and forwards the close to NSMgr.
+func (m *Manager) cleanupSaved(ctx context.Context) {
+	for id, conn := range m.saved {
+		if m.hasID(id, nil) {
+			continue
+		}
+		delete(m.saved, id)
+		if conn.GetPath() == nil {
+			continue
+		}
+		log.FromContext(ctx).Infof("closing connection %s left from the previous run", id)
+
+		client := m.client(m.dialTimeout)
+		cleanupCtx, cancelCleanup := context.WithTimeout(ctx, m.requestTimeout)
+		conn.Path.Index = 0
+		established, err := client.Request(cleanupCtx, &networkservice.NetworkServiceRequest{
+			Connection:           conn,
+			MechanismPreferences: []*networkservice.Mechanism{conn.GetMechanism()},
+		})
+		if err == nil {
+			_, err = client.Close(cleanupCtx, established)
+		}
+		cancelCleanup()
+		if err != nil {
+			log.FromContext(ctx).Warnf("failed to close connection %s left from the previous run: %s", id, err.Error())
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/connections"
+)
+
+// pathClient establishes the requested connections with the NSC path segment and records the IDs of the
+// connections requested with the path, i.e. resumed
+type pathClient struct {
+	mu      sync.Mutex
+	resumed []string
+}
+
+func (c *pathClient) Request(_ context.Context, request *networkservice.NetworkServiceRequest, _ ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn := request.GetConnection().Clone()
+	if conn.GetPath() != nil {
+		c.mu.Lock()
+		c.resumed = append(c.resumed, conn.GetId())
+		c.mu.Unlock()
+	} else {
+		conn.Path = &networkservice.Path{
+			PathSegments: []*networkservice.PathSegment{{Id: conn.GetId()}},
+		}
+	}
+	conn.Mechanism = request.GetMechanismPreferences()[0]
+	return conn, nil
+}
+
+func (c *pathClient) Close(context.Context, *networkservice.Connection, ...grpc.CallOption) (*emptypb.Empty, error) {
+	return new(emptypb.Empty), nil
+}
+
+// barrierMonitorClient blocks the first n monitor calls until all of them are made, so the parallel requests
+// resume the saved connections at the same time
+type barrierMonitorClient struct {
+	n       int32
+	calls   int32
+	barrier chan struct{}
+}
+
+func newBarrierMonitorClient(n int) *barrierMonitorClient {
+	return &barrierMonitorClient{
+		n:       int32(n),
+		barrier: make(chan struct{}),
+	}
+}
+
+func (c *barrierMonitorClient) MonitorConnections(ctx context.Context, _ *networkservice.MonitorScopeSelector, _ ...grpc.CallOption) (networkservice.MonitorConnection_MonitorConnectionsClient, error) {
+	switch calls := atomic.AddInt32(&c.calls, 1); {
+	case calls == c.n:
+		close(c.barrier)
+	case calls < c.n:
+		select {
+		case <-ctx.Done():
+		case <-c.barrier:
+		}
+	}
+	return nil, errors.New("monitor is not available")
+}
+
+func TestManager_StateFile_Resume(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		services int
+		parallel int
+	}{
+		{name: "single", services: 1, parallel: 1},
+		{name: "parallel", services: 8, parallel: 4},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// The requests are made with the context without the synchronized logger and the cancel, so they don't
+			// hide the concurrent access to the saved connections from the race detector
+			requestCtx := log.WithLog(context.Background(), log.Empty())
+			ctx, cancel := context.WithCancel(requestCtx)
+			defer cancel()
+
+			var rawURLs []string
+			for i := 1; i <= tc.services; i++ {
+				rawURLs = append(rawURLs, fmt.Sprintf("kernel://service-%d/nsm-%d", i, i))
+			}
+			stateFile := filepath.Join(t.TempDir(), "connections.json")
+
+			before := newTestManager(ctx, new(pathClient), connections.WithStateFile(stateFile),
+				connections.WithMaxParallelRequests(4))
+			require.NoError(t, before.Update(ctx, parseURLs(t, rawURLs...)))
+			require.NoError(t, before.Established(ctx))
+
+			client := new(pathClient)
+			after := connections.NewManager(ctx, "nsc", func(time.Duration) networkservice.NetworkServiceClient {
+				return client
+			}, newBarrierMonitorClient(tc.parallel), connections.WithStateFile(stateFile),
+				connections.WithMaxParallelRequests(4))
+			require.NoError(t, after.Update(requestCtx, parseURLs(t, rawURLs...)))
+			require.NoError(t, after.Established(ctx))
+
+			var ids []string
+			for _, s := range after.Services() {
+				ids = append(ids, s.ID)
+			}
+			require.Len(t, ids, tc.services)
+			require.ElementsMatch(t, ids, client.resumed)
+		})
+	}
+}
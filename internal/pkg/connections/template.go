@@ -0,0 +1,133 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// maxTemplateURLs limits the number of the URLs a single Network Service URL template expands into
+const maxTemplateURLs = 1024
+
+// templateRange matches the {first..last} numeric ranges of the Network Service URL templates
+var templateRange = regexp.MustCompile(`\{(\d+)\.\.(\d+)\}`)
+
+// expandTemplate expands the {first..last} ranges in the path and the query values of the Network Service URL into
+// the URLs with all the combinations of the range values, e.g. memif://lb-service?instance={1..50} into 50 URLs with
+// the instance label from 1 to 50. The values are zero padded if first is, e.g. {01..10}. The URLs without
+// the ranges are returned as is.
+func expandTemplate(u *url.URL) ([]url.URL, error) {
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	isTemplate := templateRange.MatchString(u.Path)
+	for key, values := range query {
+		keys = append(keys, key)
+		for _, value := range values {
+			isTemplate = isTemplate || templateRange.MatchString(value)
+		}
+	}
+	if !isTemplate {
+		return []url.URL{*u}, nil
+	}
+	sort.Strings(keys)
+
+	paths, err := expandRanges(u.Path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid template %s", u.String())
+	}
+	queries := []url.Values{{}}
+	for _, key := range keys {
+		for _, value := range query[key] {
+			values, rangesErr := expandRanges(value)
+			if rangesErr != nil {
+				return nil, errors.Wrapf(rangesErr, "invalid template %s", u.String())
+			}
+			if len(paths)*len(queries)*len(values) > maxTemplateURLs {
+				return nil, errors.Errorf("template %s expands into more than %d URLs", u.String(), maxTemplateURLs)
+			}
+			var next []url.Values
+			for _, q := range queries {
+				for _, v := range values {
+					expanded := make(url.Values, len(q)+1)
+					for k, vs := range q {
+						expanded[k] = append([]string(nil), vs...)
+					}
+					expanded.Add(key, v)
+					next = append(next, expanded)
+				}
+			}
+			queries = next
+		}
+	}
+
+	var result []url.URL
+	for _, path := range paths {
+		for _, q := range queries {
+			expanded := *u
+			expanded.Path = path
+			expanded.RawPath = ""
+			expanded.RawQuery = q.Encode()
+			result = append(result, expanded)
+		}
+	}
+	return result, nil
+}
+
+// expandRanges returns the copies of s with all the combinations of the {first..last} range values
+func expandRanges(s string) ([]string, error) {
+	loc := templateRange.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return []string{s}, nil
+	}
+	firstValue, lastValue := s[loc[2]:loc[3]], s[loc[4]:loc[5]]
+	first, err := strconv.Atoi(firstValue)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid range %s", s[loc[0]:loc[1]])
+	}
+	last, err := strconv.Atoi(lastValue)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid range %s", s[loc[0]:loc[1]])
+	}
+	if first > last || last-first >= maxTemplateURLs {
+		return nil, errors.Errorf("invalid range %s", s[loc[0]:loc[1]])
+	}
+	width := 0
+	if len(firstValue) > 1 && firstValue[0] == '0' {
+		width = len(firstValue)
+	}
+
+	rest, err := expandRanges(s[loc[1]:])
+	if err != nil {
+		return nil, err
+	}
+	if (last-first+1)*len(rest) > maxTemplateURLs {
+		return nil, errors.Errorf("%s expands into more than %d values", s, maxTemplateURLs)
+	}
+	var result []string
+	for i := first; i <= last; i++ {
+		for _, r := range rest {
+			result = append(result, fmt.Sprintf("%s%0*d%s", s[:loc[0]], width, i, r))
+		}
+	}
+	return result, nil
+}
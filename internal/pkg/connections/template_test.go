@@ -0,0 +1,206 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func urlStrings(urls []url.URL) []string {
+	var result []string
+	for i := range urls {
+		result = append(result, urls[i].String())
+	}
+	return result
+}
+
+func TestExpandTemplate(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		template string
+		expected []string
+		err      bool
+	}{
+		{
+			name:     "no ranges",
+			template: "kernel://my-service/nsm-1?app=nsc",
+			expected: []string{"kernel://my-service/nsm-1?app=nsc"},
+		},
+		{
+			name:     "path",
+			template: "kernel://my-service/nsm-{1..3}",
+			expected: []string{"kernel://my-service/nsm-1", "kernel://my-service/nsm-2", "kernel://my-service/nsm-3"},
+		},
+		{
+			name:     "zero padded",
+			template: "memif://lb-service?instance={08..10}",
+			expected: []string{"memif://lb-service?instance=08", "memif://lb-service?instance=09", "memif://lb-service?instance=10"},
+		},
+		{
+			name:     "combinations",
+			template: "memif://lb-service/nsm-{1..2}?zone={1..2}&app=nsc",
+			expected: []string{
+				"memif://lb-service/nsm-1?app=nsc&zone=1",
+				"memif://lb-service/nsm-1?app=nsc&zone=2",
+				"memif://lb-service/nsm-2?app=nsc&zone=1",
+				"memif://lb-service/nsm-2?app=nsc&zone=2",
+			},
+		},
+		{
+			name:     "ranges in a value",
+			template: "memif://lb-service?instance={1..2}-{1..2}",
+			expected: []string{
+				"memif://lb-service?instance=1-1",
+				"memif://lb-service?instance=1-2",
+				"memif://lb-service?instance=2-1",
+				"memif://lb-service?instance=2-2",
+			},
+		},
+		{
+			name:     "single value",
+			template: "memif://lb-service?instance={5..5}",
+			expected: []string{"memif://lb-service?instance=5"},
+		},
+		{
+			name:     "reversed range",
+			template: "memif://lb-service?instance={3..1}",
+			err:      true,
+		},
+		{
+			name:     "too long range",
+			template: "memif://lb-service?instance={1..2000}",
+			err:      true,
+		},
+		{
+			name:     "too many combinations",
+			template: "memif://lb-service/nsm-{1..100}?instance={1..100}",
+			err:      true,
+		},
+		{
+			name:     "too many values",
+			template: "memif://lb-service?instance={1..100}-{1..100}",
+			err:      true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			u, err := url.Parse(tc.template)
+			require.NoError(t, err)
+
+			urls, err := expandTemplate(u)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, urlStrings(urls))
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		urls     []string
+		expected []string
+		err      bool
+	}{
+		{
+			name:     "plain",
+			urls:     []string{"kernel://a/nsm-1", "kernel://b/nsm-2", "kernel://a/nsm-1"},
+			expected: []string{"kernel://a/nsm-1", "kernel://b/nsm-2", "kernel://a/nsm-1"},
+		},
+		{
+			name: "redundancy",
+			urls: []string{"memif://my-service?redundancy=active-standby"},
+			expected: []string{
+				"memif://my-service?redundancy=active-standby&redundancyRole=primary",
+				"memif://my-service?redundancy=active-standby&redundancyRole=standby",
+			},
+		},
+		{
+			name: "replicas",
+			urls: []string{"memif://my-service?replicas=3"},
+			expected: []string{
+				"memif://my-service?replica=1&replicas=3",
+				"memif://my-service?replica=2&replicas=3",
+				"memif://my-service?replica=3&replicas=3",
+			},
+		},
+		{
+			name: "template with replicas",
+			urls: []string{"memif://my-service?zone={1..2}&replicas=2"},
+			expected: []string{
+				"memif://my-service?replica=1&replicas=2&zone=1",
+				"memif://my-service?replica=2&replicas=2&zone=1",
+				"memif://my-service?replica=1&replicas=2&zone=2",
+				"memif://my-service?replica=2&replicas=2&zone=2",
+			},
+		},
+		{
+			name: "unknown redundancy mode",
+			urls: []string{"memif://my-service?redundancy=active-active"},
+			err:  true,
+		},
+		{
+			name: "redundancy with replicas",
+			urls: []string{"memif://my-service?redundancy=active-standby&replicas=2"},
+			err:  true,
+		},
+		{
+			name: "role set by user",
+			urls: []string{"memif://my-service?redundancy=active-standby&redundancyRole=primary"},
+			err:  true,
+		},
+		{
+			name: "zero replicas",
+			urls: []string{"memif://my-service?replicas=0"},
+			err:  true,
+		},
+		{
+			name: "too many replicas",
+			urls: []string{"memif://my-service?replicas=17"},
+			err:  true,
+		},
+		{
+			name: "invalid template",
+			urls: []string{"memif://my-service?zone={2..1}"},
+			err:  true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var urls []url.URL
+			for _, rawURL := range tc.urls {
+				u, err := url.Parse(rawURL)
+				require.NoError(t, err)
+				urls = append(urls, *u)
+			}
+
+			expanded, err := expand(urls)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, urlStrings(expanded))
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// watchInterval is the delay before reopening the broken monitor stream and before checking the deleted connection.
+// The delay of reopening the stream is doubled after every attempt failed without receiving any event up to
+// maxWatchInterval.
+const (
+	watchInterval    = time.Second
+	maxWatchInterval = 30 * time.Second
+)
+
+// watchMode selects when watchStream returns
+type watchMode int
+
+const (
+	// watchDeleted waits for the connection to be deleted
+	watchDeleted watchMode = iota
+	// watchRestarted waits for the connection to be deleted or returns if it's missing in the initial state
+	watchRestarted
+	// checkInitial returns after the initial state
+	checkInitial
+)
+
+// startWatch starts watching the connection c until it is closed
+func (m *Manager) startWatch(c *connection) {
+	watchCtx, cancelWatch := context.WithCancel(m.ctx)
+	c.cancelWatch = cancelWatch
+	go m.watch(watchCtx, c.id)
+}
+
+// watch keeps the monitor stream of the connection open reopening it with backoff, and requests the connection
+// again if it is deleted or missing after the stream restart, e.g. NSMgr restart, and not restored by heal in
+// watchInterval
+func (m *Manager) watch(ctx context.Context, id string) {
+	logger := log.FromContext(ctx).WithField("connection", id)
+
+	restarted := false
+	delay := watchInterval
+	for ctx.Err() == nil {
+		mode := watchDeleted
+		if restarted {
+			mode = watchRestarted
+		}
+		deleted, received, err := m.watchStream(ctx, id, mode)
+		if err != nil && ctx.Err() == nil {
+			logger.Warnf("monitor stream is broken, reopening in %s: %v", delay, err.Error())
+		}
+		restarted = err != nil
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if received {
+			delay = watchInterval
+		} else if delay *= 2; delay > maxWatchInterval {
+			delay = maxWatchInterval
+		}
+		if !deleted {
+			continue
+		}
+		restarted = false
+
+		if deleted, _, err = m.watchStream(ctx, id, checkInitial); err != nil || !deleted {
+			continue
+		}
+		logger.Warnf("connection is deleted, requesting it again")
+		if err = m.Reselect(m.ctx, id); err != nil {
+			logger.Errorf("failed to request deleted connection: %v", err.Error())
+		}
+		return
+	}
+}
+
+// watchStream reads the monitor stream of the connection and returns true when the connection is deleted, or missing
+// in the initial state for watchRestarted and checkInitial modes. The second result is true if any event has been
+// received.
+func (m *Manager) watchStream(ctx context.Context, id string, mode watchMode) (deleted, received bool, err error) {
+	streamCtx, cancelStream := context.WithCancel(ctx)
+	defer cancelStream()
+
+	stream, err := m.monitorClient.MonitorConnections(streamCtx, &networkservice.MonitorScopeSelector{
+		PathSegments: []*networkservice.PathSegment{{Id: id}},
+	})
+	if err != nil {
+		return false, false, errors.Wrap(err, "failed to open monitor stream")
+	}
+	for {
+		event, recvErr := stream.Recv()
+		if recvErr != nil {
+			return false, received, errors.Wrap(recvErr, "failed to receive monitor event")
+		}
+		found := hasConnection(event, id)
+		switch {
+		case mode == checkInitial:
+			return !found, true, nil
+		case mode == watchRestarted && !received && !found:
+			return true, true, nil
+		case event.GetType() == networkservice.ConnectionEventType_DELETE && found:
+			return true, true, nil
+		}
+		received = true
+	}
+}
+
+// hasConnection returns true if the event contains the connection with the NSC path segment id
+func hasConnection(event *networkservice.ConnectionEvent, id string) bool {
+	for _, conn := range event.GetConnections() {
+		segments := conn.GetPath().GetPathSegments()
+		if len(segments) > 0 && segments[0].GetId() == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package connfile provides a client chain element writing the details of every established connection to a JSON
+// file, so the application container can configure itself without parsing the NSC logs
+package connfile
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/memif"
+)
+
+// Route is a route of the connection
+type Route struct {
+	Prefix  string `json:"prefix"`
+	NextHop string `json:"nextHop,omitempty"`
+}
+
+// DNS is a DNS config of the connection
+type DNS struct {
+	Servers       []string `json:"servers,omitempty"`
+	SearchDomains []string `json:"searchDomains,omitempty"`
+}
+
+// Details are the connection details written to the file
+type Details struct {
+	ID             string   `json:"id"`
+	NetworkService string   `json:"networkService"`
+	Endpoint       string   `json:"endpoint,omitempty"`
+	Mechanism      string   `json:"mechanism,omitempty"`
+	IfIndex        *uint32  `json:"ifIndex,omitempty"`
+	MemifSocket    string   `json:"memifSocket,omitempty"`
+	SrcIPs         []string `json:"srcIPs,omitempty"`
+	DstIPs         []string `json:"dstIPs,omitempty"`
+	SrcRoutes      []Route  `json:"srcRoutes,omitempty"`
+	DstRoutes      []Route  `json:"dstRoutes,omitempty"`
+	DNS            []DNS    `json:"dns,omitempty"`
+}
+
+type connFileClient struct {
+	dir string
+}
+
+// NewClient returns a client chain element writing the details of every established connection to <dir>/<id>.json
+// and removing the file on close. It should be placed before the mechanism client to get the VPP interface index and the
+// memif socket filename.
+func NewClient(dir string) networkservice.NetworkServiceClient {
+	return &connFileClient{dir: dir}
+}
+
+func (c *connFileClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	details := newDetails(conn)
+	if swIfIndex, ok := ifindex.Load(ctx, true); ok {
+		index := uint32(swIfIndex)
+		details.IfIndex = &index
+	}
+	if socket, ok := memif.SocketFilename(ctx); ok {
+		details.MemifSocket = socket
+	}
+	if writeErr := c.write(conn.GetId(), details); writeErr != nil {
+		log.FromContext(ctx).Errorf("failed to write connection file: %s", writeErr.Error())
+	}
+	return conn, nil
+}
+
+func (c *connFileClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	if err := os.Remove(c.path(conn.GetId())); err != nil && !os.IsNotExist(err) {
+		log.FromContext(ctx).Errorf("failed to remove connection file: %s", err.Error())
+	}
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
+
+func (c *connFileClient) path(id string) string {
+	return filepath.Join(c.dir, strings.ReplaceAll(id, string(filepath.Separator), "_")+".json")
+}
+
+// write replaces the file with rename, so the readers never see a partially written file
+func (c *connFileClient) write(id string, details *Details) error {
+	data, err := json.MarshalIndent(details, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal connection details")
+	}
+
+	tmp, err := os.CreateTemp(c.dir, ".conn-*")
+	if err != nil {
+		return errors.Wrapf(err, "failed to create temporary file in %s", c.dir)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err = tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		return errors.Wrapf(err, "failed to write %s", tmp.Name())
+	}
+	if err = tmp.Chmod(0o644); err != nil {
+		_ = tmp.Close()
+		return errors.Wrapf(err, "failed to chmod %s", tmp.Name())
+	}
+	if err = tmp.Close(); err != nil {
+		return errors.Wrapf(err, "failed to close %s", tmp.Name())
+	}
+	return errors.Wrapf(os.Rename(tmp.Name(), c.path(id)), "failed to rename %s", tmp.Name())
+}
+
+func newDetails(conn *networkservice.Connection) *Details {
+	ipContext := conn.GetContext().GetIpContext()
+	details := &Details{
+		ID:             conn.GetId(),
+		NetworkService: conn.GetNetworkService(),
+		Endpoint:       conn.GetNetworkServiceEndpointName(),
+		Mechanism:      conn.GetMechanism().GetType(),
+		SrcIPs:         ipContext.GetSrcIpAddrs(),
+		DstIPs:         ipContext.GetDstIpAddrs(),
+		SrcRoutes:      routes(ipContext.GetSrcRoutes()),
+		DstRoutes:      routes(ipContext.GetDstRoutes()),
+	}
+	for _, config := range conn.GetContext().GetDnsContext().GetConfigs() {
+		details.DNS = append(details.DNS, DNS{
+			Servers:       config.GetDnsServerIps(),
+			SearchDomains: config.GetSearchDomains(),
+		})
+	}
+	return details
+}
+
+func routes(routes []*networkservice.Route) []Route {
+	var result []Route
+	for _, route := range routes {
+		result = append(result, Route{
+			Prefix:  route.GetPrefix(),
+			NextHop: route.GetNextHop(),
+		})
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connlog
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+)
+
+type connLogClient struct {
+	hook *Hook
+}
+
+// NewClient returns a client storing the descriptions of the connections for the hook. It should be placed first
+// in the chain to describe the log entries of all the following elements.
+func (h *Hook) NewClient() networkservice.NetworkServiceClient {
+	return &connLogClient{hook: h}
+}
+
+func (c *connLogClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	id := request.GetConnection().GetId()
+	_, loaded := c.hook.conns.LoadOrStore(id, Info{
+		NetworkService: request.GetConnection().GetNetworkService(),
+		Mechanism:      request.GetConnection().GetMechanism().GetType(),
+	})
+
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		if !loaded {
+			c.hook.conns.Delete(id)
+		}
+		return nil, err
+	}
+	c.hook.conns.Store(conn.GetId(), Info{
+		NetworkService: conn.GetNetworkService(),
+		Mechanism:      conn.GetMechanism().GetType(),
+	})
+	return conn, nil
+}
+
+func (c *connLogClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	defer c.hook.conns.Delete(conn.GetId())
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package connlog adds the Network Service and the mechanism of the connection to the log entries of the connection,
+// so the logs of a multi-service NSC can be filtered per connection
+package connlog
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Log entry fields
+const (
+	// IDField is the connection ID field set by the sdk trace loggers
+	IDField = "id"
+	// ConnectionField is the connection ID field set by the NSC loggers
+	ConnectionField = "connection"
+	// NetworkServiceField is the Network Service name field added by the Hook
+	NetworkServiceField = "networkService"
+	// MechanismField is the mechanism type field added by the Hook
+	MechanismField = "mechanism"
+)
+
+// Info is the connection description added to the log entries
+type Info struct {
+	NetworkService string
+	Mechanism      string
+}
+
+// Hook is a logrus hook adding the description of the connections passing through its client to the log entries
+// having the connection ID field. It should be added before the hooks writing the entries.
+type Hook struct {
+	conns sync.Map
+}
+
+// NewHook creates a new Hook
+func NewHook() *Hook {
+	return new(Hook)
+}
+
+// Levels returns all log levels
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire adds the connection description to the entry
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	id, ok := entry.Data[IDField].(string)
+	if !ok {
+		if id, ok = entry.Data[ConnectionField].(string); !ok {
+			return nil
+		}
+	}
+	value, ok := h.conns.Load(id)
+	if !ok {
+		return nil
+	}
+	info := value.(Info)
+	if _, set := entry.Data[NetworkServiceField]; !set && info.NetworkService != "" {
+		entry.Data[NetworkServiceField] = info.NetworkService
+	}
+	if _, set := entry.Data[MechanismField]; !set && info.Mechanism != "" {
+		entry.Data[MechanismField] = info.Mechanism
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package debugbundle collects the VPP and NSC state into a tarball, so it can be debugged without exec-ing into
+// the pod
+package debugbundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/vppcli"
+)
+
+const collectTimeout = time.Second * 30
+
+// Source returns the content of a single bundle file
+type Source func(ctx context.Context) ([]byte, error)
+
+type file struct {
+	name   string
+	source Source
+}
+
+// Collector writes the debug bundles to the directory
+type Collector struct {
+	dir   string
+	files []file
+
+	mu sync.Mutex
+}
+
+// Option is an option pattern for New
+type Option func(c *Collector)
+
+// WithSource adds the file with the name and the content returned by source to the bundle
+func WithSource(name string, source Source) Option {
+	return func(c *Collector) {
+		c.files = append(c.files, file{name: name, source: source})
+	}
+}
+
+// WithJSON adds the file with the name and the JSON encoded value returned by get to the bundle
+func WithJSON(name string, get func() interface{}) Option {
+	return WithSource(name, func(_ context.Context) ([]byte, error) {
+		return json.MarshalIndent(get(), "", "  ")
+	})
+}
+
+// WithVPPCommands adds the outputs of the VPP CLI commands to the bundle, e.g. "show interface" is written to
+// vpp/show-interface.txt
+func WithVPPCommands(vppConn api.Connection, commands ...string) Option {
+	return func(c *Collector) {
+		for _, command := range commands {
+			command := command
+			name := "vpp/" + strings.ReplaceAll(command, " ", "-") + ".txt"
+			WithSource(name, func(ctx context.Context) ([]byte, error) {
+				reply, err := vppcli.Run(ctx, vppConn, command)
+				return []byte(reply), err
+			})(c)
+		}
+	}
+}
+
+// New creates a Collector writing the bundles to dir
+func New(dir string, opts ...Option) *Collector {
+	c := &Collector{
+		dir: dir,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Collect writes a new bundle and returns its path. Files failed to be collected are listed in errors.txt of the
+// bundle.
+func (c *Collector) Collect(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, collectTimeout)
+	defer cancel()
+
+	if err := os.MkdirAll(c.dir, 0o750); err != nil {
+		return "", errors.Wrapf(err, "failed to create debug bundle directory %s", c.dir)
+	}
+	tmp, err := os.CreateTemp(c.dir, ".nsc-debug-*")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create debug bundle in %s", c.dir)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	writeErr := c.write(ctx, tmp)
+	if closeErr := tmp.Close(); writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr != nil {
+		return "", errors.Wrap(writeErr, "failed to write debug bundle")
+	}
+
+	path := filepath.Join(c.dir, fmt.Sprintf("nsc-debug-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z")))
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return "", errors.Wrapf(err, "failed to write debug bundle %s", path)
+	}
+	return path, nil
+}
+
+func (c *Collector) write(ctx context.Context, out io.Writer) error {
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+
+	var failed []string
+	for _, f := range c.files {
+		content, err := f.source(ctx)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", f.name, err.Error()))
+			continue
+		}
+		if err = writeFile(tw, f.name, content, now); err != nil {
+			return err
+		}
+	}
+	if len(failed) > 0 {
+		if err := writeFile(tw, "errors.txt", []byte(strings.Join(failed, "\n")+"\n"), now); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(gz.Close())
+}
+
+func writeFile(tw *tar.Writer, name string, content []byte, modTime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o640,
+		Size:    int64(len(content)),
+		ModTime: modTime,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to write %s", name)
+	}
+	_, err := tw.Write(content)
+	return errors.Wrapf(err, "failed to write %s", name)
+}
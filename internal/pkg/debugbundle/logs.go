@@ -0,0 +1,81 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debugbundle
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogBuffer is a logrus hook keeping the recent log lines for the bundle
+type LogBuffer struct {
+	formatter logrus.Formatter
+
+	mu    sync.Mutex
+	lines [][]byte
+	next  int
+}
+
+// NewLogBuffer creates a LogBuffer keeping up to size recent lines
+func NewLogBuffer(size int) *LogBuffer {
+	return &LogBuffer{
+		formatter: &logrus.TextFormatter{DisableColors: true, FullTimestamp: true},
+		lines:     make([][]byte, 0, size),
+	}
+}
+
+// Levels returns all log levels
+func (b *LogBuffer) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire stores the log entry
+func (b *LogBuffer) Fire(entry *logrus.Entry) error {
+	line, err := b.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if cap(b.lines) == 0 {
+		return nil
+	}
+	if len(b.lines) < cap(b.lines) {
+		b.lines = append(b.lines, line)
+		return nil
+	}
+	b.lines[b.next] = line
+	b.next = (b.next + 1) % len(b.lines)
+	return nil
+}
+
+// Source returns the stored log lines, the oldest first
+func (b *LogBuffer) Source(_ context.Context) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var buf bytes.Buffer
+	for i := range b.lines {
+		buf.Write(b.lines[(b.next+i)%len(b.lines)])
+	}
+	return buf.Bytes(), nil
+}
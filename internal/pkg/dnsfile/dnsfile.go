@@ -0,0 +1,199 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dnsfile provides a client chain element writing the DNS configs of the connections to a resolv.conf or
+// a Corefile, so the applications can resolve the names provided by the NSEs
+package dnsfile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// Format is a format of the written DNS config file
+type Format string
+
+const (
+	// ResolvConf - resolv.conf with the nameserver and search lines
+	ResolvConf Format = "resolvconf"
+	// Corefile - CoreDNS config forwarding the search domains of every DNS config to its servers
+	Corefile Format = "corefile"
+)
+
+// ParseFormat parses the DNS config file format
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case ResolvConf, Corefile:
+		return f, nil
+	default:
+		return "", errors.Errorf("unknown DNS config file format: %s", s)
+	}
+}
+
+type dnsFileClient struct {
+	path   string
+	format Format
+
+	mu      sync.Mutex
+	configs map[string][]*networkservice.DNSConfig
+}
+
+// NewClient returns a client chain element writing the DNS configs of all established connections to path. The same
+// element should be used in all the client chains, as it keeps the configs of the connections requested through it.
+func NewClient(path string, format Format) networkservice.NetworkServiceClient {
+	return &dnsFileClient{
+		path:    path,
+		format:  format,
+		configs: make(map[string][]*networkservice.DNSConfig),
+	}
+}
+
+func (c *dnsFileClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.update(ctx, conn.GetId(), conn.GetContext().GetDnsContext().GetConfigs())
+	return conn, nil
+}
+
+func (c *dnsFileClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	c.update(ctx, conn.GetId(), nil)
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
+
+func (c *dnsFileClient) update(ctx context.Context, id string, configs []*networkservice.DNSConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, known := c.configs[id]
+	if len(configs) == 0 {
+		if !known {
+			return
+		}
+		delete(c.configs, id)
+	} else {
+		c.configs[id] = configs
+	}
+
+	var content string
+	if c.format == Corefile {
+		content = c.corefile()
+	} else {
+		content = c.resolvConf()
+	}
+	// The file is rewritten in place, as it may be a bind mount which can't be replaced with rename
+	if err := os.WriteFile(c.path, []byte(content), 0o644); err != nil {
+		log.FromContext(ctx).Errorf("failed to write DNS config %s: %s", c.path, err.Error())
+	}
+}
+
+// sorted returns the DNS configs ordered by the connection ID, so the file content is stable
+func (c *dnsFileClient) sorted() []*networkservice.DNSConfig {
+	var ids []string
+	for id := range c.configs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var result []*networkservice.DNSConfig
+	for _, id := range ids {
+		result = append(result, c.configs[id]...)
+	}
+	return result
+}
+
+func (c *dnsFileClient) resolvConf() string {
+	var servers, searches []string
+	seen := make(map[string]bool)
+	for _, config := range c.sorted() {
+		for _, server := range config.GetDnsServerIps() {
+			if !seen["nameserver "+server] {
+				seen["nameserver "+server] = true
+				servers = append(servers, server)
+			}
+		}
+		for _, search := range config.GetSearchDomains() {
+			if !seen["search "+search] {
+				seen["search "+search] = true
+				searches = append(searches, search)
+			}
+		}
+	}
+
+	var b strings.Builder
+	for _, server := range servers {
+		_, _ = fmt.Fprintf(&b, "nameserver %s\n", server)
+	}
+	if len(searches) > 0 {
+		_, _ = fmt.Fprintf(&b, "search %s\n", strings.Join(searches, " "))
+	}
+	return b.String()
+}
+
+func (c *dnsFileClient) corefile() string {
+	// Configs with the same zones are merged, as CoreDNS doesn't allow repeated server blocks
+	var zones []string
+	servers := make(map[string][]string)
+	for _, config := range c.sorted() {
+		if len(config.GetDnsServerIps()) == 0 {
+			continue
+		}
+		key := strings.Join(config.GetSearchDomains(), " ")
+		if key == "" {
+			key = "."
+		}
+		if _, ok := servers[key]; !ok {
+			zones = append(zones, key)
+		}
+		for _, server := range config.GetDnsServerIps() {
+			if !contains(servers[key], server) {
+				servers[key] = append(servers[key], server)
+			}
+		}
+	}
+
+	var b strings.Builder
+	for _, key := range zones {
+		_, _ = fmt.Fprintf(&b, "%s {\n", key)
+		_, _ = fmt.Fprintf(&b, "    forward . %s\n", strings.Join(servers[key], " "))
+		_, _ = fmt.Fprintf(&b, "    reload\n")
+		_, _ = fmt.Fprintf(&b, "}\n")
+	}
+	return b.String()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ecmp balances the traffic over the replica connections of a Network Service with the ECMP routes in VPP
+package ecmp
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/govpp/binapi/fib_types"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/ip"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/vrf"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/types"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/postpone"
+)
+
+type contextKey struct{}
+
+// WithGroup returns a context requesting the connection as a replica of the group
+func WithGroup(ctx context.Context, group string) context.Context {
+	return context.WithValue(ctx, contextKey{}, group)
+}
+
+// routeKey is a route prefix in a VPP table
+type routeKey struct {
+	tableID uint32
+	prefix  string
+}
+
+// replica is a connection of a group and the next hops of its IP context routes
+type replica struct {
+	id        string
+	swIfIndex interface_types.InterfaceIndex
+	routes    map[routeKey]net.IP
+	prefixes  map[routeKey]*net.IPNet
+}
+
+// Balancer keeps the replicas of the groups. The IP context routes with the same prefix of the replicas of a group
+// are installed as a single route with a path via every replica.
+type Balancer struct {
+	vppConn api.Connection
+
+	mu       sync.Mutex
+	groups   map[string]map[string]*replica
+	replicas map[string]string
+}
+
+// New creates a new Balancer
+func New(vppConn api.Connection) *Balancer {
+	return &Balancer{
+		vppConn:  vppConn,
+		groups:   make(map[string]map[string]*replica),
+		replicas: make(map[string]string),
+	}
+}
+
+// NewClient returns a client chain element installing the ECMP routes for the connections requested with WithGroup.
+// It should be placed before the connection context client, as the routes installed by it replace each other.
+func (b *Balancer) NewClient() networkservice.NetworkServiceClient {
+	return &ecmpClient{balancer: b}
+}
+
+type ecmpClient struct {
+	balancer *Balancer
+}
+
+func (c *ecmpClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	postponeCtxFunc := postpone.ContextWithValues(ctx)
+
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	group, _ := ctx.Value(contextKey{}).(string)
+	swIfIndex, ok := ifindex.Load(ctx, true)
+	if group == "" || !ok {
+		return conn, nil
+	}
+	if err := c.balancer.join(ctx, group, newReplica(ctx, conn, swIfIndex)); err != nil {
+		closeCtx, cancelClose := postponeCtxFunc()
+		defer cancelClose()
+
+		if _, closeErr := c.Close(closeCtx, conn, opts...); closeErr != nil {
+			err = errors.Wrapf(err, "connection closed with error: %s", closeErr.Error())
+		}
+
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (c *ecmpClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	// The connection context client deletes the routes of the replica, so the ones of the rest are installed again
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+	c.balancer.leave(ctx, conn.GetId())
+	return rv, err
+}
+
+// newReplica returns the replica with the routes installed by the connection context client
+func newReplica(ctx context.Context, conn *networkservice.Connection, swIfIndex interface_types.InterfaceIndex) *replica {
+	r := &replica{
+		id:        conn.GetId(),
+		swIfIndex: swIfIndex,
+		routes:    make(map[routeKey]net.IP),
+		prefixes:  make(map[routeKey]*net.IPNet),
+	}
+	routes := conn.GetContext().GetIpContext().GetDstIPRoutes()
+	routes = append(routes, conn.GetContext().GetIpContext().GetSrcRoutesWithExplicitNextHop()...)
+	for _, route := range routes {
+		prefix := route.GetPrefixIPNet()
+		if prefix == nil {
+			continue
+		}
+		tableID, _ := vrf.Load(ctx, true, prefix.IP.To4() == nil)
+		key := routeKey{tableID: tableID, prefix: prefix.String()}
+		r.routes[key] = route.GetNextHopIP()
+		r.prefixes[key] = prefix
+	}
+	return r
+}
+
+// join adds or updates the replica of the group and installs the routes it shares with the other replicas
+func (b *Balancer) join(ctx context.Context, group string, r *replica) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	replicas, ok := b.groups[group]
+	if !ok {
+		replicas = make(map[string]*replica)
+		b.groups[group] = replicas
+	}
+	prev := replicas[r.id]
+	replicas[r.id] = r
+	b.replicas[r.id] = group
+
+	for key, prefix := range r.prefixes {
+		if err := b.sync(ctx, replicas, key, prefix, true); err != nil {
+			return err
+		}
+	}
+	// The routes of the previous interface are moved to the new one on reselect
+	if prev != nil {
+		for key, prefix := range prev.prefixes {
+			if _, ok := r.prefixes[key]; !ok {
+				b.syncOrWarn(ctx, replicas, key, prefix)
+			}
+		}
+	}
+	return nil
+}
+
+// leave removes the replica of the group and installs the routes of the rest
+func (b *Balancer) leave(ctx context.Context, id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	group, ok := b.replicas[id]
+	if !ok {
+		return
+	}
+	delete(b.replicas, id)
+	replicas := b.groups[group]
+	r := replicas[id]
+	delete(replicas, id)
+	if len(replicas) == 0 {
+		delete(b.groups, group)
+		return
+	}
+	for key, prefix := range r.prefixes {
+		b.syncOrWarn(ctx, replicas, key, prefix)
+	}
+}
+
+func (b *Balancer) syncOrWarn(ctx context.Context, replicas map[string]*replica, key routeKey, prefix *net.IPNet) {
+	if err := b.sync(ctx, replicas, key, prefix, false); err != nil {
+		log.FromContext(ctx).Warnf("failed to install route %s: %s", key.prefix, err.Error())
+	}
+}
+
+// sync installs the route with a path via every replica having it. The single path route is installed by the
+// connection context client itself, so it is skipped if onlyShared is set.
+func (b *Balancer) sync(ctx context.Context, replicas map[string]*replica, key routeKey, prefix *net.IPNet, onlyShared bool) error {
+	var ids []string
+	for id, r := range replicas {
+		if _, ok := r.routes[key]; ok {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 || (onlyShared && len(ids) == 1) {
+		return nil
+	}
+	// The paths are sorted, so the flows keep their paths while the set of the replicas is the same
+	sort.Strings(ids)
+
+	isV6 := prefix.IP.To4() == nil
+	var paths []fib_types.FibPath
+	for _, id := range ids {
+		r := replicas[id]
+		path := fib_types.FibPath{
+			SwIfIndex: uint32(r.swIfIndex),
+			Weight:    1,
+			Type:      fib_types.FIB_API_PATH_TYPE_NORMAL,
+			Flags:     fib_types.FIB_API_PATH_FLAG_NONE,
+			Proto:     types.IsV6toFibProto(isV6),
+		}
+		if nh := r.routes[key]; nh != nil {
+			path.Nh.Address = types.ToVppAddress(nh).Un
+		}
+		paths = append(paths, path)
+	}
+
+	now := time.Now()
+	if _, err := ip.NewServiceClient(b.vppConn).IPRouteAddDel(ctx, &ip.IPRouteAddDel{
+		IsAdd: true,
+		Route: ip.IPRoute{
+			TableID: key.tableID,
+			Prefix:  types.ToVppPrefix(prefix),
+			NPaths:  uint8(len(paths)),
+			Paths:   paths,
+		},
+	}); err != nil {
+		return errors.Wrap(err, "vppapi IPRouteAddDel returned error")
+	}
+	log.FromContext(ctx).
+		WithField("tableID", key.tableID).
+		WithField("prefix", key.prefix).
+		WithField("replicas", ids).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "IPRouteAddDel").Debug("completed")
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package failover
+
+import (
+	"context"
+	"net/url"
+	"sync"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// ClientFunc creates a Network Service client connected to the NSMgr with URL u
+type ClientFunc func(u *url.URL) networkservice.NetworkServiceClient
+
+type failoverClient struct {
+	urls      *URLs
+	newClient ClientFunc
+
+	mu      sync.Mutex
+	clients map[string]networkservice.NetworkServiceClient
+	conns   map[string]*url.URL
+}
+
+// NewClient returns a Network Service client requesting the connections with the client for the current NSMgr
+// URL. Connections are closed with the client they are established with. If the connection is requested again
+// after the failover, it is closed on the previous NSMgr first.
+func NewClient(urls *URLs, newClient ClientFunc) networkservice.NetworkServiceClient {
+	return &failoverClient{
+		urls:      urls,
+		newClient: newClient,
+		clients:   make(map[string]networkservice.NetworkServiceClient),
+		conns:     make(map[string]*url.URL),
+	}
+}
+
+func (c *failoverClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	id := request.GetConnection().GetId()
+	u := c.urls.Current()
+
+	c.mu.Lock()
+	prev, ok := c.conns[id]
+	c.mu.Unlock()
+	if ok && prev.String() != u.String() {
+		log.FromContext(ctx).Infof("closing connection %s on the previous NSMgr %s", id, prev.String())
+		if _, err := c.client(prev).Close(ctx, request.GetConnection().Clone(), opts...); err != nil {
+			log.FromContext(ctx).Warnf("failed to close connection %s on %s: %s", id, prev.String(), err.Error())
+		}
+		c.forget(id)
+	}
+
+	conn, err := c.client(u).Request(ctx, request, opts...)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.urls.Failed(u)
+		}
+		return nil, err
+	}
+	c.urls.Succeeded(u)
+
+	c.mu.Lock()
+	c.conns[id] = u
+	c.mu.Unlock()
+	return conn, nil
+}
+
+func (c *failoverClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	c.mu.Lock()
+	u, ok := c.conns[conn.GetId()]
+	c.mu.Unlock()
+	if !ok {
+		u = c.urls.Current()
+	}
+	defer c.forget(conn.GetId())
+	return c.client(u).Close(ctx, conn, opts...)
+}
+
+func (c *failoverClient) client(u *url.URL) networkservice.NetworkServiceClient {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if client, ok := c.clients[u.String()]; ok {
+		return client
+	}
+	client := c.newClient(u)
+	c.clients[u.String()] = client
+	return client
+}
+
+func (c *failoverClient) forget(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.conns, id)
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package failover
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// Conns are the gRPC connections to the NSMgr URLs shared by the Network Service and the monitor clients, so every
+// NSMgr is connected once
+type Conns struct {
+	ctx  context.Context
+	dial DialFunc
+
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+// NewConns returns the shared gRPC connections dialed on the first use and closed when ctx is done.
+//   - ctx - context of the connections lifetime
+//   - dial - dials NSMgr, shouldn't block
+//   - conns - already dialed gRPC connections by URL
+func NewConns(ctx context.Context, dial DialFunc, conns map[string]*grpc.ClientConn) *Conns {
+	c := &Conns{
+		ctx:   ctx,
+		dial:  dial,
+		conns: make(map[string]*grpc.ClientConn),
+	}
+	for u, cc := range conns {
+		c.conns[u] = cc
+	}
+	go func() {
+		<-ctx.Done()
+		c.Reset()
+	}()
+	return c
+}
+
+// Get returns the gRPC connection to u, dialing it if needed
+func (c *Conns) Get(u *url.URL) (*grpc.ClientConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cc, ok := c.conns[u.String()]; ok {
+		return cc, nil
+	}
+	if c.ctx.Err() != nil {
+		return nil, errors.Wrapf(c.ctx.Err(), "failed to dial %s", u.String())
+	}
+	cc, err := c.dial(c.ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	c.conns[u.String()] = cc
+	return cc, nil
+}
+
+// Reset closes the gRPC connections breaking the open streams, so the next calls are made on the new connections,
+// e.g. with the rotated TLS certificate
+func (c *Conns) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for u, cc := range c.conns {
+		_ = cc.Close()
+		delete(c.conns, u)
+	}
+}
+
+// ClientConn returns the gRPC connection to u making the calls on the current shared connection. The calls wait up
+// to dialTimeout for the connection to become ready.
+func (c *Conns) ClientConn(u *url.URL, dialTimeout time.Duration) grpc.ClientConnInterface {
+	return &sharedConn{
+		conns:       c,
+		url:         u,
+		dialTimeout: dialTimeout,
+	}
+}
+
+type sharedConn struct {
+	conns       *Conns
+	url         *url.URL
+	dialTimeout time.Duration
+}
+
+func (s *sharedConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	cc, err := s.ready(ctx)
+	if err != nil {
+		return err
+	}
+	return cc.Invoke(ctx, method, args, reply, opts...)
+}
+
+func (s *sharedConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	cc, err := s.ready(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cc.NewStream(ctx, desc, method, opts...)
+}
+
+// ready returns the shared connection waiting up to dialTimeout for it to become ready
+func (s *sharedConn) ready(ctx context.Context) (*grpc.ClientConn, error) {
+	cc, err := s.conns.Get(s.url)
+	if err != nil {
+		return nil, err
+	}
+	if s.dialTimeout <= 0 {
+		return cc, nil
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, s.dialTimeout)
+	defer cancel()
+
+	for state := cc.GetState(); state != connectivity.Ready; state = cc.GetState() {
+		if state == connectivity.Shutdown {
+			return nil, errors.Errorf("connection to %s is closed", s.url.String())
+		}
+		cc.Connect()
+		if !cc.WaitForStateChange(dialCtx, state) {
+			return nil, errors.Errorf("failed to connect to %s in %s", s.url.String(), s.dialTimeout)
+		}
+	}
+	return cc, nil
+}
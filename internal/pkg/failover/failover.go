@@ -0,0 +1,81 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package failover provides NSMgr clients switching to the next NSMgr URL after repeated failures
+package failover
+
+import (
+	"net/url"
+	"sync"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// URLs is a list of NSMgr URLs with the current one used by the NSC
+type URLs struct {
+	urls        []url.URL
+	maxFailures int
+
+	mu       sync.Mutex
+	current  int
+	failures int
+}
+
+// New creates a new URLs list, the first URL is used until it fails maxFailures times in a row
+func New(urls []url.URL, maxFailures int) *URLs {
+	if maxFailures < 1 {
+		maxFailures = 1
+	}
+	return &URLs{
+		urls:        urls,
+		maxFailures: maxFailures,
+	}
+}
+
+// Current returns the NSMgr URL currently used
+func (u *URLs) Current() *url.URL {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return &u.urls[u.current]
+}
+
+// Failed records the failure of the NSMgr with URL failed and switches to the next URL if the current one has
+// failed maxFailures times in a row. Failures of a URL other than the current one are ignored.
+func (u *URLs) Failed(failed *url.URL) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if failed.String() != u.urls[u.current].String() {
+		return
+	}
+	if u.failures++; u.failures < u.maxFailures || len(u.urls) == 1 {
+		return
+	}
+	u.current = (u.current + 1) % len(u.urls)
+	u.failures = 0
+	log.L().Warnf("NSMgr %s has failed %d times, failing over to %s", failed.String(), u.maxFailures, u.urls[u.current].String())
+}
+
+// Succeeded resets the failures count of the current URL if it is succeeded
+func (u *URLs) Succeeded(succeeded *url.URL) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if succeeded.String() == u.urls[u.current].String() {
+		u.failures = 0
+	}
+}
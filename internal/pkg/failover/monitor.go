@@ -0,0 +1,62 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package failover
+
+import (
+	"context"
+	"net/url"
+
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+)
+
+// DialFunc dials the NSMgr with URL u
+type DialFunc func(ctx context.Context, u *url.URL) (*grpc.ClientConn, error)
+
+// MonitorClient is a MonitorConnection client opening the streams to the current NSMgr URL
+type MonitorClient struct {
+	urls  *URLs
+	conns *Conns
+}
+
+// NewMonitorClient returns a MonitorConnection client opening the streams to the current NSMgr URL on the shared
+// gRPC connections
+func NewMonitorClient(urls *URLs, conns *Conns) *MonitorClient {
+	return &MonitorClient{
+		urls:  urls,
+		conns: conns,
+	}
+}
+
+// MonitorConnections opens the stream to the current NSMgr URL
+func (c *MonitorClient) MonitorConnections(ctx context.Context, in *networkservice.MonitorScopeSelector, opts ...grpc.CallOption) (networkservice.MonitorConnection_MonitorConnectionsClient, error) {
+	u := c.urls.Current()
+	cc, err := c.conns.Get(u)
+	if err == nil {
+		var stream networkservice.MonitorConnection_MonitorConnectionsClient
+		stream, err = networkservice.NewMonitorConnectionClient(cc).MonitorConnections(ctx, in, opts...)
+		if err == nil {
+			c.urls.Succeeded(u)
+			return stream, nil
+		}
+	}
+	if ctx.Err() == nil {
+		c.urls.Failed(u)
+	}
+	return nil, err
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakevpp
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/pkg/errors"
+)
+
+// Notify delivers the event to the channels subscribed to its message name, the full channels are skipped the same
+// way govpp does
+func (c *Connection) Notify(event api.Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, sub := range c.subscriptions {
+		if sub.name != event.GetMessageName() {
+			continue
+		}
+		select {
+		case sub.notifCh <- event:
+		default:
+		}
+	}
+}
+
+func (c *Connection) unsubscribe(sub *subscription) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := range c.subscriptions {
+		if c.subscriptions[i] == sub {
+			c.subscriptions = append(c.subscriptions[:i], c.subscriptions[i+1:]...)
+			return
+		}
+	}
+}
+
+type channel struct {
+	conn *Connection
+}
+
+func (ch *channel) SendRequest(msg api.Message) api.RequestCtx {
+	return &requestCtx{conn: ch.conn, req: msg}
+}
+
+func (ch *channel) SendMultiRequest(msg api.Message) api.MultiRequestCtx {
+	replies, err := ch.conn.handle(msg)
+	return &multiRequestCtx{replies: replies, err: err}
+}
+
+func (ch *channel) SubscribeNotification(notifCh chan api.Message, event api.Message) (api.SubscriptionCtx, error) {
+	sub := &subscription{
+		conn:    ch.conn,
+		name:    event.GetMessageName(),
+		notifCh: notifCh,
+	}
+	ch.conn.mu.Lock()
+	defer ch.conn.mu.Unlock()
+
+	ch.conn.subscriptions = append(ch.conn.subscriptions, sub)
+	return sub, nil
+}
+
+func (ch *channel) SetReplyTimeout(_ time.Duration) {}
+
+func (ch *channel) CheckCompatiblity(_ ...api.Message) error {
+	return nil
+}
+
+func (ch *channel) Close() {}
+
+type requestCtx struct {
+	conn *Connection
+	req  api.Message
+}
+
+func (r *requestCtx) ReceiveReply(msg api.Message) error {
+	return r.conn.Invoke(context.Background(), r.req, msg)
+}
+
+type multiRequestCtx struct {
+	replies []api.Message
+	err     error
+}
+
+func (r *multiRequestCtx) ReceiveReply(msg api.Message) (bool, error) {
+	if r.err != nil {
+		return false, r.err
+	}
+	if len(r.replies) == 0 {
+		return true, nil
+	}
+	value := reflect.ValueOf(msg).Elem()
+	replyValue := reflect.ValueOf(r.replies[0]).Elem()
+	if value.Type() != replyValue.Type() {
+		return false, errors.Errorf("dump returned %T instead of %T", r.replies[0], msg)
+	}
+	value.Set(replyValue)
+	r.replies = r.replies[1:]
+	return false, nil
+}
+
+type subscription struct {
+	conn    *Connection
+	name    string
+	notifCh chan api.Message
+}
+
+func (s *subscription) Unsubscribe() error {
+	s.conn.unsubscribe(s)
+	return nil
+}
@@ -0,0 +1,251 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fakevpp provides an in-memory VPP API connection answering the requests with the successful replies, so
+// the client chain can be exercised without VPP
+package fakevpp
+
+import (
+	"context"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/edwarnicke/vpphelper"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/govpp/binapi/memclnt"
+)
+
+// firstSwIfIndex is the interface index returned by the first request creating an interface, the lower ones are
+// left for the interfaces VPP creates itself
+const firstSwIfIndex = 1
+
+// HandlerFunc returns the replies to the request. The dump requests are answered with all the replies followed by
+// the end of the dump, the other ones with the first reply.
+type HandlerFunc func(req api.Message) ([]api.Message, error)
+
+// Connection is a fake VPP API connection. Unless a handler is set for the request, it is answered with the zero
+// reply having a new sw_if_index if the reply has one, and the dumps are answered with no details.
+type Connection struct {
+	mu            sync.Mutex
+	handlers      map[string]HandlerFunc
+	messages      []string
+	nextIfIndex   uint32
+	subscriptions []*subscription
+	onRestart     func(ctx context.Context)
+}
+
+// New creates a new fake VPP API connection
+func New() *Connection {
+	return &Connection{
+		handlers:    make(map[string]HandlerFunc),
+		nextIfIndex: firstSwIfIndex,
+	}
+}
+
+// Handle sets the handler for the requests with the message name, e.g. sw_interface_dump
+func (c *Connection) Handle(name string, handler HandlerFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.handlers[name] = handler
+}
+
+// Fail makes the requests with the message name fail with err
+func (c *Connection) Fail(name string, err error) {
+	c.Handle(name, func(api.Message) ([]api.Message, error) {
+		return nil, err
+	})
+}
+
+// Messages returns the names of the requests received so far
+func (c *Connection) Messages() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.messages...)
+}
+
+// Count returns the number of the received requests with the message name
+func (c *Connection) Count(name string) int {
+	var count int
+	for _, msg := range c.Messages() {
+		if msg == name {
+			count++
+		}
+	}
+	return count
+}
+
+// Invoke answers the request with the reply
+func (c *Connection) Invoke(_ context.Context, req, reply api.Message) error {
+	replies, err := c.handle(req)
+	if err != nil {
+		return err
+	}
+	if len(replies) == 0 {
+		c.setSwIfIndex(reply)
+		return nil
+	}
+	value := reflect.ValueOf(reply).Elem()
+	replyValue := reflect.ValueOf(replies[0]).Elem()
+	if value.Type() != replyValue.Type() {
+		return errors.Errorf("handler of %s returned %T instead of %T", req.GetMessageName(), replies[0], reply)
+	}
+	value.Set(replyValue)
+	return nil
+}
+
+// NewStream returns a stream answering the sent requests
+func (c *Connection) NewStream(ctx context.Context, _ ...api.StreamOption) (api.Stream, error) {
+	return &stream{ctx: ctx, conn: c}, nil
+}
+
+// NewAPIChannel returns a channel answering the requests the same way as Invoke and delivering the notifications
+// sent with Notify
+func (c *Connection) NewAPIChannel() (api.Channel, error) {
+	return &channel{conn: c}, nil
+}
+
+// NewAPIChannelBuffered returns the same channel as NewAPIChannel, the buffer sizes are ignored
+func (c *Connection) NewAPIChannelBuffered(_, _ int) (api.Channel, error) {
+	return c.NewAPIChannel()
+}
+
+// OnRestart sets the handler called by Restart, the same way the VPP supervisor calls it after VPP has been
+// restarted
+func (c *Connection) OnRestart(onRestart func(ctx context.Context)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.onRestart = onRestart
+}
+
+// Restart simulates the VPP restart: the interface indexes start over as the interfaces are lost and the handler
+// set by OnRestart is called with ctx
+func (c *Connection) Restart(ctx context.Context) {
+	c.mu.Lock()
+	c.nextIfIndex = firstSwIfIndex
+	onRestart := c.onRestart
+	c.mu.Unlock()
+
+	if onRestart != nil {
+		onRestart(ctx)
+	}
+}
+
+// handle records the request and returns the replies of its handler, nil if there is none
+func (c *Connection) handle(req api.Message) ([]api.Message, error) {
+	c.mu.Lock()
+	c.messages = append(c.messages, req.GetMessageName())
+	handler := c.handlers[req.GetMessageName()]
+	c.mu.Unlock()
+
+	if handler == nil {
+		return nil, nil
+	}
+	return handler(req)
+}
+
+// setSwIfIndex sets a new interface index to the reply if it has the SwIfIndex field
+func (c *Connection) setSwIfIndex(reply api.Message) {
+	field := reflect.ValueOf(reply).Elem().FieldByName("SwIfIndex")
+	if !field.IsValid() || !field.CanSet() || field.Kind() != reflect.Uint32 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	field.SetUint(uint64(c.nextIfIndex))
+	c.nextIfIndex++
+}
+
+// newReply returns the zero reply to the request from the same binary API
+func newReply(req api.Message) (api.Message, error) {
+	name := req.GetMessageName() + "_reply"
+	pkgPath := path.Dir(reflect.TypeOf(req).Elem().PkgPath())
+	for _, msg := range api.GetRegisteredMessages()[pkgPath] {
+		if msg.GetMessageName() == name && reflect.TypeOf(msg).Elem().PkgPath() == reflect.TypeOf(req).Elem().PkgPath() {
+			return reflect.New(reflect.TypeOf(msg).Elem()).Interface().(api.Message), nil
+		}
+	}
+	return nil, errors.Errorf("no reply message for %s", req.GetMessageName())
+}
+
+type stream struct {
+	ctx     context.Context
+	conn    *Connection
+	replies []api.Message
+	err     error
+	closed  bool
+}
+
+func (s *stream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *stream) SendMsg(msg api.Message) error {
+	if s.closed {
+		return errors.New("stream is closed")
+	}
+	if _, ok := msg.(*memclnt.ControlPing); ok {
+		s.replies = append(s.replies, &memclnt.ControlPingReply{})
+		return nil
+	}
+	replies, err := s.conn.handle(msg)
+	switch {
+	case err != nil:
+		s.err = err
+	case strings.HasSuffix(msg.GetMessageName(), "_dump"):
+		s.replies = append(s.replies, replies...)
+	case len(replies) > 0:
+		s.replies = append(s.replies, replies[0])
+	default:
+		reply, replyErr := newReply(msg)
+		if replyErr != nil {
+			return replyErr
+		}
+		s.conn.setSwIfIndex(reply)
+		s.replies = append(s.replies, reply)
+	}
+	return nil
+}
+
+func (s *stream) RecvMsg() (api.Message, error) {
+	if s.err != nil {
+		err := s.err
+		s.err = nil
+		s.replies = nil
+		return nil, err
+	}
+	if len(s.replies) == 0 {
+		<-s.ctx.Done()
+		return nil, s.ctx.Err()
+	}
+	msg := s.replies[0]
+	s.replies = s.replies[1:]
+	return msg, nil
+}
+
+func (s *stream) Close() error {
+	s.closed = true
+	return nil
+}
+
+var _ vpphelper.Connection = &Connection{}
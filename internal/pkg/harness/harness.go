@@ -0,0 +1,203 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration && linux
+// +build integration,linux
+
+// Package harness runs the NSC client chain against an in-memory NSM sandbox of the sdk and a fake VPP, so the
+// request, recovery and heal behavior can be validated without Kubernetes. It is used by the tests built with the
+// integration tag.
+package harness
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"testing"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	memifmech "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+	registryapi "github.com/networkservicemesh/api/pkg/api/registry"
+	interfaces "github.com/networkservicemesh/govpp/binapi/interface"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/chains/client"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/chains/nsmgr"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/recvfd"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/null"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/ipam/point2pointipam"
+	"github.com/networkservicemesh/sdk/pkg/tools/grpcutils"
+	"github.com/networkservicemesh/sdk/pkg/tools/sandbox"
+
+	nscclient "github.com/networkservicemesh/cmd-nsc-vpp/internal/client"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/config"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/attach"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/connlog"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/failover"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/fakevpp"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/metrics"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/redundancy"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/registry"
+)
+
+// endpointPrefix is the prefix the addresses of the sandbox connections are allocated from
+const endpointPrefix = "172.16.0.0/24"
+
+// Harness is a single node NSM sandbox with an endpoint of the network service and a fake VPP for the NSC
+type Harness struct {
+	// Domain is the sandbox with NSMgr, forwarder and registry
+	Domain *sandbox.Domain
+	// Endpoint is the NSE of the NetworkService, it can be restarted or canceled to exercise heal
+	Endpoint *sandbox.EndpointEntry
+	// VPP is the fake VPP API connection for the chain elements of the NSC
+	VPP *fakevpp.Connection
+	// NetworkService is the name of the network service provided by the Endpoint
+	NetworkService string
+	// Conns are the gRPC connections of the NSC to the sandbox NSMgr
+	Conns *failover.Conns
+
+	t *testing.T
+}
+
+// New starts the sandbox with the endpoint of the networkService. The endpoint allocates the point to point
+// addresses, endpointServers are appended to its chain. The sandbox is served on the unix sockets, so the NSC can pass
+// its network namespace file to the forwarder selecting the memif mechanism. VPP reports all the interfaces up.
+func New(ctx context.Context, t *testing.T, networkService string, endpointServers ...networkservice.NetworkServiceServer) *Harness {
+	domain := sandbox.NewBuilder(ctx, t).
+		UseUnixSockets().
+		SetNodeSetup(func(ctx context.Context, node *sandbox.Node, _ int) {
+			node.NewNSMgr(ctx, sandbox.UniqueName("nsmgr"), nil, sandbox.GenerateTestToken, nsmgr.NewServer)
+			node.NewForwarder(ctx, &registryapi.NetworkServiceEndpoint{
+				Name:                sandbox.UniqueName("forwarder"),
+				NetworkServiceNames: []string{"forwarder"},
+				NetworkServiceLabels: map[string]*registryapi.NetworkServiceLabels{
+					"forwarder": {Labels: map[string]string{"p2p": "true"}},
+				},
+			}, sandbox.GenerateTestToken, sandbox.WithForwarderAdditionalFunctionalityServer(
+				recvfd.NewServer(),
+				mechanisms.NewServer(map[string]networkservice.NetworkServiceServer{
+					memifmech.MECHANISM: null.NewServer(),
+				}),
+			))
+		}).
+		Build()
+
+	_, err := domain.NewNSRegistryClient(ctx, sandbox.GenerateTestToken).Register(ctx, &registryapi.NetworkService{
+		Name: networkService,
+	})
+	require.NoError(t, err)
+
+	_, prefix, err := net.ParseCIDR(endpointPrefix)
+	require.NoError(t, err)
+	nse := &registryapi.NetworkServiceEndpoint{
+		Name:                sandbox.UniqueName("nse"),
+		NetworkServiceNames: []string{networkService},
+	}
+	endpoint := domain.Nodes[0].NewEndpoint(ctx, nse, sandbox.GenerateTestToken,
+		append([]networkservice.NetworkServiceServer{point2pointipam.NewServer(prefix)}, endpointServers...)...)
+
+	vppConn := fakevpp.New()
+	vppConn.Handle((&interfaces.SwInterfaceDump{}).GetMessageName(), func(req api.Message) ([]api.Message, error) {
+		return []api.Message{&interfaces.SwInterfaceDetails{
+			SwIfIndex: req.(*interfaces.SwInterfaceDump).SwIfIndex,
+			Flags:     interface_types.IF_STATUS_API_FLAG_ADMIN_UP | interface_types.IF_STATUS_API_FLAG_LINK_UP,
+		}}, nil
+	})
+	dialOptions := sandbox.DialOptions(sandbox.WithTokenGenerator(sandbox.GenerateTestToken))
+
+	return &Harness{
+		Domain:         domain,
+		Endpoint:       endpoint,
+		VPP:            vppConn,
+		NetworkService: networkService,
+		Conns: failover.NewConns(ctx, func(ctx context.Context, u *url.URL) (*grpc.ClientConn, error) {
+			return grpc.DialContext(ctx, grpcutils.URLToTarget(u), dialOptions...)
+		}, nil),
+		t: t,
+	}
+}
+
+// NSMgrURL returns the URL of the sandbox NSMgr
+func (h *Harness) NSMgrURL() *url.URL {
+	return sandbox.CloneURL(h.Domain.Nodes[0].NSMgr.URL)
+}
+
+// NewClient returns the NSC client dialing the sandbox NSMgr. The chain elements of the NSC under test are passed
+// with client.WithAdditionalFunctionality and use VPP as the VPP API connection.
+func (h *Harness) NewClient(ctx context.Context, opts ...client.Option) networkservice.NetworkServiceClient {
+	return h.Domain.Nodes[0].NewClient(ctx, sandbox.GenerateTestToken, opts...)
+}
+
+// NewNSCClient returns the client chain of the NSC configured by cfg dialing the sandbox NSMgr. The chain elements
+// use VPP as the VPP API connection.
+func (h *Harness) NewNSCClient(ctx context.Context, cfg *config.Config) networkservice.NetworkServiceClient {
+	nscMetrics, err := metrics.New()
+	require.NoError(h.t, err)
+
+	builder, err := nscclient.New(ctx, cfg, &nscclient.Dependencies{
+		VPPConn:    h.VPP,
+		Conns:      h.Conns,
+		ConnLog:    connlog.NewHook(),
+		Metrics:    nscMetrics,
+		Registry:   registry.New(),
+		Attacher:   attach.New(h.VPP),
+		Redundancy: redundancy.New(h.VPP),
+	})
+	require.NoError(h.t, err)
+	return builder.NewClient(h.NSMgrURL(), sandbox.DialTimeout)
+}
+
+// NewMonitorClient returns the client monitoring the NSC connections on the sandbox NSMgr
+func (h *Harness) NewMonitorClient() networkservice.MonitorConnectionClient {
+	return failover.NewMonitorClient(failover.New([]url.URL{*h.NSMgrURL()}, 1), h.Conns)
+}
+
+// Request requests the network service with the memif mechanism using nsc
+func (h *Harness) Request(ctx context.Context, nsc networkservice.NetworkServiceClient) *networkservice.Connection {
+	conn, err := nsc.Request(ctx, &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id:             sandbox.UniqueName("nsc"),
+			NetworkService: h.NetworkService,
+		},
+		MechanismPreferences: []*networkservice.Mechanism{
+			{Cls: cls.LOCAL, Type: memifmech.MECHANISM},
+		},
+	})
+	require.NoError(h.t, err)
+	require.NotNil(h.t, conn)
+	return conn
+}
+
+// RestartNSMgr restarts the sandbox NSMgr to exercise the recovery of the connections
+func (h *Harness) RestartNSMgr() {
+	h.Domain.Nodes[0].NSMgr.Restart()
+}
+
+// RestartEndpoint restarts the endpoint to exercise heal of the connections
+func (h *Harness) RestartEndpoint() {
+	h.Endpoint.Restart()
+}
+
+// KillEndpoint stops the endpoint, so heal can't restore the connections until another endpoint is registered
+func (h *Harness) KillEndpoint() {
+	h.Endpoint.Cancel()
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration && linux
+// +build integration,linux
+
+package harness_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	memifmech "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/count"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/config"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/connections"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/harness"
+)
+
+const (
+	networkService = "my-service"
+	timeout        = 10 * time.Second
+	tick           = 10 * time.Millisecond
+)
+
+func newConfig(t *testing.T) *config.Config {
+	cfg := new(config.Config)
+	require.NoError(t, config.Load(cfg))
+	return cfg
+}
+
+func TestNSC_Request(t *testing.T) {
+	ctx, cancel := context.WithTimeout(log.WithLog(context.Background(), log.Empty()), timeout)
+	defer cancel()
+
+	counter := new(count.Server)
+	h := harness.New(ctx, t, networkService, counter)
+	nsc := h.NewNSCClient(ctx, newConfig(t))
+
+	conn := h.Request(ctx, nsc)
+	require.Equal(t, memifmech.MECHANISM, conn.GetMechanism().GetType())
+	require.NotEmpty(t, conn.GetContext().GetIpContext().GetSrcIpAddrs())
+	require.Equal(t, 1, counter.Requests())
+	require.Equal(t, 1, h.VPP.Count("memif_create"))
+	require.Equal(t, 1, h.VPP.Count("sw_interface_set_flags"))
+
+	_, err := nsc.Close(ctx, conn)
+	require.NoError(t, err)
+	require.Equal(t, 1, counter.Closes())
+	require.Equal(t, 1, h.VPP.Count("memif_delete"))
+}
+
+func TestNSC_HealEndpointRestart(t *testing.T) {
+	ctx, cancel := context.WithTimeout(log.WithLog(context.Background(), log.Empty()), timeout)
+	defer cancel()
+
+	counter := new(count.Server)
+	h := harness.New(ctx, t, networkService, counter)
+	nsc := h.NewNSCClient(ctx, newConfig(t))
+
+	conn := h.Request(ctx, nsc)
+	require.Equal(t, 1, counter.Requests())
+
+	h.RestartEndpoint()
+	require.Eventually(t, func() bool { return counter.Requests() > 1 }, timeout, tick)
+	require.Equal(t, 1, counter.UniqueRequests())
+
+	_, err := nsc.Close(ctx, conn)
+	require.NoError(t, err)
+}
+
+func TestNSC_VPPRestart(t *testing.T) {
+	ctx, cancel := context.WithTimeout(log.WithLog(context.Background(), log.Empty()), timeout)
+	defer cancel()
+
+	counter := new(count.Server)
+	h := harness.New(ctx, t, networkService, counter)
+	cfg := newConfig(t)
+	manager := connections.NewManager(ctx, cfg.Name,
+		func(time.Duration) networkservice.NetworkServiceClient {
+			return h.NewNSCClient(ctx, cfg)
+		},
+		h.NewMonitorClient(),
+		connections.WithRequestTimeout(time.Second),
+	)
+
+	u, err := url.Parse("memif://" + networkService + "/nsm-1")
+	require.NoError(t, err)
+	require.NoError(t, manager.Update(ctx, []url.URL{*u}))
+	require.NoError(t, manager.Established(ctx))
+	require.Equal(t, 1, h.VPP.Count("memif_create"))
+
+	h.VPP.OnRestart(func(ctx context.Context) {
+		require.NoError(t, manager.Reconnect(ctx))
+	})
+	h.VPP.Restart(ctx)
+	require.NoError(t, manager.Established(ctx))
+	require.Equal(t, 1, h.VPP.Count("memif_delete"))
+	require.Equal(t, 2, h.VPP.Count("memif_create"))
+	require.Equal(t, 2, counter.Requests())
+
+	manager.CloseAll(ctx)
+	require.Equal(t, 2, counter.Closes())
+	require.Equal(t, 2, h.VPP.Count("memif_delete"))
+}
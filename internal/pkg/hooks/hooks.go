@@ -0,0 +1,137 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hooks runs user commands and webhooks on the connection lifecycle events
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/registry"
+)
+
+// EventEnv is the env var with the event name passed to the commands
+const EventEnv = "NSM_HOOK_EVENT"
+
+// payload is the JSON passed to the commands on stdin and posted to the webhooks
+type payload struct {
+	Event      registry.Event  `json:"event"`
+	Connection json.RawMessage `json:"connection"`
+}
+
+// Hooks runs the commands and calls the webhooks on the connection lifecycle events. Every hook receives the event
+// and the connection as JSON: the commands on stdin, the webhooks in the POST request body.
+type Hooks struct {
+	ctx      context.Context
+	commands []string
+	webhooks []*url.URL
+	timeout  time.Duration
+	client   *http.Client
+}
+
+// New creates new Hooks running the hooks until ctx is done. Every hook is stopped after timeout.
+func New(ctx context.Context, commands []string, webhooks []url.URL, timeout time.Duration) *Hooks {
+	h := &Hooks{
+		ctx:      ctx,
+		commands: commands,
+		timeout:  timeout,
+		client:   &http.Client{},
+	}
+	for i := range webhooks {
+		h.webhooks = append(h.webhooks, &webhooks[i])
+	}
+	return h
+}
+
+// Handle runs all the hooks for the event in background, it is a registry.EventHandler
+func (h *Hooks) Handle(_ context.Context, event registry.Event, conn *networkservice.Connection) {
+	if len(h.commands) == 0 && len(h.webhooks) == 0 {
+		return
+	}
+
+	connJSON, err := protojson.Marshal(conn)
+	if err != nil {
+		log.FromContext(h.ctx).Errorf("failed to marshal connection %s: %v", conn.GetId(), err.Error())
+		return
+	}
+	data, err := json.Marshal(&payload{Event: event, Connection: connJSON})
+	if err != nil {
+		log.FromContext(h.ctx).Errorf("failed to marshal %s event of %s: %v", event, conn.GetId(), err.Error())
+		return
+	}
+
+	for _, command := range h.commands {
+		go h.run(event, conn.GetId(), data, func(ctx context.Context) error {
+			return runCommand(ctx, command, event, data)
+		})
+	}
+	for _, webhook := range h.webhooks {
+		go h.run(event, conn.GetId(), data, func(ctx context.Context) error {
+			return h.post(ctx, webhook, data)
+		})
+	}
+}
+
+func (h *Hooks) run(event registry.Event, id string, data []byte, hook func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(h.ctx, h.timeout)
+	defer cancel()
+
+	if err := hook(ctx); err != nil {
+		log.FromContext(h.ctx).Errorf("%s hook of %s has failed: %+v", event, id, err)
+	}
+}
+
+func runCommand(ctx context.Context, command string, event registry.Event, data []byte) error {
+	cmd := exec.CommandContext(ctx, command) // nolint:gosec
+	cmd.Env = append(os.Environ(), EventEnv+"="+string(event))
+	cmd.Stdin = bytes.NewReader(data)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "command %s has failed: %s", command, output)
+	}
+	return nil
+}
+
+func (h *Hooks) post(ctx context.Context, webhook *url.URL, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.String(), bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create request to %s", webhook.String())
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to call webhook %s", webhook.String())
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("webhook %s has returned %s", webhook.String(), resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package httputils provides helpers for the local HTTP servers
+package httputils
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	readHeaderTimeout = time.Second * 10
+	shutdownTimeout   = time.Second * 5
+)
+
+// ListenAndServe listens on address with handler. Returns an chan err which will receive an error and then be
+// closed in the event that server.Serve(listener) returns an error. Server is shut down when ctx is done.
+func ListenAndServe(ctx context.Context, address string, handler http.Handler) <-chan error {
+	errCh := make(chan error, 1)
+
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		errCh <- errors.Wrapf(err, "failed to listen on %s", address)
+		close(errCh)
+		return errCh
+	}
+
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancelShutdown()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	go func() {
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+	return errCh
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ifmap provides a local read-only gRPC API the sidecars query to map the Network Services to the
+// connections, their memif parameters and VPP interface indexes
+package ifmap
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const serviceName = "nsc.interfaces.Interfaces"
+
+// InterfacesClient is the client API for the Interfaces service
+type InterfacesClient interface {
+	// ListConnections returns the "connections" list of the connections of the Network Service, all the connections
+	// if the Network Service is empty
+	ListConnections(ctx context.Context, in *wrapperspb.StringValue, opts ...grpc.CallOption) (*structpb.Struct, error)
+	// GetIfIndex returns VPP interface index of the connection with the ID or of the only connection of the Network
+	// Service
+	GetIfIndex(ctx context.Context, in *wrapperspb.StringValue, opts ...grpc.CallOption) (*wrapperspb.UInt32Value, error)
+}
+
+type interfacesClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewInterfacesClient creates a new InterfacesClient
+func NewInterfacesClient(cc grpc.ClientConnInterface) InterfacesClient {
+	return &interfacesClient{cc: cc}
+}
+
+func (c *interfacesClient) ListConnections(ctx context.Context, in *wrapperspb.StringValue, opts ...grpc.CallOption) (*structpb.Struct, error) {
+	out := new(structpb.Struct)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/ListConnections", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interfacesClient) GetIfIndex(ctx context.Context, in *wrapperspb.StringValue, opts ...grpc.CallOption) (*wrapperspb.UInt32Value, error) {
+	out := new(wrapperspb.UInt32Value)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/GetIfIndex", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InterfacesServer is the server API for the Interfaces service
+type InterfacesServer interface {
+	// ListConnections returns the connections of the Network Service, all the connections if it is empty
+	ListConnections(context.Context, *wrapperspb.StringValue) (*structpb.Struct, error)
+	// GetIfIndex returns VPP interface index of the connection with the ID or of the only connection of the Network
+	// Service
+	GetIfIndex(context.Context, *wrapperspb.StringValue) (*wrapperspb.UInt32Value, error)
+}
+
+// UnimplementedInterfacesServer can be embedded to have forward compatible implementations
+type UnimplementedInterfacesServer struct{}
+
+// ListConnections is not implemented
+func (*UnimplementedInterfacesServer) ListConnections(context.Context, *wrapperspb.StringValue) (*structpb.Struct, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListConnections not implemented")
+}
+
+// GetIfIndex is not implemented
+func (*UnimplementedInterfacesServer) GetIfIndex(context.Context, *wrapperspb.StringValue) (*wrapperspb.UInt32Value, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetIfIndex not implemented")
+}
+
+// RegisterInterfacesServer registers srv on the gRPC server s
+func RegisterInterfacesServer(s grpc.ServiceRegistrar, srv InterfacesServer) {
+	s.RegisterService(&interfacesServiceDesc, srv)
+}
+
+func unaryHandler(call func(srv InterfacesServer, ctx context.Context, in *wrapperspb.StringValue) (interface{}, error), method string) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: method,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := new(wrapperspb.StringValue)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return call(srv.(InterfacesServer), ctx, in)
+			}
+			info := &grpc.UnaryServerInfo{
+				Server:     srv,
+				FullMethod: "/" + serviceName + "/" + method,
+			}
+			return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return call(srv.(InterfacesServer), ctx, req.(*wrapperspb.StringValue))
+			})
+		},
+	}
+}
+
+var interfacesServiceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*InterfacesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryHandler(func(srv InterfacesServer, ctx context.Context, in *wrapperspb.StringValue) (interface{}, error) {
+			return srv.ListConnections(ctx, in)
+		}, "ListConnections"),
+		unaryHandler(func(srv InterfacesServer, ctx context.Context, in *wrapperspb.StringValue) (interface{}, error) {
+			return srv.GetIfIndex(ctx, in)
+		}, "GetIfIndex"),
+	},
+	Streams: []grpc.StreamDesc{},
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ifmap
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/registry"
+)
+
+// ConnectionsFunc returns the state of the known connections
+type ConnectionsFunc func() []*registry.Info
+
+type interfacesServer struct {
+	connections ConnectionsFunc
+}
+
+// NewServer creates a new InterfacesServer answering from the connections
+func NewServer(connections ConnectionsFunc) InterfacesServer {
+	return &interfacesServer{connections: connections}
+}
+
+func (s *interfacesServer) ListConnections(_ context.Context, in *wrapperspb.StringValue) (*structpb.Struct, error) {
+	result := make([]*registry.Info, 0)
+	for _, info := range s.connections() {
+		if in.GetValue() == "" || info.NetworkService == in.GetValue() {
+			result = append(result, info)
+		}
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"connections": result})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal connections: %s", err.Error())
+	}
+	out := new(structpb.Struct)
+	if err = protojson.Unmarshal(data, out); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to convert connections: %s", err.Error())
+	}
+	return out, nil
+}
+
+func (s *interfacesServer) GetIfIndex(_ context.Context, in *wrapperspb.StringValue) (*wrapperspb.UInt32Value, error) {
+	if in.GetValue() == "" {
+		return nil, status.Error(codes.InvalidArgument, "connection id or Network Service is required")
+	}
+
+	var found []*registry.Info
+	for _, info := range s.connections() {
+		if info.ID == in.GetValue() {
+			found = []*registry.Info{info}
+			break
+		}
+		if info.NetworkService == in.GetValue() {
+			found = append(found, info)
+		}
+	}
+	switch {
+	case len(found) == 0:
+		return nil, status.Errorf(codes.NotFound, "no connection %s", in.GetValue())
+	case len(found) > 1:
+		return nil, status.Errorf(codes.FailedPrecondition, "%d connections of Network Service %s, query by connection id", len(found), in.GetValue())
+	case found[0].IfIndex == 0:
+		return nil, status.Errorf(codes.Unavailable, "connection %s has no interface", found[0].ID)
+	}
+	return wrapperspb.UInt32(found[0].IfIndex), nil
+}
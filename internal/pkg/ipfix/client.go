@@ -0,0 +1,279 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ipfix enables VPP flowprobe on the connection interfaces and exports the IPFIX flow records to a collector
+package ipfix
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/govpp/binapi/flowprobe"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/ipfix_export"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/types"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/postpone"
+)
+
+// defaultPort is the IPFIX collector port
+const defaultPort = 4739
+
+// pathMTU is the largest IPFIX packet sent to the collector
+const pathMTU = 1450
+
+// Config is the IPFIX export config
+type Config struct {
+	// Collector is the IPFIX collector address
+	Collector *net.UDPAddr
+	// Src is the source address of the IPFIX packets, it should be an address of a VPP interface the collector is
+	// reachable via
+	Src net.IP
+	// ActiveTimeout is the interval the records of the active flows are exported at
+	ActiveTimeout time.Duration
+	// PassiveTimeout is the inactivity interval the flows expire after
+	PassiveTimeout time.Duration
+}
+
+// ParseCollector parses the collector host:port, the port is 4739 if not set
+func ParseCollector(s string) (*net.UDPAddr, error) {
+	host, port := s, strconv.Itoa(defaultPort)
+	if h, p, err := net.SplitHostPort(s); err == nil {
+		host, port = h, p
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, errors.Errorf("invalid collector address %s", s)
+	}
+	portNumber, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, errors.Errorf("invalid collector port %s", s)
+	}
+	return &net.UDPAddr{IP: ip, Port: int(portNumber)}, nil
+}
+
+type metadataKey struct{}
+
+// probed is the interface with the flowprobe enabled and the enabled variants
+type probed struct {
+	swIfIndex interface_types.InterfaceIndex
+	which     []flowprobe.FlowprobeWhich
+}
+
+type ipfixClient struct {
+	vppConn api.Connection
+	config  *Config
+
+	mu      sync.Mutex
+	enabled bool
+}
+
+// NewClient returns a client chain element enabling the flowprobe in both directions on the connection interfaces.
+// The IPv4 and IPv6 flows are probed according to the connection addresses. It should be placed before the
+// mechanism client to see the interface index.
+func NewClient(vppConn api.Connection, config *Config) networkservice.NetworkServiceClient {
+	return &ipfixClient{
+		vppConn: vppConn,
+		config:  config,
+	}
+}
+
+func (c *ipfixClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	postponeCtxFunc := postpone.ContextWithValues(ctx)
+
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.add(ctx, conn); err != nil {
+		closeCtx, cancelClose := postponeCtxFunc()
+		defer cancelClose()
+
+		if _, closeErr := c.Close(closeCtx, conn, opts...); closeErr != nil {
+			err = errors.Wrapf(err, "connection closed with error: %s", closeErr.Error())
+		}
+
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (c *ipfixClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	if p, ok := loadAndDelete(ctx); ok {
+		c.del(ctx, p)
+	}
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
+
+func (c *ipfixClient) add(ctx context.Context, conn *networkservice.Connection) error {
+	swIfIndex, ok := ifindex.Load(ctx, true)
+	if !ok {
+		return nil
+	}
+	prev, loaded := load(ctx)
+	if loaded && prev.swIfIndex == swIfIndex {
+		return nil
+	}
+
+	if err := c.enable(ctx); err != nil {
+		return err
+	}
+	// The NSM interface is created again on reselect, so the flowprobe is moved to the new one
+	if loaded {
+		c.del(ctx, prev)
+	}
+
+	var hasIPv4, hasIPv6 bool
+	for _, ipNet := range conn.GetContext().GetIpContext().GetSrcIPNets() {
+		if ipNet.IP.To4() != nil {
+			hasIPv4 = true
+		} else {
+			hasIPv6 = true
+		}
+	}
+	p := &probed{swIfIndex: swIfIndex}
+	if hasIPv4 || !hasIPv6 {
+		if err := interfaceAddDel(ctx, c.vppConn, swIfIndex, flowprobe.FLOWPROBE_WHICH_IP4, true); err != nil {
+			// VPP may be restarted since the exporter is set
+			c.mu.Lock()
+			c.enabled = false
+			c.mu.Unlock()
+			return err
+		}
+		p.which = append(p.which, flowprobe.FLOWPROBE_WHICH_IP4)
+	}
+	if hasIPv6 {
+		if err := interfaceAddDel(ctx, c.vppConn, swIfIndex, flowprobe.FLOWPROBE_WHICH_IP6, true); err != nil {
+			if len(p.which) == 0 {
+				return err
+			}
+			// Some VPP versions probe a single variant per interface
+			log.FromContext(ctx).Warnf("only IPv4 flows are exported from interface %d: %s", swIfIndex, err.Error())
+		} else {
+			p.which = append(p.which, flowprobe.FLOWPROBE_WHICH_IP6)
+		}
+	}
+	store(ctx, p)
+
+	log.FromContext(ctx).Infof("flowprobe is enabled on interface %d", swIfIndex)
+	return nil
+}
+
+func (c *ipfixClient) del(ctx context.Context, p *probed) {
+	for _, which := range p.which {
+		if err := interfaceAddDel(ctx, c.vppConn, p.swIfIndex, which, false); err != nil {
+			log.FromContext(ctx).Warnf("failed to disable flowprobe on interface %d: %s", p.swIfIndex, err.Error())
+		}
+	}
+}
+
+// enable sets the IPFIX exporter and the flowprobe params once
+func (c *ipfixClient) enable(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.enabled {
+		return nil
+	}
+
+	now := time.Now()
+	if _, err := ipfix_export.NewServiceClient(c.vppConn).SetIpfixExporter(ctx, &ipfix_export.SetIpfixExporter{
+		CollectorAddress: types.ToVppAddress(c.config.Collector.IP),
+		CollectorPort:    uint16(c.config.Collector.Port),
+		SrcAddress:       types.ToVppAddress(c.config.Src),
+		PathMtu:          pathMTU,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi SetIpfixExporter returned error")
+	}
+	log.FromContext(ctx).
+		WithField("collector", c.config.Collector.String()).
+		WithField("src", c.config.Src.String()).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "SetIpfixExporter").Debug("completed")
+
+	now = time.Now()
+	if _, err := flowprobe.NewServiceClient(c.vppConn).FlowprobeSetParams(ctx, &flowprobe.FlowprobeSetParams{
+		RecordFlags:  flowprobe.FLOWPROBE_RECORD_FLAG_L3 | flowprobe.FLOWPROBE_RECORD_FLAG_L4,
+		ActiveTimer:  uint32(c.config.ActiveTimeout.Seconds()),
+		PassiveTimer: uint32(c.config.PassiveTimeout.Seconds()),
+	}); err != nil {
+		return errors.Wrap(err, "vppapi FlowprobeSetParams returned error")
+	}
+	log.FromContext(ctx).
+		WithField("activeTimer", c.config.ActiveTimeout).
+		WithField("passiveTimer", c.config.PassiveTimeout).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "FlowprobeSetParams").Debug("completed")
+
+	c.enabled = true
+	return nil
+}
+
+func interfaceAddDel(ctx context.Context, vppConn api.Connection, swIfIndex interface_types.InterfaceIndex, which flowprobe.FlowprobeWhich, isAdd bool) error {
+	now := time.Now()
+	if _, err := flowprobe.NewServiceClient(vppConn).FlowprobeInterfaceAddDel(ctx, &flowprobe.FlowprobeInterfaceAddDel{
+		IsAdd:     isAdd,
+		Which:     which,
+		Direction: flowprobe.FLOWPROBE_DIRECTION_BOTH,
+		SwIfIndex: swIfIndex,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi FlowprobeInterfaceAddDel returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", swIfIndex).
+		WithField("which", which).
+		WithField("isAdd", isAdd).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "FlowprobeInterfaceAddDel").Debug("completed")
+	return nil
+}
+
+func store(ctx context.Context, p *probed) {
+	metadata.Map(ctx, true).Store(metadataKey{}, p)
+}
+
+func load(ctx context.Context) (*probed, bool) {
+	rawValue, ok := metadata.Map(ctx, true).Load(metadataKey{})
+	if !ok {
+		return nil, false
+	}
+	p, ok := rawValue.(*probed)
+	return p, ok
+}
+
+func loadAndDelete(ctx context.Context) (*probed, bool) {
+	rawValue, ok := metadata.Map(ctx, true).LoadAndDelete(metadataKey{})
+	if !ok {
+		return nil, false
+	}
+	p, ok := rawValue.(*probed)
+	return p, ok
+}
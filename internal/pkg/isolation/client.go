@@ -0,0 +1,239 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package isolation places the NSM connection interfaces into their own VPP VRFs, so the overlapping IP ranges of
+// the different Network Services don't collide in the default table
+package isolation
+
+import (
+	"context"
+	"net"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/vrf"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/postpone"
+)
+
+// Leaking configures the routes leaked between the connection VRF and the default table
+type Leaking struct {
+	// Export are the prefixes reachable via the connection which are routed from the default table into the
+	// connection VRF
+	Export []*net.IPNet
+	// Import are the prefixes of the default table which are routed from the connection VRF into the default table
+	Import []*net.IPNet
+}
+
+type contextKey struct{}
+
+// WithLeaking returns a context requesting the connection with the routes leaked between its VRF and the default
+// table
+func WithLeaking(ctx context.Context, l *Leaking) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// vrfs is the state of the VRFs of a single connection
+type vrfs struct {
+	tables    map[bool]uint32
+	swIfIndex interface_types.InterfaceIndex
+	leaking   *Leaking
+}
+
+type metadataKey struct{}
+
+type isolationClient struct {
+	vppConn api.Connection
+	pairs   *pairs
+}
+
+// Option is an option pattern for NewClient
+type Option func(o *options)
+
+type options struct {
+	pairs []Pair
+}
+
+// WithRoutedPairs sets the pairs of the Network Services which connections are routed to each other between their
+// VRFs, so VPP forwards the traffic between the paired connections only
+func WithRoutedPairs(pairs ...Pair) Option {
+	return func(o *options) {
+		o.pairs = append(o.pairs, pairs...)
+	}
+}
+
+// NewClient returns a client chain element creating a VRF for every connection and placing the connection
+// interface into it. The VRFs are stored in the sdk-vpp vrf metadata, so the IP context routes are installed into
+// them. It should be placed after the connection context clients and before the mechanism clients, so the
+// interface is moved to the VRF before the addresses are assigned.
+func NewClient(vppConn api.Connection, opts ...Option) networkservice.NetworkServiceClient {
+	o := new(options)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &isolationClient{
+		vppConn: vppConn,
+		pairs:   newPairs(o.pairs),
+	}
+}
+
+func (c *isolationClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	v, loaded := load(ctx)
+	if !loaded {
+		v = &vrfs{tables: make(map[bool]uint32)}
+		v.leaking, _ = ctx.Value(contextKey{}).(*Leaking)
+		for _, isIPv6 := range []bool{false, true} {
+			tableID, err := createTable(ctx, c.vppConn, isIPv6)
+			if err != nil {
+				c.delete(ctx, v)
+				return nil, err
+			}
+			v.tables[isIPv6] = tableID
+			vrf.Store(ctx, true, isIPv6, tableID)
+		}
+		store(ctx, v)
+	}
+
+	postponeCtxFunc := postpone.ContextWithValues(ctx)
+
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		if !loaded {
+			c.delete(ctx, v)
+			deleteMetadata(ctx)
+		}
+		return nil, err
+	}
+
+	err = c.attach(ctx, v)
+	if err == nil {
+		err = c.pairs.update(ctx, c.vppConn, conn, v.tables)
+	}
+	if err != nil {
+		closeCtx, cancelClose := postponeCtxFunc()
+		defer cancelClose()
+
+		if _, closeErr := c.Close(closeCtx, conn, opts...); closeErr != nil {
+			err = errors.Wrapf(err, "connection closed with error: %s", closeErr.Error())
+		}
+
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (c *isolationClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	v, loaded := load(ctx)
+	if loaded {
+		c.pairs.delete(ctx, c.vppConn, conn)
+		c.leak(ctx, v, false)
+	}
+	resp, err := next.Client(ctx).Close(ctx, conn, opts...)
+	// The tables are deleted after the interface is deleted by the mechanism client
+	if loaded {
+		c.delete(ctx, v)
+		deleteMetadata(ctx)
+	}
+	return resp, err
+}
+
+func (c *isolationClient) attach(ctx context.Context, v *vrfs) error {
+	swIfIndex, ok := ifindex.Load(ctx, true)
+	if !ok || swIfIndex == v.swIfIndex {
+		return nil
+	}
+	if v.swIfIndex != 0 {
+		c.leak(ctx, v, false)
+	}
+
+	// The NSM interface is created again on reselect, so the new one is moved to the VRFs
+	for _, isIPv6 := range []bool{false, true} {
+		if err := setTable(ctx, c.vppConn, swIfIndex, v.tables[isIPv6], isIPv6); err != nil {
+			return err
+		}
+	}
+	v.swIfIndex = swIfIndex
+	if err := c.leak(ctx, v, true); err != nil {
+		return err
+	}
+
+	log.FromContext(ctx).Infof("interface %d is placed into VRFs %v", swIfIndex, v.tables)
+	return nil
+}
+
+// leak adds or deletes the routes leaked between the connection VRFs and the default table. The errors are only
+// returned on add.
+func (c *isolationClient) leak(ctx context.Context, v *vrfs, isAdd bool) error {
+	if v.leaking == nil {
+		return nil
+	}
+	for _, prefix := range v.leaking.Export {
+		tableID := v.tables[prefix.IP.To4() == nil]
+		if err := routeViaTable(ctx, c.vppConn, 0, tableID, prefix, isAdd); err != nil {
+			if isAdd {
+				return err
+			}
+			log.FromContext(ctx).Warnf("failed to delete leaked route %s: %s", prefix.String(), err.Error())
+		}
+	}
+	for _, prefix := range v.leaking.Import {
+		tableID := v.tables[prefix.IP.To4() == nil]
+		if err := routeViaTable(ctx, c.vppConn, tableID, 0, prefix, isAdd); err != nil {
+			if isAdd {
+				return err
+			}
+			log.FromContext(ctx).Warnf("failed to delete leaked route %s: %s", prefix.String(), err.Error())
+		}
+	}
+	return nil
+}
+
+func (c *isolationClient) delete(ctx context.Context, v *vrfs) {
+	for isIPv6, tableID := range v.tables {
+		if err := deleteTable(ctx, c.vppConn, tableID, isIPv6); err != nil {
+			log.FromContext(ctx).Warnf("failed to delete VRF %d: %s", tableID, err.Error())
+		}
+	}
+}
+
+func store(ctx context.Context, v *vrfs) {
+	metadata.Map(ctx, true).Store(metadataKey{}, v)
+}
+
+func load(ctx context.Context) (*vrfs, bool) {
+	rawValue, ok := metadata.Map(ctx, true).Load(metadataKey{})
+	if !ok {
+		return nil, false
+	}
+	v, ok := rawValue.(*vrfs)
+	return v, ok
+}
+
+func deleteMetadata(ctx context.Context) {
+	metadata.Map(ctx, true).Delete(metadataKey{})
+	vrf.Delete(ctx, true, false)
+	vrf.Delete(ctx, true, true)
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package isolation
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// Pair is a pair of the Network Services which connections are routed to each other between their VRFs
+type Pair [2]string
+
+// ParsePairs parses the comma separated pairs of the Network Services, e.g. service-a:service-b
+func ParsePairs(values ...string) ([]Pair, error) {
+	var result []Pair
+	for _, value := range values {
+		for _, s := range strings.Split(value, ",") {
+			if s = strings.TrimSpace(s); s == "" {
+				continue
+			}
+			first, second, ok := strings.Cut(s, ":")
+			first, second = strings.TrimSpace(first), strings.TrimSpace(second)
+			if !ok || first == "" || second == "" || first == second {
+				return nil, errors.Errorf("invalid Network Service pair %s", s)
+			}
+			result = append(result, Pair{first, second})
+		}
+	}
+	return result, nil
+}
+
+// routed is the state of the connection routed to the connections of the paired Network Services
+type routed struct {
+	id       string
+	tables   map[bool]uint32
+	prefixes []*net.IPNet
+}
+
+// pairs keeps the routes between the VRFs of the connections of the paired Network Services. A Network Service is
+// expected to have a single connection.
+type pairs struct {
+	peers map[string][]string
+
+	mu    sync.Mutex
+	conns map[string]*routed
+}
+
+func newPairs(list []Pair) *pairs {
+	p := &pairs{
+		peers: make(map[string][]string),
+		conns: make(map[string]*routed),
+	}
+	for _, pair := range list {
+		p.peers[pair[0]] = append(p.peers[pair[0]], pair[1])
+		p.peers[pair[1]] = append(p.peers[pair[1]], pair[0])
+	}
+	return p
+}
+
+// update routes the prefixes reachable via the connection from the VRFs of the paired connections and the other
+// way round
+func (p *pairs) update(ctx context.Context, vppConn api.Connection, conn *networkservice.Connection, tables map[bool]uint32) error {
+	service := conn.GetNetworkService()
+	if len(p.peers[service]) == 0 {
+		return nil
+	}
+	prefixes := remotePrefixes(conn)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if r, ok := p.conns[service]; ok {
+		if r.id == conn.GetId() && equalPrefixes(r.prefixes, prefixes) {
+			return nil
+		}
+		p.remove(ctx, vppConn, service, r)
+	}
+
+	r := &routed{
+		id:       conn.GetId(),
+		tables:   tables,
+		prefixes: prefixes,
+	}
+	p.conns[service] = r
+	for _, peer := range p.peers[service] {
+		other, ok := p.conns[peer]
+		if !ok {
+			continue
+		}
+		if err := routeBetween(ctx, vppConn, r, other, true); err != nil {
+			return err
+		}
+		if err := routeBetween(ctx, vppConn, other, r, true); err != nil {
+			return err
+		}
+		log.FromContext(ctx).Infof("connections %s and %s are routed to each other", r.id, other.id)
+	}
+	return nil
+}
+
+// delete deletes the routes between the connection and the connections of the paired Network Services
+func (p *pairs) delete(ctx context.Context, vppConn api.Connection, conn *networkservice.Connection) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if r, ok := p.conns[conn.GetNetworkService()]; ok && r.id == conn.GetId() {
+		p.remove(ctx, vppConn, conn.GetNetworkService(), r)
+	}
+}
+
+func (p *pairs) remove(ctx context.Context, vppConn api.Connection, service string, r *routed) {
+	delete(p.conns, service)
+	for _, peer := range p.peers[service] {
+		if other, ok := p.conns[peer]; ok {
+			_ = routeBetween(ctx, vppConn, r, other, false)
+			_ = routeBetween(ctx, vppConn, other, r, false)
+		}
+	}
+}
+
+// routeBetween adds or deletes the routes for the prefixes of the connection to in the VRFs of the connection from.
+// The errors are only returned on add.
+func routeBetween(ctx context.Context, vppConn api.Connection, from, to *routed, isAdd bool) error {
+	for _, prefix := range to.prefixes {
+		isIPv6 := prefix.IP.To4() == nil
+		if err := routeViaTable(ctx, vppConn, from.tables[isIPv6], to.tables[isIPv6], prefix, isAdd); err != nil {
+			if isAdd {
+				return err
+			}
+			log.FromContext(ctx).Warnf("failed to delete route %s between connections: %s", prefix.String(), err.Error())
+		}
+	}
+	return nil
+}
+
+// remotePrefixes returns the prefixes reachable via the connection: the destination addresses and the routes
+func remotePrefixes(conn *networkservice.Connection) []*net.IPNet {
+	ipContext := conn.GetContext().GetIpContext()
+	ipNets := ipContext.GetDstIPNets()
+	for _, route := range ipContext.GetSrcRoutes() {
+		if ipNet := route.GetPrefixIPNet(); ipNet != nil {
+			ipNets = append(ipNets, ipNet)
+		}
+	}
+
+	var result []*net.IPNet
+	seen := make(map[string]bool)
+	for _, ipNet := range ipNets {
+		if ipNet == nil {
+			continue
+		}
+		prefix := &net.IPNet{IP: ipNet.IP.Mask(ipNet.Mask), Mask: ipNet.Mask}
+		if !seen[prefix.String()] {
+			seen[prefix.String()] = true
+			result = append(result, prefix)
+		}
+	}
+	return result
+}
+
+func equalPrefixes(a, b []*net.IPNet) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].String() != b[i].String() {
+			return false
+		}
+	}
+	return true
+}
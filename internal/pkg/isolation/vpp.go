@@ -0,0 +1,122 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package isolation
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/networkservicemesh/govpp/binapi/fib_types"
+	interfaces "github.com/networkservicemesh/govpp/binapi/interface"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/ip"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/types"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+func createTable(ctx context.Context, vppConn api.Connection, isIPv6 bool) (uint32, error) {
+	now := time.Now()
+	reply, err := ip.NewServiceClient(vppConn).IPTableAllocate(ctx, &ip.IPTableAllocate{
+		Table: ip.IPTable{
+			TableID: ^uint32(0),
+			IsIP6:   isIPv6,
+		},
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "vppapi IPTableAllocate returned error")
+	}
+	log.FromContext(ctx).
+		WithField("tableID", reply.Table.TableID).
+		WithField("isIPv6", isIPv6).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "IPTableAllocate").Debug("completed")
+	return reply.Table.TableID, nil
+}
+
+func deleteTable(ctx context.Context, vppConn api.Connection, tableID uint32, isIPv6 bool) error {
+	now := time.Now()
+	if _, err := ip.NewServiceClient(vppConn).IPTableAddDel(ctx, &ip.IPTableAddDel{
+		IsAdd: false,
+		Table: ip.IPTable{
+			TableID: tableID,
+			IsIP6:   isIPv6,
+		},
+	}); err != nil {
+		return errors.Wrap(err, "vppapi IPTableAddDel returned error")
+	}
+	log.FromContext(ctx).
+		WithField("tableID", tableID).
+		WithField("isIPv6", isIPv6).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "IPTableAddDel").Debug("completed")
+	return nil
+}
+
+func setTable(ctx context.Context, vppConn api.Connection, swIfIndex interface_types.InterfaceIndex, tableID uint32, isIPv6 bool) error {
+	now := time.Now()
+	if _, err := interfaces.NewServiceClient(vppConn).SwInterfaceSetTable(ctx, &interfaces.SwInterfaceSetTable{
+		SwIfIndex: swIfIndex,
+		IsIPv6:    isIPv6,
+		VrfID:     tableID,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi SwInterfaceSetTable returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", swIfIndex).
+		WithField("tableID", tableID).
+		WithField("isIPv6", isIPv6).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "SwInterfaceSetTable").Debug("completed")
+	return nil
+}
+
+// routeViaTable adds or deletes the route for prefix in the table tableID looked up in the table viaTableID
+func routeViaTable(ctx context.Context, vppConn api.Connection, tableID, viaTableID uint32, prefix *net.IPNet, isAdd bool) error {
+	now := time.Now()
+	if _, err := ip.NewServiceClient(vppConn).IPRouteAddDel(ctx, &ip.IPRouteAddDel{
+		IsAdd: isAdd,
+		Route: ip.IPRoute{
+			TableID: tableID,
+			Prefix:  types.ToVppPrefix(prefix),
+			NPaths:  1,
+			Paths: []fib_types.FibPath{
+				{
+					SwIfIndex: ^uint32(0),
+					TableID:   viaTableID,
+					Weight:    1,
+					Type:      fib_types.FIB_API_PATH_TYPE_NORMAL,
+					Proto:     types.IsV6toFibProto(prefix.IP.To4() == nil),
+				},
+			},
+		},
+	}); err != nil {
+		return errors.Wrap(err, "vppapi IPRouteAddDel returned error")
+	}
+	log.FromContext(ctx).
+		WithField("tableID", tableID).
+		WithField("viaTableID", viaTableID).
+		WithField("prefix", prefix.String()).
+		WithField("isAdd", isAdd).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "IPRouteAddDel").Debug("completed")
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jwttoken provides the generator of the JWT tokens sent to NSMgr with every call
+package jwttoken
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/token"
+)
+
+type cachedToken struct {
+	token      string
+	expireTime time.Time
+}
+
+// Generator generates the JWT tokens signed with the X.509 SVID key and reuses them until they are about to expire
+type Generator struct {
+	source       x509svid.Source
+	maxLifetime  time.Duration
+	refreshAhead time.Duration
+	audience     []string
+
+	mu     sync.Mutex
+	tokens map[string]*cachedToken
+}
+
+// New returns the generator of the tokens living for maxLifetime at most, limited by the X.509 SVID and the peer
+// certificate expiration. The token is regenerated refreshAhead before it expires, so it doesn't expire in the
+// middle of the call. The audience of the tokens is the peer SPIFFE ID if audience is empty.
+func New(source x509svid.Source, maxLifetime, refreshAhead time.Duration, audience ...string) *Generator {
+	return &Generator{
+		source:       source,
+		maxLifetime:  maxLifetime,
+		refreshAhead: refreshAhead,
+		audience:     audience,
+		tokens:       make(map[string]*cachedToken),
+	}
+}
+
+// GeneratorFunc returns the token generator func for token.NewPerRPCCredentials
+func (g *Generator) GeneratorFunc() token.GeneratorFunc {
+	return g.generate
+}
+
+func (g *Generator) generate(authInfo credentials.AuthInfo) (string, time.Time, error) {
+	ownSVID, err := g.source.GetX509SVID()
+	if err != nil {
+		return "", time.Time{}, errors.Wrap(err, "failed to get X.509 SVID")
+	}
+
+	expireTime := time.Now().Add(g.maxLifetime)
+	if ownSVID.Certificates[0].NotAfter.Before(expireTime) {
+		expireTime = ownSVID.Certificates[0].NotAfter
+	}
+	claims := jwt.RegisteredClaims{
+		Subject:  ownSVID.ID.String(),
+		Audience: g.audience,
+	}
+	if tlsInfo, ok := authInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+		peerCert := tlsInfo.State.PeerCertificates[0]
+		peerID, idErr := x509svid.IDFromCert(peerCert)
+		if idErr != nil {
+			return "", time.Time{}, errors.Wrap(idErr, "failed to extract the SPIFFE ID from the peer certificate")
+		}
+		if peerCert.NotAfter.Before(expireTime) {
+			expireTime = peerCert.NotAfter
+		}
+		if len(claims.Audience) == 0 {
+			claims.Audience = []string{peerID.String()}
+		}
+	}
+
+	key := strings.Join(append([]string{ownSVID.Certificates[0].SerialNumber.String()}, claims.Audience...), ",")
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if cached, ok := g.tokens[key]; ok && time.Until(cached.expireTime) > g.refreshAhead {
+		return cached.token, cached.expireTime, nil
+	}
+
+	claims.ExpiresAt = jwt.NewNumericDate(expireTime)
+	tok, err := jwt.NewWithClaims(jwt.SigningMethodES256, claims).SignedString(ownSVID.PrivateKey)
+	if err != nil {
+		return "", time.Time{}, errors.Wrapf(err, "failed to create a new token, subject %s", claims.Subject)
+	}
+
+	// The tokens of the previous SVIDs and the expired ones are not used anymore
+	for k, cached := range g.tokens {
+		if time.Now().After(cached.expireTime) || !strings.HasPrefix(k, ownSVID.Certificates[0].SerialNumber.String()+",") {
+			delete(g.tokens, k)
+		}
+	}
+	g.tokens[key] = &cachedToken{
+		token:      tok,
+		expireTime: expireTime,
+	}
+	return tok, expireTime, nil
+}
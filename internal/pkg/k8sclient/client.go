@@ -0,0 +1,121 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package k8sclient provides a minimal Kubernetes API client using the in-cluster service account
+package k8sclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	tokenFile         = serviceAccountDir + "/token"
+	caFile            = serviceAccountDir + "/ca.crt"
+)
+
+// Client calls the Kubernetes API of the cluster the NSC runs in
+type Client struct {
+	host       string
+	httpClient *http.Client
+}
+
+// NewInCluster creates a Client authenticated with the pod service account
+func NewInCluster() (*Client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in a Kubernetes cluster: KUBERNETES_SERVICE_HOST or KUBERNETES_SERVICE_PORT is not set")
+	}
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read service account CA")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.Errorf("no certificates in %s", caFile)
+	}
+
+	return &Client{
+		host: net.JoinHostPort(host, port),
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs:    pool,
+					MinVersion: tls.VersionTLS12,
+				},
+			},
+		},
+	}, nil
+}
+
+// Do sends the request with the JSON encoded body to the API path and decodes the JSON response to out. Nil body
+// and out are skipped. The service account token is read on every call, as it is rotated by kubelet.
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to read service account token")
+	}
+
+	var reqBody io.Reader = http.NoBody
+	if body != nil {
+		data, marshalErr := json.Marshal(body)
+		if marshalErr != nil {
+			return errors.Wrap(marshalErr, "failed to encode Kubernetes API request")
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	u := url.URL{
+		Scheme:   "https",
+		Host:     c.host,
+		Path:     path,
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if err != nil {
+		return errors.Wrap(err, "failed to create Kubernetes API request")
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "%s %s has failed", method, path)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.Errorf("%s %s has failed: %s", method, path, resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	return errors.Wrapf(json.NewDecoder(resp.Body).Decode(out), "failed to decode %s response", path)
+}
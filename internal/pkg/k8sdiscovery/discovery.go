@@ -0,0 +1,124 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package k8sdiscovery finds the node-local NSMgr through the Kubernetes API using the in-cluster service account
+package k8sdiscovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/k8sclient"
+)
+
+type podList struct {
+	Items []pod `json:"items"`
+}
+
+type pod struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Containers []struct {
+			Ports []struct {
+				Name          string `json:"name"`
+				ContainerPort int    `json:"containerPort"`
+				Protocol      string `json:"protocol"`
+			} `json:"ports"`
+		} `json:"containers"`
+	} `json:"spec"`
+	Status struct {
+		Phase string `json:"phase"`
+		PodIP string `json:"podIP"`
+	} `json:"status"`
+}
+
+// Discover returns TCP URLs of the running NSMgr pods scheduled on the node nodeName
+//   - namespace - namespace of the NSMgr pods
+//   - labelSelector - label selector of the NSMgr pods, e.g. app=nsmgr
+//   - port - NSMgr port, the port named nsmgr or the first TCP container port of the pod is used if 0
+func Discover(ctx context.Context, nodeName, namespace, labelSelector string, port int) ([]url.URL, error) {
+	if nodeName == "" {
+		return nil, errors.New("node name is not set")
+	}
+	pods, err := listPods(ctx, namespace, labelSelector, "spec.nodeName="+nodeName)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []url.URL
+	for i := range pods {
+		p := &pods[i]
+		if p.Status.Phase != "Running" || p.Status.PodIP == "" {
+			continue
+		}
+		podPort := port
+		if podPort == 0 {
+			podPort = containerPort(p)
+		}
+		if podPort == 0 {
+			return nil, errors.Errorf("NSMgr pod %s has no TCP ports", p.Metadata.Name)
+		}
+		result = append(result, url.URL{
+			Scheme: "tcp",
+			Host:   net.JoinHostPort(p.Status.PodIP, strconv.Itoa(podPort)),
+		})
+	}
+	if len(result) == 0 {
+		return nil, errors.Errorf("no running NSMgr pods matching %s on the node %s", labelSelector, nodeName)
+	}
+	return result, nil
+}
+
+func containerPort(p *pod) int {
+	var first int
+	for _, c := range p.Spec.Containers {
+		for _, port := range c.Ports {
+			if port.Protocol != "" && port.Protocol != "TCP" {
+				continue
+			}
+			if port.Name == "nsmgr" {
+				return port.ContainerPort
+			}
+			if first == 0 {
+				first = port.ContainerPort
+			}
+		}
+	}
+	return first
+}
+
+func listPods(ctx context.Context, namespace, labelSelector, fieldSelector string) ([]pod, error) {
+	client, err := k8sclient.NewInCluster()
+	if err != nil {
+		return nil, err
+	}
+	var list podList
+	if err = client.Do(ctx, http.MethodGet, fmt.Sprintf("/api/v1/namespaces/%s/pods", namespace), url.Values{
+		"labelSelector": []string{labelSelector},
+		"fieldSelector": []string{fieldSelector},
+	}, nil, &list); err != nil {
+		return nil, errors.Wrap(err, "failed to list NSMgr pods")
+	}
+	return list.Items, nil
+}
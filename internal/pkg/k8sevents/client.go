@@ -0,0 +1,61 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sevents
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+)
+
+type eventsClient struct {
+	recorder *Recorder
+}
+
+// NewClient returns a client posting an event when a connection request fails and when it succeeds after the
+// failures. The events are posted once per failure streak.
+func (r *Recorder) NewClient() networkservice.NetworkServiceClient {
+	return &eventsClient{recorder: r}
+}
+
+func (c *eventsClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	id := request.GetConnection().GetId()
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		if _, failing := c.recorder.failing.LoadOrStore(id, struct{}{}); !failing {
+			c.recorder.Post(TypeWarning, ReasonRequestFailed, fmt.Sprintf("Request of connection %s to %s has failed: %s",
+				id, request.GetConnection().GetNetworkService(), err.Error()))
+		}
+		return nil, err
+	}
+	if _, failing := c.recorder.failing.LoadAndDelete(id); failing {
+		c.recorder.Post(TypeNormal, ReasonConnectionRestored, fmt.Sprintf("Connection %s to %s has been restored",
+			id, conn.GetNetworkService()))
+	}
+	return conn, nil
+}
+
+func (c *eventsClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	c.recorder.failing.Delete(conn.GetId())
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package k8sevents posts Kubernetes Events about the connection lifecycle to the NSC pod, so the NSM problems are
+// shown by kubectl describe pod
+package k8sevents
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/k8sclient"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/registry"
+)
+
+const (
+	component   = "nsc"
+	postTimeout = time.Second * 10
+)
+
+// Event types
+const (
+	// TypeNormal - the event is informational
+	TypeNormal = "Normal"
+	// TypeWarning - the event reports a problem
+	TypeWarning = "Warning"
+)
+
+// Event reasons
+const (
+	// ReasonRequestFailed - the connection request has failed
+	ReasonRequestFailed = "RequestFailed"
+	// ReasonConnectionRestored - the connection request has succeeded after the failures
+	ReasonConnectionRestored = "ConnectionRestored"
+	// ReasonHealTriggered - the datapath liveness check of the connection has failed
+	ReasonHealTriggered = "HealTriggered"
+	// ReasonConnectionHealed - the connection has been requested again after the failed liveness check or with
+	// a new NSE
+	ReasonConnectionHealed = "ConnectionHealed"
+)
+
+type objectReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	UID        string `json:"uid,omitempty"`
+}
+
+type event struct {
+	Metadata struct {
+		GenerateName string `json:"generateName"`
+		Namespace    string `json:"namespace"`
+	} `json:"metadata"`
+	InvolvedObject objectReference `json:"involvedObject"`
+	Reason         string          `json:"reason"`
+	Message        string          `json:"message"`
+	Type           string          `json:"type"`
+	FirstTimestamp time.Time       `json:"firstTimestamp"`
+	LastTimestamp  time.Time       `json:"lastTimestamp"`
+	Count          int             `json:"count"`
+	Source         struct {
+		Component string `json:"component"`
+		Host      string `json:"host,omitempty"`
+	} `json:"source"`
+	ReportingComponent string `json:"reportingComponent"`
+	ReportingInstance  string `json:"reportingInstance"`
+}
+
+type podMetadata struct {
+	Metadata struct {
+		UID string `json:"uid"`
+	} `json:"metadata"`
+}
+
+// Recorder posts the events to the pod
+type Recorder struct {
+	ctx       context.Context
+	client    *k8sclient.Client
+	pod       objectReference
+	node      string
+	uidOnce   sync.Once
+	failing   sync.Map
+	postQueue chan *event
+}
+
+// New creates a Recorder posting the events to the pod podName in namespace running on the node nodeName until
+// ctx is done. The service account needs get pods and create events permissions.
+func New(ctx context.Context, podName, namespace, nodeName string) (*Recorder, error) {
+	if podName == "" || namespace == "" {
+		return nil, errors.New("pod name and namespace are required to post Kubernetes events")
+	}
+	client, err := k8sclient.NewInCluster()
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{
+		ctx:    ctx,
+		client: client,
+		pod: objectReference{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Namespace:  namespace,
+			Name:       podName,
+		},
+		node:      nodeName,
+		postQueue: make(chan *event, 100),
+	}
+	go r.run()
+	return r, nil
+}
+
+// Handle posts the events of the connection registry lifecycle events
+func (r *Recorder) Handle(_ context.Context, e registry.Event, conn *networkservice.Connection) {
+	switch e {
+	case registry.EventDegraded:
+		r.Post(TypeWarning, ReasonHealTriggered, fmt.Sprintf("Liveness check of connection %s to %s has failed, healing",
+			conn.GetId(), conn.GetNetworkService()))
+	case registry.EventHealed:
+		r.Post(TypeNormal, ReasonConnectionHealed, fmt.Sprintf("Connection %s to %s has been healed with NSE %s",
+			conn.GetId(), conn.GetNetworkService(), conn.GetNetworkServiceEndpointName()))
+	}
+}
+
+// Post queues the event to be posted, the event is dropped if the queue is full
+func (r *Recorder) Post(eventType, reason, message string) {
+	now := time.Now().UTC().Truncate(time.Second)
+	e := &event{
+		InvolvedObject:     r.pod,
+		Reason:             reason,
+		Message:            message,
+		Type:               eventType,
+		FirstTimestamp:     now,
+		LastTimestamp:      now,
+		Count:              1,
+		ReportingComponent: component,
+		ReportingInstance:  r.pod.Name,
+	}
+	e.Metadata.GenerateName = r.pod.Name + "."
+	e.Metadata.Namespace = r.pod.Namespace
+	e.Source.Component = component
+	e.Source.Host = r.node
+
+	select {
+	case r.postQueue <- e:
+	default:
+		log.FromContext(r.ctx).Warnf("Kubernetes event queue is full, dropping %s event: %s", reason, message)
+	}
+}
+
+func (r *Recorder) run() {
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case e := <-r.postQueue:
+			if err := r.post(e); err != nil {
+				log.FromContext(r.ctx).Warnf("failed to post Kubernetes %s event: %v", e.Reason, err.Error())
+			}
+		}
+	}
+}
+
+func (r *Recorder) post(e *event) error {
+	ctx, cancel := context.WithTimeout(r.ctx, postTimeout)
+	defer cancel()
+
+	// The pod UID is needed to find the events by kubectl describe
+	r.uidOnce.Do(func() {
+		var pod podMetadata
+		path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s", r.pod.Namespace, r.pod.Name)
+		if err := r.client.Do(ctx, http.MethodGet, path, nil, nil, &pod); err != nil {
+			log.FromContext(r.ctx).Warnf("failed to get pod UID: %v", err.Error())
+			return
+		}
+		r.pod.UID = pod.Metadata.UID
+	})
+	e.InvolvedObject.UID = r.pod.UID
+
+	return r.client.Do(ctx, http.MethodPost, fmt.Sprintf("/api/v1/namespaces/%s/events", r.pod.Namespace), nil, e, nil)
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package labels provides the templating of the request labels with the pod fields
+package labels
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Names of the variables set from the pod fields
+const (
+	PodNameVariable   = "podName"
+	NodeNameVariable  = "nodeName"
+	NamespaceVariable = "namespace"
+)
+
+var referenceRegexp = regexp.MustCompile(`{([^{}]*)}`)
+
+// Variables are the values referenced in the label values as {name}
+type Variables map[string]string
+
+// Set sets the variable if the value is not empty
+func (v Variables) Set(name, value string) {
+	if value != "" {
+		v[name] = value
+	}
+}
+
+// ReadDir sets the variables from the downward API volume files in dir named by the file names. The values are
+// trimmed, the hidden files are skipped.
+func (v Variables) ReadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read pod info directory %s", dir)
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		info, statErr := os.Stat(path)
+		if statErr != nil {
+			return errors.Wrapf(statErr, "failed to stat %s", path)
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		data, readErr := os.ReadFile(filepath.Clean(path))
+		if readErr != nil {
+			return errors.Wrapf(readErr, "failed to read %s", path)
+		}
+		v.Set(entry.Name(), strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// Expand replaces the {name} references in value with the variable values
+func (v Variables) Expand(value string) (string, error) {
+	var err error
+	result := referenceRegexp.ReplaceAllStringFunc(value, func(reference string) string {
+		name := reference[1 : len(reference)-1]
+		variable, ok := v[name]
+		if !ok && err == nil {
+			err = errors.Errorf("unknown variable %s in %s", reference, value)
+		}
+		return variable
+	})
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+// ExpandAll returns the labels with the expanded values
+func (v Variables) ExpandAll(labels map[string]string) (map[string]string, error) {
+	result := make(map[string]string, len(labels))
+	for key, value := range labels {
+		expanded, err := v.Expand(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid label %s", key)
+		}
+		result[key] = expanded
+	}
+	return result, nil
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package liveness
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/heal"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// TCP returns a check connecting to the port on the addresses of the connection from the connection source address
+func TCP(ctx context.Context, port int, opts ...Option) heal.LivenessCheck {
+	o := newOptions(opts...)
+	return func(deadlineCtx context.Context, conn *networkservice.Connection) bool {
+		dstAddresses := addresses(conn, o.withGateways)
+		if len(dstAddresses) == 0 {
+			log.FromContext(ctx).Infof("no destination IP to connect, skipping the liveness check")
+			return true
+		}
+
+		return checkAll(deadlineCtx, conn, dstAddresses, o.policy, func(deadlineCtx context.Context, conn *networkservice.Connection, address net.IP) bool {
+			dialer := &net.Dialer{}
+			if srcIP := sourceAddress(conn, address); srcIP != nil {
+				dialer.LocalAddr = &net.TCPAddr{IP: srcIP}
+			}
+			tcpConn, err := dialer.DialContext(deadlineCtx, "tcp", net.JoinHostPort(address.String(), strconv.Itoa(port)))
+			if err != nil {
+				log.FromContext(ctx).Warnf("TCP liveness check of %v has failed: %v", address, err.Error())
+				return false
+			}
+			_ = tcpConn.Close()
+			return true
+		})
+	}
+}
+
+// GRPCHealth returns a check calling the gRPC health service on the port on the addresses of the connection. The
+// service is the name of the checked service, the overall server health is checked if empty.
+func GRPCHealth(ctx context.Context, port int, service string, opts ...Option) heal.LivenessCheck {
+	o := newOptions(opts...)
+	return func(deadlineCtx context.Context, conn *networkservice.Connection) bool {
+		dstAddresses := addresses(conn, o.withGateways)
+		if len(dstAddresses) == 0 {
+			log.FromContext(ctx).Infof("no destination IP to check, skipping the liveness check")
+			return true
+		}
+
+		return checkAll(deadlineCtx, conn, dstAddresses, o.policy, func(deadlineCtx context.Context, conn *networkservice.Connection, address net.IP) bool {
+			dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+			if srcIP := sourceAddress(conn, address); srcIP != nil {
+				dialOptions = append(dialOptions, grpc.WithContextDialer(func(dialCtx context.Context, target string) (net.Conn, error) {
+					dialer := &net.Dialer{LocalAddr: &net.TCPAddr{IP: srcIP}}
+					return dialer.DialContext(dialCtx, "tcp", target)
+				}))
+			}
+			cc, err := grpc.DialContext(deadlineCtx, net.JoinHostPort(address.String(), strconv.Itoa(port)), dialOptions...)
+			if err != nil {
+				log.FromContext(ctx).Warnf("gRPC health check of %v has failed: %v", address, err.Error())
+				return false
+			}
+			defer func() { _ = cc.Close() }()
+
+			resp, err := grpc_health_v1.NewHealthClient(cc).Check(deadlineCtx, &grpc_health_v1.HealthCheckRequest{Service: service})
+			if err != nil {
+				log.FromContext(ctx).Warnf("gRPC health check of %v has failed: %v", address, err.Error())
+				return false
+			}
+			return resp.GetStatus() == grpc_health_v1.HealthCheckResponse_SERVING
+		})
+	}
+}
+
+// sourceAddress returns the source IP of the connection with the same address family as dstIP
+func sourceAddress(conn *networkservice.Connection, dstIP net.IP) net.IP {
+	for _, srcIPNet := range conn.GetContext().GetIpContext().GetSrcIPNets() {
+		if (srcIPNet.IP.To4() == nil) == (dstIP.To4() == nil) {
+			return srcIPNet.IP
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package liveness provides datapath liveness checks of the NSC connections
+package liveness
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/heal"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/metrics"
+)
+
+// Strategies of the liveness check
+const (
+	// StrategyVPPPing - ping the connection addresses from VPP
+	StrategyVPPPing = "vpp-ping"
+	// StrategyTCP - connect to the port on the connection addresses
+	StrategyTCP = "tcp"
+	// StrategyGRPCHealth - call the gRPC health service on the port on the connection addresses
+	StrategyGRPCHealth = "grpc-health"
+	// StrategyNone - don't check the datapath
+	StrategyNone = "none"
+)
+
+// New returns the liveness check for the strategy, it returns nil check for StrategyNone
+func New(ctx context.Context, strategy string, vppConn api.Connection, nscMetrics *metrics.Metrics, opts ...Option) (heal.LivenessCheck, error) {
+	if err := Validate(strategy, opts...); err != nil {
+		return nil, err
+	}
+	o := newOptions(opts...)
+	switch strategy {
+	case StrategyVPPPing:
+		return VPPPing(ctx, vppConn, nscMetrics, opts...), nil
+	case StrategyTCP:
+		return TCP(ctx, o.port, opts...), nil
+	case StrategyGRPCHealth:
+		return GRPCHealth(ctx, o.port, o.healthService, opts...), nil
+	default:
+		return nil, nil
+	}
+}
+
+// Validate returns an error if the liveness check for the strategy can't be created with opts
+func Validate(strategy string, opts ...Option) error {
+	o := newOptions(opts...)
+	switch strategy {
+	case StrategyVPPPing, StrategyNone:
+		return nil
+	case StrategyTCP, StrategyGRPCHealth:
+		if o.port == 0 {
+			return errors.Errorf("port is required for the %s liveness check", strategy)
+		}
+		return nil
+	default:
+		return errors.Errorf("unknown liveness check: %s", strategy)
+	}
+}
+
+// Policy is the aggregation policy of the checks of the connection addresses
+type Policy string
+
+const (
+	// PolicyAny - the connection is alive if any of its addresses is alive
+	PolicyAny Policy = "any"
+	// PolicyAll - the connection is alive if all of its addresses are alive
+	PolicyAll Policy = "all"
+)
+
+// ParsePolicy returns the Policy for the name
+func ParsePolicy(name string) (Policy, error) {
+	switch policy := Policy(strings.ToLower(name)); policy {
+	case PolicyAny, PolicyAll:
+		return policy, nil
+	default:
+		return "", errors.Errorf("unknown liveness check policy: %s", name)
+	}
+}
+
+// addressCheck checks the liveness of a single address of the connection
+type addressCheck func(ctx context.Context, conn *networkservice.Connection, address net.IP) bool
+
+// checkAll runs check for all the addresses at the same time and aggregates the results with the policy
+func checkAll(ctx context.Context, conn *networkservice.Connection, addresses []net.IP, policy Policy, check addressCheck) bool {
+	results := make([]bool, len(addresses))
+	var wg sync.WaitGroup
+	for i := range addresses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = check(ctx, conn, addresses[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for _, alive := range results {
+		if alive && policy == PolicyAny {
+			return true
+		}
+		if !alive && policy == PolicyAll {
+			return false
+		}
+	}
+	return policy == PolicyAll
+}
+
+// addresses returns the destination IPs of the connection and the gateway IPs if withGateways is set
+func addresses(conn *networkservice.Connection, withGateways bool) []net.IP {
+	ipContext := conn.GetContext().GetIpContext()
+
+	var result []net.IP
+	for _, dstIP := range ipContext.GetDstIpAddrs() {
+		if ip := net.ParseIP(strings.Split(dstIP, "/")[0]); ip != nil {
+			result = append(result, ip)
+		}
+	}
+	if withGateways {
+		for _, route := range ipContext.GetSrcRoutes() {
+			ip := net.ParseIP(route.GetNextHop())
+			if ip == nil || containsIP(result, ip) {
+				continue
+			}
+			result = append(result, ip)
+		}
+	}
+	return result
+}
+
+func containsIP(ips []net.IP, ip net.IP) bool {
+	for _, i := range ips {
+		if i.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
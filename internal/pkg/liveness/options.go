@@ -0,0 +1,88 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package liveness
+
+import "time"
+
+// Option is an option pattern for the liveness checks
+type Option func(o *options)
+
+type options struct {
+	policy        Policy
+	withGateways  bool
+	port          int
+	healthService string
+
+	packetCount    int
+	packetInterval time.Duration
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		policy:      PolicyAny,
+		packetCount: 4,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithPolicy sets the aggregation policy of the checks of the connection addresses, PolicyAny by default
+func WithPolicy(policy Policy) Option {
+	return func(o *options) {
+		o.policy = policy
+	}
+}
+
+// WithGateways enables checking the gateway addresses of the connection in addition to the destination addresses
+func WithGateways(withGateways bool) Option {
+	return func(o *options) {
+		o.withGateways = withGateways
+	}
+}
+
+// WithPort sets the port used by the tcp and grpc-health checks
+func WithPort(port int) Option {
+	return func(o *options) {
+		o.port = port
+	}
+}
+
+// WithHealthService sets the service name checked by the grpc-health check
+func WithHealthService(healthService string) Option {
+	return func(o *options) {
+		o.healthService = healthService
+	}
+}
+
+// WithPacketCount sets the number of packets sent by the vpp-ping check to every address, 4 by default
+func WithPacketCount(packetCount int) Option {
+	return func(o *options) {
+		if packetCount > 0 {
+			o.packetCount = packetCount
+		}
+	}
+}
+
+// WithPacketInterval sets the time the vpp-ping check waits for the reply to every packet, it is derived from the
+// check timeout if not set
+func WithPacketInterval(packetInterval time.Duration) Option {
+	return func(o *options) {
+		o.packetInterval = packetInterval
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package liveness
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/govpp/binapi/ip_types"
+	"github.com/networkservicemesh/govpp/binapi/ping"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/heal"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/metrics"
+)
+
+// VPPPing returns a check pinging the addresses of the connection from VPP. VPP sends ICMP or ICMPv6 echo requests
+// depending on the address family.
+func VPPPing(ctx context.Context, vppConn api.Connection, nscMetrics *metrics.Metrics, opts ...Option) heal.LivenessCheck {
+	o := newOptions(opts...)
+	return func(deadlineCtx context.Context, conn *networkservice.Connection) bool {
+		l := log.FromContext(ctx)
+
+		defer l.Info("Finish pinging")
+
+		dstAddresses := addresses(conn, o.withGateways)
+		if len(dstAddresses) == 0 {
+			l.Infof("no destination IP to ping, skipping the liveness check")
+			return true
+		}
+
+		return checkAll(deadlineCtx, conn, dstAddresses, o.policy, func(deadlineCtx context.Context, conn *networkservice.Connection, address net.IP) bool {
+			return vppPing(ctx, deadlineCtx, vppConn, nscMetrics, conn, address, o)
+		})
+	}
+}
+
+func vppPing(ctx, deadlineCtx context.Context, vppConn api.Connection, nscMetrics *metrics.Metrics, conn *networkservice.Connection, address net.IP, o *options) bool {
+	l := log.FromContext(ctx)
+
+	defaultTimeout := time.Second
+	deadline, ok := deadlineCtx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultTimeout)
+	}
+	timeout := time.Until(deadline)
+
+	packetCount := o.packetCount
+	interval := timeout.Seconds() / float64(packetCount) * 0.7
+	if o.packetInterval > 0 {
+		interval = o.packetInterval.Seconds()
+	}
+
+	var msg ping.Ping
+
+	dstAddress, err := ip_types.ParseAddress(address.String())
+	if err != nil {
+		l.Errorf("failed to parse %v: %v", address, err.Error())
+		return false
+	}
+
+	l.Infof("DstAddr parsed: %v", dstAddress)
+
+	msg.Address = dstAddress
+	msg.Timeout = interval
+
+	replyCount := 0
+
+	for i := 0; i < packetCount; i++ {
+		start := time.Now()
+		reply, _ := ping.NewServiceClient(vppConn).Ping(deadlineCtx, &msg)
+		if reply != nil && reply.ReplyCount > 0 {
+			nscMetrics.PingRTT(ctx, conn, time.Since(start))
+		}
+		if deadlineCtx.Err() != nil {
+			l.Info("deadline exceeded")
+
+			if reply != nil {
+				replyCount += int(reply.ReplyCount)
+
+				l.Infof("reply.Retval: %v", reply.Retval)
+				l.Infof("reply.ReplyCount: %v", reply.ReplyCount)
+			}
+
+			nscMetrics.PingLoss(ctx, conn, i+1, replyCount)
+			return replyCount > 0
+		}
+
+		if reply != nil {
+			l.Infof("reply.Retval: %v", reply.Retval)
+			l.Infof("reply.ReplyCount: %v", reply.ReplyCount)
+
+			replyCount += int(reply.ReplyCount)
+		}
+	}
+
+	nscMetrics.PingLoss(ctx, conn, packetCount, replyCount)
+	return replyCount > 0
+}
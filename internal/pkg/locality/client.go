@@ -0,0 +1,131 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package locality provides a client chain element preferring or requiring the Network Service Endpoints on the
+// same node as the client
+package locality
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/postpone"
+)
+
+// Mode is the node locality mode of the selected endpoints
+type Mode string
+
+// Node locality modes
+const (
+	// Any accepts the endpoints on any node
+	Any Mode = ""
+	// Prefer requests the connection again if the endpoint is on the other node, and accepts it after all the
+	// attempts have failed
+	Prefer Mode = "prefer"
+	// Require requests the connection again if the endpoint is on the other node, and fails after all the attempts
+	// have failed
+	Require Mode = "require"
+)
+
+// localPathSegments is the length of the node-local connection path: NSC, NSMgr, forwarder and NSE. The remote
+// connection passes the second NSMgr and forwarder.
+const localPathSegments = 4
+
+// ParseMode parses the node locality mode
+func ParseMode(value string) (Mode, error) {
+	switch mode := Mode(value); mode {
+	case Any, Prefer, Require:
+		return mode, nil
+	default:
+		return Any, errors.Errorf("invalid node locality mode: %s", value)
+	}
+}
+
+// IsLocal returns true if the connection endpoint is on the same node as the client
+func IsLocal(conn *networkservice.Connection) bool {
+	return len(conn.GetPath().GetPathSegments()) <= localPathSegments
+}
+
+type localityClient struct {
+	mode     Mode
+	attempts int
+}
+
+// NewClient returns a client chain element requesting the connection up to attempts times until the endpoint
+// selected by NSMgr is on the same node as the client. The element has no effect if the endpoint is already
+// selected in the request.
+func NewClient(mode Mode, attempts int) networkservice.NetworkServiceClient {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return &localityClient{
+		mode:     mode,
+		attempts: attempts,
+	}
+}
+
+func (c *localityClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	if c.mode == Any || request.GetConnection().GetNetworkServiceEndpointName() != "" {
+		return next.Client(ctx).Request(ctx, request, opts...)
+	}
+
+	postponeCtxFunc := postpone.ContextWithValues(ctx)
+
+	for attempt := 1; ; attempt++ {
+		conn, err := next.Client(ctx).Request(ctx, request.Clone(), opts...)
+		if err != nil {
+			return nil, err
+		}
+		if IsLocal(conn) {
+			return conn, nil
+		}
+		if attempt >= c.attempts && c.mode == Prefer {
+			log.FromContext(ctx).Warnf("no node-local endpoint selected in %d attempts, using remote endpoint %s",
+				c.attempts, conn.GetNetworkServiceEndpointName())
+			return conn, nil
+		}
+
+		log.FromContext(ctx).Debugf("remote endpoint %s selected, closing the connection", conn.GetNetworkServiceEndpointName())
+
+		closeCtx, cancelClose := postponeCtxFunc()
+		_, closeErr := next.Client(ctx).Close(closeCtx, conn, opts...)
+		cancelClose()
+
+		if attempt >= c.attempts {
+			err = errors.Errorf("no node-local endpoint selected in %d attempts, last one: %s",
+				c.attempts, conn.GetNetworkServiceEndpointName())
+			if closeErr != nil {
+				err = errors.Wrapf(err, "connection closed with error: %s", closeErr.Error())
+			}
+			return nil, err
+		}
+		if closeErr != nil {
+			return nil, errors.Wrapf(closeErr, "failed to close the connection to remote endpoint %s",
+				conn.GetNetworkServiceEndpointName())
+		}
+	}
+}
+
+func (c *localityClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
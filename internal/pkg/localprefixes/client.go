@@ -0,0 +1,165 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package localprefixes provides a client chain element excluding the prefixes already used by the local
+// interfaces from the connection IP addresses
+package localprefixes
+
+import (
+	"context"
+	"io"
+	"net"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/golang/protobuf/ptypes/empty"
+	interfaces "github.com/networkservicemesh/govpp/binapi/interface"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/ip"
+	"github.com/networkservicemesh/govpp/binapi/ip_types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+type localPrefixesClient struct {
+	vppConn api.Connection
+}
+
+// NewClient returns a client chain element adding the prefixes of the host and VPP interfaces to the excluded
+// prefixes of the request, so the NSE doesn't allocate the addresses colliding with the node network. The addresses
+// of the requested connection itself are not excluded.
+func NewClient(vppConn api.Connection) networkservice.NetworkServiceClient {
+	return &localPrefixesClient{vppConn: vppConn}
+}
+
+func (c *localPrefixesClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn := request.GetConnection()
+	if conn.GetContext() == nil {
+		conn.Context = &networkservice.ConnectionContext{}
+	}
+	if conn.GetContext().GetIpContext() == nil {
+		conn.Context.IpContext = &networkservice.IPContext{}
+	}
+	ipCtx := conn.GetContext().GetIpContext()
+
+	prefixes := hostPrefixes(ctx)
+	vppPrefixes, err := c.vppPrefixes(ctx)
+	if err != nil {
+		log.FromContext(ctx).Warnf("failed to get VPP interface prefixes: %+v", err)
+	}
+	prefixes = append(prefixes, vppPrefixes...)
+
+	own := append(ipCtx.GetSrcIPNets(), ipCtx.GetDstIPNets()...)
+	excluded := make(map[string]bool)
+	for _, prefix := range ipCtx.GetExcludedPrefixes() {
+		excluded[prefix] = true
+	}
+	for _, prefix := range prefixes {
+		if excluded[prefix.String()] || contains(own, prefix) {
+			continue
+		}
+		excluded[prefix.String()] = true
+		ipCtx.ExcludedPrefixes = append(ipCtx.ExcludedPrefixes, prefix.String())
+	}
+	log.FromContext(ctx).Debugf("excluded prefixes: %v", ipCtx.GetExcludedPrefixes())
+
+	return next.Client(ctx).Request(ctx, request, opts...)
+}
+
+func (c *localPrefixesClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
+
+// hostPrefixes returns the prefixes of the global unicast addresses of the host interfaces
+func hostPrefixes(ctx context.Context) []*net.IPNet {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		log.FromContext(ctx).Warnf("failed to get host interface addresses: %s", err.Error())
+		return nil
+	}
+	var result []*net.IPNet
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.IsGlobalUnicast() {
+			result = append(result, network(ipNet))
+		}
+	}
+	return result
+}
+
+// vppPrefixes returns the prefixes of the global unicast addresses of all VPP interfaces
+func (c *localPrefixesClient) vppPrefixes(ctx context.Context) ([]*net.IPNet, error) {
+	ifaces, err := interfaces.NewServiceClient(c.vppConn).SwInterfaceDump(ctx, &interfaces.SwInterfaceDump{
+		SwIfIndex: ^interface_types.InterfaceIndex(0),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "vppapi SwInterfaceDump returned error")
+	}
+	var swIfIndexes []interface_types.InterfaceIndex
+	for {
+		details, recvErr := ifaces.Recv()
+		if recvErr == io.EOF {
+			break
+		}
+		if recvErr != nil {
+			return nil, errors.Wrap(recvErr, "vppapi SwInterfaceDump returned error")
+		}
+		swIfIndexes = append(swIfIndexes, details.SwIfIndex)
+	}
+
+	var result []*net.IPNet
+	for _, swIfIndex := range swIfIndexes {
+		for _, isIPv6 := range []bool{false, true} {
+			addrs, dumpErr := ip.NewServiceClient(c.vppConn).IPAddressDump(ctx, &ip.IPAddressDump{
+				SwIfIndex: swIfIndex,
+				IsIPv6:    isIPv6,
+			})
+			if dumpErr != nil {
+				return nil, errors.Wrap(dumpErr, "vppapi IPAddressDump returned error")
+			}
+			for {
+				details, recvErr := addrs.Recv()
+				if recvErr == io.EOF {
+					break
+				}
+				if recvErr != nil {
+					return nil, errors.Wrap(recvErr, "vppapi IPAddressDump returned error")
+				}
+				if ipNet := ip_types.Prefix(details.Prefix).ToIPNet(); ipNet.IP.IsGlobalUnicast() {
+					result = append(result, network(ipNet))
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+func network(ipNet *net.IPNet) *net.IPNet {
+	return &net.IPNet{IP: ipNet.IP.Mask(ipNet.Mask), Mask: ipNet.Mask}
+}
+
+// contains returns true if prefix contains any of the addresses
+func contains(addrs []*net.IPNet, prefix *net.IPNet) bool {
+	for _, addr := range addrs {
+		if prefix.Contains(addr.IP) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logfile provides a log file writer rotating the file by size
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// colors matches the terminal color sequences of the log formatters
+var colors = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// Writer writes to the file rotating it when it exceeds the max size. The rotated files are named <path>.1,
+// <path>.2, ... the newest first, files over the max backups are removed.
+type Writer struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// New opens the file at path for appending. Files are rotated after maxSize bytes, rotation is disabled if
+// maxSize is 0.
+func New(path string, maxSize int64, maxBackups int) (*Writer, error) {
+	w := &Writer{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, errors.Wrapf(err, "failed to create log directory for %s", path)
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write writes p without the terminal colors to the file
+func (w *Writer) Write(p []byte) (int, error) {
+	line := colors.ReplaceAll(p, nil)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(line)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(line)
+	w.size += int64(n)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to write %s", w.path)
+	}
+	return len(p), nil
+}
+
+func (w *Writer) open() error {
+	file, err := os.OpenFile(filepath.Clean(w.path), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o640)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", w.path)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return errors.Wrapf(err, "failed to stat %s", w.path)
+	}
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return errors.Wrapf(err, "failed to close %s", w.path)
+	}
+	if w.maxBackups > 0 {
+		_ = os.Remove(w.backup(w.maxBackups))
+	}
+	for i := w.maxBackups - 1; i > 0; i-- {
+		if err := os.Rename(w.backup(i), w.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to rotate %s", w.backup(i))
+		}
+	}
+	if w.maxBackups > 0 {
+		if err := os.Rename(w.path, w.backup(1)); err != nil {
+			return errors.Wrapf(err, "failed to rotate %s", w.path)
+		}
+	} else if err := os.Remove(w.path); err != nil {
+		return errors.Wrapf(err, "failed to remove %s", w.path)
+	}
+	return w.open()
+}
+
+func (w *Writer) backup(i int) string {
+	return fmt.Sprintf("%s.%d", w.path, i)
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package loglevels writes the log entries filtered by the log levels of the components, so the noisy components
+// can be silenced without losing the details of the others
+package loglevels
+
+import (
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ComponentField is the log entry field naming the component
+	ComponentField = "component"
+	// DefaultComponent is the name of the level used for the entries of the components without their own level
+	DefaultComponent = "default"
+)
+
+// Components known to the NSC
+const (
+	// HealComponent - the datapath liveness checks
+	HealComponent = "heal"
+	// VPPComponent - the VPP supervisor
+	VPPComponent = "vpp"
+)
+
+// Hook is a logrus hook writing the entries allowed by the component levels. The logger output should be discarded
+// and the logger level should be set to Hook.MaxLevel.
+type Hook struct {
+	levels       map[string]logrus.Level
+	defaultLevel logrus.Level
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// New creates a Hook writing to out. levels are the level names by component, the levels of the components not
+// listed and of the entries without the component are set by the "default" key or defaultLevel.
+func New(out io.Writer, levels map[string]string, defaultLevel logrus.Level) (*Hook, error) {
+	h := &Hook{
+		levels:       make(map[string]logrus.Level, len(levels)),
+		defaultLevel: defaultLevel,
+		out:          out,
+	}
+	for component, value := range levels {
+		level, err := logrus.ParseLevel(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid log level of %s", component)
+		}
+		if component == DefaultComponent {
+			h.defaultLevel = level
+			continue
+		}
+		h.levels[component] = level
+	}
+	return h, nil
+}
+
+// MaxLevel returns the most verbose of the levels
+func (h *Hook) MaxLevel() logrus.Level {
+	result := h.defaultLevel
+	for _, level := range h.levels {
+		if level > result {
+			result = level
+		}
+	}
+	return result
+}
+
+// Levels returns all log levels
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire writes the entry if its level is enabled for its component
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	level := h.defaultLevel
+	if component, ok := entry.Data[ComponentField].(string); ok {
+		if componentLevel, found := h.levels[component]; found {
+			level = componentLevel
+		}
+	}
+	if entry.Level > level {
+		return nil
+	}
+
+	line, err := entry.Logger.Formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, err = h.out.Write(line)
+	return err
+}
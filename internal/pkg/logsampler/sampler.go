@@ -0,0 +1,178 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logsampler limits the recurring identical log messages, so a flapping connection doesn't flood the logs
+package logsampler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/heal"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// maxRecords is the number of the remembered messages the expired ones are removed after
+const maxRecords = 1000
+
+type record struct {
+	logged     time.Time
+	suppressed int
+}
+
+// Sampler logs an identical message once per interval. The suppressed messages are counted and the count is added
+// to the message logged after the interval.
+type Sampler struct {
+	interval time.Duration
+
+	mu      sync.Mutex
+	records map[string]*record
+}
+
+// New creates a new Sampler
+func New(interval time.Duration) *Sampler {
+	return &Sampler{
+		interval: interval,
+		records:  make(map[string]*record),
+	}
+}
+
+// Logger returns the logger sampling the messages logged with logger. Fatal messages and objects are not sampled.
+func (s *Sampler) Logger(logger log.Logger) log.Logger {
+	return &sampledLogger{
+		logger:  logger,
+		sampler: s,
+	}
+}
+
+// LivenessCheck wraps the check, so the messages it logs to the context logger are sampled. Nil check is returned
+// as is.
+func (s *Sampler) LivenessCheck(check heal.LivenessCheck) heal.LivenessCheck {
+	if check == nil {
+		return nil
+	}
+	return func(deadlineCtx context.Context, conn *networkservice.Connection) bool {
+		return check(log.WithLog(deadlineCtx, s.Logger(log.FromContext(deadlineCtx))), conn)
+	}
+}
+
+// allow returns true and the number of the suppressed messages if the message with the key should be logged
+func (s *Sampler) allow(key string) (bool, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if r, ok := s.records[key]; ok {
+		if now.Sub(r.logged) < s.interval {
+			r.suppressed++
+			return false, 0
+		}
+		suppressed := r.suppressed
+		r.logged, r.suppressed = now, 0
+		return true, suppressed
+	}
+
+	if len(s.records) >= maxRecords {
+		for k, r := range s.records {
+			if now.Sub(r.logged) >= s.interval {
+				delete(s.records, k)
+			}
+		}
+	}
+	s.records[key] = &record{logged: now}
+	return true, 0
+}
+
+type sampledLogger struct {
+	logger  log.Logger
+	sampler *Sampler
+	fields  string
+}
+
+func (l *sampledLogger) log(level, msg string, write func(string)) {
+	ok, suppressed := l.sampler.allow(level + "\n" + l.fields + "\n" + msg)
+	if !ok {
+		return
+	}
+	if suppressed > 0 {
+		msg = fmt.Sprintf("%s (%d identical messages suppressed)", msg, suppressed)
+	}
+	write(msg)
+}
+
+func (l *sampledLogger) Info(v ...interface{}) {
+	l.log("info", fmt.Sprint(v...), func(msg string) { l.logger.Info(msg) })
+}
+
+func (l *sampledLogger) Infof(format string, v ...interface{}) {
+	l.log("info", fmt.Sprintf(format, v...), func(msg string) { l.logger.Info(msg) })
+}
+
+func (l *sampledLogger) Warn(v ...interface{}) {
+	l.log("warn", fmt.Sprint(v...), func(msg string) { l.logger.Warn(msg) })
+}
+
+func (l *sampledLogger) Warnf(format string, v ...interface{}) {
+	l.log("warn", fmt.Sprintf(format, v...), func(msg string) { l.logger.Warn(msg) })
+}
+
+func (l *sampledLogger) Error(v ...interface{}) {
+	l.log("error", fmt.Sprint(v...), func(msg string) { l.logger.Error(msg) })
+}
+
+func (l *sampledLogger) Errorf(format string, v ...interface{}) {
+	l.log("error", fmt.Sprintf(format, v...), func(msg string) { l.logger.Error(msg) })
+}
+
+func (l *sampledLogger) Fatal(v ...interface{}) {
+	l.logger.Fatal(v...)
+}
+
+func (l *sampledLogger) Fatalf(format string, v ...interface{}) {
+	l.logger.Fatalf(format, v...)
+}
+
+func (l *sampledLogger) Debug(v ...interface{}) {
+	l.log("debug", fmt.Sprint(v...), func(msg string) { l.logger.Debug(msg) })
+}
+
+func (l *sampledLogger) Debugf(format string, v ...interface{}) {
+	l.log("debug", fmt.Sprintf(format, v...), func(msg string) { l.logger.Debug(msg) })
+}
+
+func (l *sampledLogger) Trace(v ...interface{}) {
+	l.log("trace", fmt.Sprint(v...), func(msg string) { l.logger.Trace(msg) })
+}
+
+func (l *sampledLogger) Tracef(format string, v ...interface{}) {
+	l.log("trace", fmt.Sprintf(format, v...), func(msg string) { l.logger.Trace(msg) })
+}
+
+func (l *sampledLogger) Object(k, v interface{}) {
+	l.logger.Object(k, v)
+}
+
+func (l *sampledLogger) WithField(key, value interface{}) log.Logger {
+	return &sampledLogger{
+		logger:  l.logger.WithField(key, value),
+		sampler: l.sampler,
+		fields:  fmt.Sprintf("%s%v=%v;", l.fields, key, value),
+	}
+}
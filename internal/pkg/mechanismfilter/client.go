@@ -0,0 +1,62 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mechanismfilter provides a chain element passing only the mechanism preferences of the given types
+package mechanismfilter
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+)
+
+type mechanismFilterClient struct {
+	mechanismTypes map[string]bool
+}
+
+// NewClient returns a client dropping all the request mechanism preferences with types other than mechanismTypes.
+// It is used inside the mechanisms.NewClient branches, so every branch requests only its own mechanism and the
+// next preferred mechanism is requested only if the previous one fails. Remote mechanism clients build their
+// preferences themselves, so they are used with no mechanismTypes to drop the preferences coming from the URL.
+func NewClient(mechanismTypes ...string) networkservice.NetworkServiceClient {
+	m := &mechanismFilterClient{
+		mechanismTypes: make(map[string]bool),
+	}
+	for _, mechanismType := range mechanismTypes {
+		m.mechanismTypes[mechanismType] = true
+	}
+	return m
+}
+
+func (m *mechanismFilterClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	var preferences []*networkservice.Mechanism
+	for _, mechanism := range request.GetMechanismPreferences() {
+		if m.mechanismTypes[mechanism.GetType()] {
+			preferences = append(preferences, mechanism)
+		}
+	}
+	request.MechanismPreferences = preferences
+	return next.Client(ctx).Request(ctx, request, opts...)
+}
+
+func (m *mechanismFilterClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+// Package memif provides the NSC memif mechanism client creating memif interfaces in VPP with the interface
+// parameters requested in the memif mechanism preference
+package memif
+
+import (
+	"context"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/govpp/binapi/memif"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	memifMech "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/mechanisms/memif/memifrxmode"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/postpone"
+)
+
+// Connection aggregates the api.Connection and api.ChannelProvider interfaces
+type Connection interface {
+	api.Connection
+	api.ChannelProvider
+}
+
+type memifClient struct {
+	vppConn api.Connection
+	nsInfo  netNSInfo
+	opts    *options
+}
+
+// NewClient provides a NetworkServiceClient chain elements that support the memif Mechanism
+func NewClient(chainCtx context.Context, vppConn Connection, opts ...Option) networkservice.NetworkServiceClient {
+	o := &options{
+		socketName: "{id}.sock",
+		socketUID:  -1,
+		socketGID:  -1,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return chain.NewNetworkServiceClient(
+		memifrxmode.NewClient(chainCtx, vppConn),
+		&memifClient{
+			vppConn: vppConn,
+			nsInfo:  newNetNSInfo(),
+			opts:    o,
+		},
+	)
+}
+
+func (m *memifClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	params, err := m.updateMechanismPreferences(request)
+	if err != nil {
+		return nil, err
+	}
+
+	postponeCtxFunc := postpone.ContextWithValues(ctx)
+
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.socketFile == "" && params.role == memif.MEMIF_ROLE_API_MASTER && m.opts.socketDir != "" {
+		params.socketFile = filepath.Join(m.opts.socketDir, socketName(m.opts.socketName, conn))
+	}
+
+	if err = create(ctx, conn, m.vppConn, m.nsInfo.netNS, params, m.opts); err != nil {
+		closeCtx, cancelClose := postponeCtxFunc()
+		defer cancelClose()
+
+		if _, closeErr := m.Close(closeCtx, conn, opts...); closeErr != nil {
+			err = errors.Wrapf(err, "connection closed with error: %s", closeErr.Error())
+		}
+
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (m *memifClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	_ = del(ctx, conn, m.vppConn)
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
+
+// updateMechanismPreferences sets the NetNS URL of the memif preferences, or adds a new memif preference if there
+// is none. It returns the memif interface parameters of the first memif preference.
+func (m *memifClient) updateMechanismPreferences(request *networkservice.NetworkServiceRequest) (*parameters, error) {
+	var params *parameters
+	for _, p := range request.GetRequestMechanismPreferences() {
+		if mechanism := memifMech.ToMechanism(p); mechanism != nil {
+			mechanism.SetNetNSURL((&url.URL{Scheme: memifMech.FileScheme, Path: m.nsInfo.netNSPath}).String())
+			if params != nil {
+				continue
+			}
+			var err error
+			if params, err = parseParameters(p.GetParameters()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if params == nil {
+		mechanism := memifMech.ToMechanism(memifMech.NewAbstract(m.nsInfo.netNSPath))
+		request.MechanismPreferences = append(request.MechanismPreferences, mechanism.Mechanism)
+		params, _ = parseParameters(nil)
+	}
+	return params, nil
+}
+
+// socketName expands {id} and {service} in the socket name pattern
+func socketName(pattern string, conn *networkservice.Connection) string {
+	return strings.NewReplacer(
+		"{id}", conn.GetId(),
+		"{service}", conn.GetNetworkService(),
+	).Replace(pattern)
+}
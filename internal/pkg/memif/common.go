@@ -0,0 +1,261 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package memif
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/networkservicemesh/govpp/binapi/memif"
+	"github.com/pkg/errors"
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	memifMech "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/payload"
+	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/up"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+type netNSInfo struct {
+	netNS     netns.NsHandle
+	netNSPath string
+}
+
+func newNetNSInfo() netNSInfo {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	fd, err := unix.Open("/proc/thread-self/ns/net", unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		panic("failed to open '/proc/thread-self/ns/net': " + err.Error())
+	}
+	netNSPath := fmt.Sprintf("/proc/%d/fd/%d", os.Getpid(), fd)
+
+	netNS, err := netns.GetFromPath(netNSPath)
+	if err != nil {
+		panic("failed to get current net NS: " + err.Error())
+	}
+
+	return netNSInfo{
+		netNSPath: netNSPath,
+		netNS:     netNS,
+	}
+}
+
+func createMemifSocket(ctx context.Context, vppConn api.Connection, socketFilename string) (uint32, error) {
+	memifSocketAddDel := &memif.MemifSocketFilenameAddDelV2{
+		IsAdd:          true,
+		SocketID:       ^uint32(0),
+		SocketFilename: socketFilename,
+	}
+
+	now := time.Now()
+
+	reply, err := memif.NewServiceClient(vppConn).MemifSocketFilenameAddDelV2(ctx, memifSocketAddDel)
+	if err != nil {
+		return 0, errors.Wrap(err, "vppapi MemifSocketFilenameAddDelV2 returned error")
+	}
+	memifSocketAddDel.SocketID = reply.SocketID
+
+	log.FromContext(ctx).
+		WithField("SocketID", memifSocketAddDel.SocketID).
+		WithField("SocketFilename", memifSocketAddDel.SocketFilename).
+		WithField("IsAdd", memifSocketAddDel.IsAdd).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "MemifSocketFilenameAddDelV2").Debug("completed")
+
+	store(ctx, memifSocketAddDel)
+
+	return memifSocketAddDel.SocketID, nil
+}
+
+func deleteMemifSocket(ctx context.Context, vppConn api.Connection) error {
+	memifSocketAddDel, ok := load(ctx)
+	if !ok {
+		return nil
+	}
+
+	memifSocketAddDel.IsAdd = false
+
+	now := time.Now()
+
+	if _, err := memif.NewServiceClient(vppConn).MemifSocketFilenameAddDelV2(ctx, memifSocketAddDel); err != nil {
+		return errors.Wrap(err, "vppapi MemifSocketFilenameAddDelV2 returned error")
+	}
+
+	log.FromContext(ctx).
+		WithField("SocketID", memifSocketAddDel.SocketID).
+		WithField("SocketFilename", memifSocketAddDel.SocketFilename).
+		WithField("IsAdd", memifSocketAddDel.IsAdd).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "MemifSocketFilenameAddDelV2").Debug("completed")
+
+	return nil
+}
+
+func createMemif(ctx context.Context, vppConn api.Connection, socketID uint32, mode memif.MemifMode, params *parameters) error {
+	now := time.Now()
+	memifCreate := &memif.MemifCreate{
+		Role:       params.role,
+		SocketID:   socketID,
+		Mode:       mode,
+		RxQueues:   params.rxQueues,
+		TxQueues:   params.txQueues,
+		RingSize:   params.ringSize,
+		BufferSize: params.bufferSize,
+	}
+	rsp, err := memif.NewServiceClient(vppConn).MemifCreate(ctx, memifCreate)
+	if err != nil {
+		return errors.Wrap(err, "vppapi MemifCreate returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", rsp.SwIfIndex).
+		WithField("Role", memifCreate.Role).
+		WithField("SocketID", memifCreate.SocketID).
+		WithField("RxQueues", memifCreate.RxQueues).
+		WithField("TxQueues", memifCreate.TxQueues).
+		WithField("RingSize", memifCreate.RingSize).
+		WithField("BufferSize", memifCreate.BufferSize).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "MemifCreate").Debug("completed")
+	ifindex.Store(ctx, true, rsp.SwIfIndex)
+	up.Store(ctx, true, true)
+
+	return nil
+}
+
+func deleteMemif(ctx context.Context, vppConn api.Connection) error {
+	swIfIndex, ok := ifindex.LoadAndDelete(ctx, true)
+	if !ok {
+		return nil
+	}
+	now := time.Now()
+	memifDel := &memif.MemifDelete{
+		SwIfIndex: swIfIndex,
+	}
+	if _, err := memif.NewServiceClient(vppConn).MemifDelete(ctx, memifDel); err != nil {
+		return errors.Wrap(err, "vppapi MemifDelete returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", memifDel.SwIfIndex).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "MemifDelete").Debug("completed")
+	return nil
+}
+
+func create(ctx context.Context, conn *networkservice.Connection, vppConn api.Connection, netNS netns.NsHandle, params *parameters, opts *options) error {
+	mechanism := memifMech.ToMechanism(conn.GetMechanism())
+	if mechanism == nil {
+		return nil
+	}
+	socketFilename := params.socketFile
+	if socketFilename == "" {
+		var err error
+		if socketFilename, err = getVppSocketFilename(mechanism, netNS); err != nil {
+			return err
+		}
+	}
+	// This connection has already been created
+	if _, ok := ifindex.Load(ctx, true); ok {
+		if memifSocketAddDel, ok := load(ctx); ok && memifSocketAddDel.SocketFilename == socketFilename {
+			return nil
+		}
+	}
+	_ = del(ctx, conn, vppConn)
+
+	mode := memif.MEMIF_MODE_API_IP
+	if conn.GetPayload() == payload.Ethernet {
+		mode = memif.MEMIF_MODE_API_ETHERNET
+	}
+	if params.socketFile != "" {
+		if err := os.MkdirAll(filepath.Dir(params.socketFile), 0o755); err != nil {
+			return errors.Wrapf(err, "failed to create memif socket directory for %s", params.socketFile)
+		}
+	}
+	socketID, err := createMemifSocket(ctx, vppConn, socketFilename)
+	if err != nil {
+		return err
+	}
+	if err := createMemif(ctx, vppConn, socketID, mode, params); err != nil {
+		return err
+	}
+	if params.socketFile != "" && params.role == memif.MEMIF_ROLE_API_MASTER {
+		return setSocketPermissions(params.socketFile, opts)
+	}
+	return nil
+}
+
+// setSocketPermissions sets the mode and the owner of the socket file created by VPP for the master memif
+func setSocketPermissions(socketFile string, opts *options) error {
+	if opts.socketMode != 0 {
+		if err := os.Chmod(socketFile, opts.socketMode); err != nil {
+			return errors.Wrapf(err, "failed to change mode of %s", socketFile)
+		}
+	}
+	if opts.socketUID >= 0 || opts.socketGID >= 0 {
+		if err := os.Chown(socketFile, opts.socketUID, opts.socketGID); err != nil {
+			return errors.Wrapf(err, "failed to change owner of %s", socketFile)
+		}
+	}
+	return nil
+}
+
+func del(ctx context.Context, conn *networkservice.Connection, vppConn api.Connection) error {
+	if mechanism := memifMech.ToMechanism(conn.GetMechanism()); mechanism != nil {
+		if err := deleteMemif(ctx, vppConn); err != nil {
+			return err
+		}
+		if err := deleteMemifSocket(ctx, vppConn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getVppSocketFilename(mechanism *memifMech.Mechanism, netNS netns.NsHandle) (string, error) {
+	u, err := url.Parse(mechanism.GetNetNSURL())
+	if err != nil {
+		return "", errors.Wrapf(err, "not a valid url %s", mechanism.GetNetNSURL())
+	}
+	if u.Scheme != memifMech.FileScheme {
+		return "", errors.Errorf("socket file url must have scheme %s, actual %s", memifMech.FileScheme, u.Scheme)
+	}
+
+	targetNetNS, err := netns.GetFromPath(u.Path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get network namespace handle for %s", u.Path)
+	}
+	defer func() { _ = targetNetNS.Close() }()
+
+	if !targetNetNS.Equal(netNS) {
+		return "@netns:" + u.Path + mechanism.GetSocketFilename(), nil
+	}
+	return mechanism.GetSocketFilename(), nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memif
+
+import "os"
+
+type options struct {
+	socketDir  string
+	socketName string
+	socketMode os.FileMode
+	socketUID  int
+	socketGID  int
+}
+
+// Option is an option pattern for NewClient
+type Option func(o *options)
+
+// WithSocketDir sets the directory of the socket files created for the master memif interfaces without the
+// socket file set in the mechanism parameters
+func WithSocketDir(socketDir string) Option {
+	return func(o *options) {
+		o.socketDir = socketDir
+	}
+}
+
+// WithSocketName sets the socket file name pattern, {id} and {service} are replaced with the connection ID and
+// the Network Service name. Default is "{id}.sock".
+func WithSocketName(socketName string) Option {
+	return func(o *options) {
+		if socketName != "" {
+			o.socketName = socketName
+		}
+	}
+}
+
+// WithSocketMode sets the file mode of the socket files created for the master memif interfaces
+func WithSocketMode(socketMode os.FileMode) Option {
+	return func(o *options) {
+		o.socketMode = socketMode
+	}
+}
+
+// WithSocketOwner sets the owner of the socket files created for the master memif interfaces, -1 keeps the
+// current uid/gid
+func WithSocketOwner(uid, gid int) Option {
+	return func(o *options) {
+		o.socketUID = uid
+		o.socketGID = gid
+	}
+}
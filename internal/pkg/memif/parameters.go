@@ -0,0 +1,116 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memif
+
+import (
+	"strconv"
+
+	"github.com/networkservicemesh/govpp/binapi/memif"
+	"github.com/pkg/errors"
+)
+
+// Memif mechanism parameters configuring the created memif interface, VPP defaults are used for the missing ones
+const (
+	// RxQueuesKey - number of the rx queues
+	RxQueuesKey = "rx-queues"
+	// TxQueuesKey - number of the tx queues
+	TxQueuesKey = "tx-queues"
+	// RingSizeKey - number of the ring entries, must be a power of 2
+	RingSizeKey = "ring-size"
+	// BufferSizeKey - size of the buffer allocated for each ring entry
+	BufferSizeKey = "buffer-size"
+	// RoleKey - memif role of the NSC side: slave (default) or master
+	RoleKey = "role"
+	// SocketFileKey - path of the memif socket file used instead of the one provided by the peer
+	SocketFileKey = "socket-file"
+)
+
+// Memif roles
+const (
+	RoleMaster = "master"
+	RoleSlave  = "slave"
+)
+
+// ParameterKeys are all the memif interface parameter keys
+var ParameterKeys = []string{RxQueuesKey, TxQueuesKey, RingSizeKey, BufferSizeKey, RoleKey, SocketFileKey}
+
+type parameters struct {
+	rxQueues   uint8
+	txQueues   uint8
+	ringSize   uint32
+	bufferSize uint16
+	role       memif.MemifRole
+	socketFile string
+}
+
+// ValidateParameters returns an error if the memif mechanism parameters have invalid values
+func ValidateParameters(params map[string]string) error {
+	_, err := parseParameters(params)
+	return err
+}
+
+func parseParameters(params map[string]string) (*parameters, error) {
+	result := &parameters{
+		role:       memif.MEMIF_ROLE_API_SLAVE,
+		socketFile: params[SocketFileKey],
+	}
+	switch role := params[RoleKey]; role {
+	case "", RoleSlave:
+	case RoleMaster:
+		result.role = memif.MEMIF_ROLE_API_MASTER
+	default:
+		return nil, errors.Errorf("invalid memif %s: %s", RoleKey, role)
+	}
+
+	parse := func(key string, bitSize int) (uint64, error) {
+		value, ok := params[key]
+		if !ok {
+			return 0, nil
+		}
+		n, err := strconv.ParseUint(value, 10, bitSize)
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid memif %s", key)
+		}
+		return n, nil
+	}
+
+	rxQueues, err := parse(RxQueuesKey, 8)
+	if err != nil {
+		return nil, err
+	}
+	txQueues, err := parse(TxQueuesKey, 8)
+	if err != nil {
+		return nil, err
+	}
+	ringSize, err := parse(RingSizeKey, 32)
+	if err != nil {
+		return nil, err
+	}
+	if ringSize&(ringSize-1) != 0 {
+		return nil, errors.Errorf("invalid memif %s: %d is not a power of 2", RingSizeKey, ringSize)
+	}
+	bufferSize, err := parse(BufferSizeKey, 16)
+	if err != nil {
+		return nil, err
+	}
+
+	result.rxQueues = uint8(rxQueues)
+	result.txQueues = uint8(txQueues)
+	result.ringSize = uint32(ringSize)
+	result.bufferSize = uint16(bufferSize)
+	return result, nil
+}
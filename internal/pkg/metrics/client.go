@@ -0,0 +1,63 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+)
+
+type metricsClient struct {
+	metrics *Metrics
+}
+
+// NewClient returns a client chain element recording the Request and Close latency. A failed Request attempt is
+// recorded as a retry, so the element should be placed inside the retry client.
+func (m *Metrics) NewClient() networkservice.NetworkServiceClient {
+	return &metricsClient{metrics: m}
+}
+
+func (c *metricsClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	networkService := request.GetConnection().GetNetworkService()
+	c.metrics.networkServices.Store(request.GetConnection().GetId(), networkService)
+
+	start := time.Now()
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	c.metrics.recordDuration(ctx, c.metrics.requestDuration, networkService, start, err)
+	if err != nil && ctx.Err() == nil {
+		c.metrics.requestRetries.Add(ctx, 1, metric.WithAttributes(attribute.String(networkServiceKey, networkService)))
+	}
+	return conn, err
+}
+
+func (c *metricsClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	start := time.Now()
+	resp, err := next.Client(ctx).Close(ctx, conn, opts...)
+	c.metrics.recordDuration(ctx, c.metrics.closeDuration, conn.GetNetworkService(), start, err)
+	c.metrics.networkServices.Delete(conn.GetId())
+	c.metrics.brokenStreams.Delete(conn.GetId())
+	return resp, err
+}
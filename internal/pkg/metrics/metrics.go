@@ -0,0 +1,157 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics provides OpenTelemetry metrics of the NSC control plane operations
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+)
+
+const (
+	networkServiceKey = "network_service"
+	connectionKey     = "connection"
+	errorKey          = "error"
+)
+
+// Metrics records the NSC control plane metrics labeled by the network service:
+//   - nsc_request_duration - histogram of the Request latency
+//   - nsc_close_duration - histogram of the Close latency
+//   - nsc_heal_events - number of healed connections
+//   - nsc_request_retries - number of the Request attempts failed and retried
+//   - nsc_monitor_reconnects - number of the connection monitor streams broken while in use
+//   - nsc_monitor_restarts - number of the connection monitor streams reopened after being broken
+//   - nsc_ping_rtt - histogram of the liveness check ping RTT, additionally labeled by the connection
+//   - nsc_ping_loss - histogram of the liveness check ping loss ratio, additionally labeled by the connection
+//   - nsc_svid_rotations - number of the X.509 SVID rotations, not labeled
+type Metrics struct {
+	requestDuration   metric.Float64Histogram
+	closeDuration     metric.Float64Histogram
+	healEvents        metric.Int64Counter
+	requestRetries    metric.Int64Counter
+	monitorReconnects metric.Int64Counter
+	monitorRestarts   metric.Int64Counter
+	pingRTT           metric.Float64Histogram
+	pingLoss          metric.Float64Histogram
+	svidRotations     metric.Int64Counter
+
+	// networkServices are the network services of the requested connections by the connection IDs
+	networkServices sync.Map
+	// brokenStreams are the IDs of the connections which monitor streams are broken and not reopened yet
+	brokenStreams sync.Map
+}
+
+// New creates the NSC metrics with the global OpenTelemetry meter provider
+func New() (*Metrics, error) {
+	meter := otel.Meter("")
+	m := new(Metrics)
+
+	var err error
+	if m.requestDuration, err = meter.Float64Histogram("nsc_request_duration",
+		metric.WithDescription("Latency of the network service requests"), metric.WithUnit("s")); err != nil {
+		return nil, errors.Wrap(err, "failed to create nsc_request_duration")
+	}
+	if m.closeDuration, err = meter.Float64Histogram("nsc_close_duration",
+		metric.WithDescription("Latency of the network service connection closes"), metric.WithUnit("s")); err != nil {
+		return nil, errors.Wrap(err, "failed to create nsc_close_duration")
+	}
+	if m.healEvents, err = meter.Int64Counter("nsc_heal_events",
+		metric.WithDescription("Number of healed network service connections")); err != nil {
+		return nil, errors.Wrap(err, "failed to create nsc_heal_events")
+	}
+	if m.requestRetries, err = meter.Int64Counter("nsc_request_retries",
+		metric.WithDescription("Number of failed and retried network service requests")); err != nil {
+		return nil, errors.Wrap(err, "failed to create nsc_request_retries")
+	}
+	if m.monitorReconnects, err = meter.Int64Counter("nsc_monitor_reconnects",
+		metric.WithDescription("Number of connection monitor streams broken while in use")); err != nil {
+		return nil, errors.Wrap(err, "failed to create nsc_monitor_reconnects")
+	}
+	if m.monitorRestarts, err = meter.Int64Counter("nsc_monitor_restarts",
+		metric.WithDescription("Number of connection monitor streams reopened after being broken")); err != nil {
+		return nil, errors.Wrap(err, "failed to create nsc_monitor_restarts")
+	}
+	if m.pingRTT, err = meter.Float64Histogram("nsc_ping_rtt",
+		metric.WithDescription("RTT of the liveness check pings replied by the peer"), metric.WithUnit("s")); err != nil {
+		return nil, errors.Wrap(err, "failed to create nsc_ping_rtt")
+	}
+	if m.pingLoss, err = meter.Float64Histogram("nsc_ping_loss",
+		metric.WithDescription("Ratio of the liveness check pings not replied by the peer")); err != nil {
+		return nil, errors.Wrap(err, "failed to create nsc_ping_loss")
+	}
+	if m.svidRotations, err = meter.Int64Counter("nsc_svid_rotations",
+		metric.WithDescription("Number of the X.509 SVID rotations")); err != nil {
+		return nil, errors.Wrap(err, "failed to create nsc_svid_rotations")
+	}
+	return m, nil
+}
+
+// Heal records the heal event of the connection
+func (m *Metrics) Heal(ctx context.Context, conn *networkservice.Connection) {
+	m.healEvents.Add(ctx, 1, metric.WithAttributes(attribute.String(networkServiceKey, conn.GetNetworkService())))
+}
+
+// SVIDRotation records the X.509 SVID rotation
+func (m *Metrics) SVIDRotation(ctx context.Context) {
+	m.svidRotations.Add(ctx, 1)
+}
+
+// PingRTT records the RTT of the ping replied by the peer of the connection
+func (m *Metrics) PingRTT(ctx context.Context, conn *networkservice.Connection, rtt time.Duration) {
+	m.pingRTT.Record(ctx, rtt.Seconds(), connectionAttributes(conn))
+}
+
+// PingLoss records the loss ratio of the pings sent to the peer of the connection
+func (m *Metrics) PingLoss(ctx context.Context, conn *networkservice.Connection, sent, replied int) {
+	if sent <= 0 {
+		return
+	}
+	loss := float64(sent-replied) / float64(sent)
+	if loss < 0 {
+		loss = 0
+	}
+	m.pingLoss.Record(ctx, loss, connectionAttributes(conn))
+}
+
+func connectionAttributes(conn *networkservice.Connection) metric.MeasurementOption {
+	return metric.WithAttributes(
+		attribute.String(networkServiceKey, conn.GetNetworkService()),
+		attribute.String(connectionKey, conn.GetId()),
+	)
+}
+
+func (m *Metrics) recordDuration(ctx context.Context, histogram metric.Float64Histogram, networkService string, start time.Time, err error) {
+	histogram.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+		attribute.String(networkServiceKey, networkService),
+		attribute.Bool(errorKey, err != nil),
+	))
+}
+
+func (m *Metrics) networkService(id string) string {
+	if networkService, ok := m.networkServices.Load(id); ok {
+		return networkService.(string)
+	}
+	return ""
+}
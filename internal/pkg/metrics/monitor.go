@@ -0,0 +1,79 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+)
+
+const monitorConnectionsMethod = "/connection.MonitorConnection/MonitorConnections"
+
+// StreamClientInterceptor returns an interceptor recording the connection monitor streams broken while still in
+// use, and the streams reopened after that by the monitoring client
+func (m *Metrics) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil || method != monitorConnectionsMethod {
+			return stream, err
+		}
+		return &monitorStream{ClientStream: stream, ctx: ctx, metrics: m}, nil
+	}
+}
+
+type monitorStream struct {
+	grpc.ClientStream
+	ctx     context.Context
+	metrics *Metrics
+
+	id             string
+	networkService string
+	once           sync.Once
+}
+
+func (s *monitorStream) SendMsg(msg interface{}) error {
+	if selector, ok := msg.(*networkservice.MonitorScopeSelector); ok && len(selector.GetPathSegments()) > 0 {
+		s.id = selector.GetPathSegments()[0].GetId()
+		s.networkService = s.metrics.networkService(s.id)
+	}
+	err := s.ClientStream.SendMsg(msg)
+	if err == nil && s.id != "" {
+		if _, broken := s.metrics.brokenStreams.LoadAndDelete(s.id); broken {
+			s.metrics.monitorRestarts.Add(s.ctx, 1, metric.WithAttributes(attribute.String(networkServiceKey, s.networkService)))
+		}
+	}
+	return err
+}
+
+func (s *monitorStream) RecvMsg(msg interface{}) error {
+	err := s.ClientStream.RecvMsg(msg)
+	if err != nil && s.ctx.Err() == nil {
+		s.once.Do(func() {
+			s.metrics.monitorReconnects.Add(s.ctx, 1, metric.WithAttributes(attribute.String(networkServiceKey, s.networkService)))
+			if s.id != "" {
+				s.metrics.brokenStreams.Store(s.id, struct{}{})
+			}
+		})
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+// Prometheus serves the metrics in the Prometheus format
+type Prometheus struct {
+	http.Handler
+	provider *sdkmetric.MeterProvider
+}
+
+// NewPrometheus sets the global meter provider exporting all the metrics to Prometheus instead of OTLP
+func NewPrometheus(ctx context.Context, service string) (*Prometheus, error) {
+	exporter, err := otelprometheus.New()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Prometheus exporter")
+	}
+	res, err := resource.New(ctx, resource.WithAttributes(semconv.ServiceNameKey.String(service)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create metrics resource")
+	}
+
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(exporter),
+	)
+	otel.SetMeterProvider(provider)
+
+	return &Prometheus{
+		Handler:  promhttp.Handler(),
+		provider: provider,
+	}, nil
+}
+
+// Close shuts down the meter provider
+func (p *Prometheus) Close(ctx context.Context) error {
+	return errors.Wrap(p.provider.Shutdown(ctx), "failed to shutdown meter provider")
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mirror mirrors the traffic of the connection interfaces to the local VPP interfaces read by the external
+// analyzers, so the production flows are troubleshot without changing them
+package mirror
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/span"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/attach"
+)
+
+// Types of the mirror target interface
+const (
+	MemifTarget = "memif"
+	TapTarget   = "tap"
+)
+
+// maxTapName is the longest kernel interface name
+const maxTapName = 15
+
+// directions are the mirrored directions of the connection interface traffic
+var directions = map[string]span.SpanState{
+	"":     span.SPAN_STATE_API_RX_TX,
+	"both": span.SPAN_STATE_API_RX_TX,
+	"rx":   span.SPAN_STATE_API_RX,
+	"tx":   span.SPAN_STATE_API_TX,
+}
+
+// IfIndexFunc returns the VPP interface index of the connection with the ID
+type IfIndexFunc func(id string) (uint32, bool)
+
+// session is the mirror of a connection
+type session struct {
+	target attach.Interface
+	from   interface_types.InterfaceIndex
+	to     interface_types.InterfaceIndex
+}
+
+// Mirror runs a single mirror per connection. The mirror is bound to the interface of the connection at the start,
+// so it is started again after the connection is reselected.
+type Mirror struct {
+	vppConn   api.Connection
+	ifIndex   IfIndexFunc
+	socketDir string
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// New creates a Mirror looking up the connection interfaces with ifIndex. The memif targets listen on the sockets
+// in socketDir, they are disabled if it is empty.
+func New(vppConn api.Connection, ifIndex IfIndexFunc, socketDir string) *Mirror {
+	return &Mirror{
+		vppConn:   vppConn,
+		ifIndex:   ifIndex,
+		socketDir: socketDir,
+		sessions:  make(map[string]*session),
+	}
+}
+
+// Start mirrors the traffic of the connection with the ID in the direction: rx, tx or both by default, to a new
+// interface of the targetType named name: the memif with the socketDir/name.sock socket or the tap in the VPP
+// network namespace. It returns the description of the target interface.
+func (m *Mirror) Start(ctx context.Context, id, targetType, name, direction string) (string, error) {
+	state, ok := directions[strings.ToLower(direction)]
+	if !ok {
+		return "", errors.Errorf("invalid direction %q", direction)
+	}
+	target, err := m.newTarget(targetType, name)
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s, ok := m.sessions[id]; ok {
+		return "", errors.Errorf("traffic of connection %s is already mirrored to %s", id, s.target.String())
+	}
+	from, ok := m.ifIndex(id)
+	if !ok {
+		return "", errors.Errorf("no interface found for connection %s", id)
+	}
+
+	to, err := target.Create(ctx, m.vppConn)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create %s", target.String())
+	}
+	s := &session{
+		target: target,
+		from:   interface_types.InterfaceIndex(from),
+		to:     to,
+	}
+	if err = spanEnableDisable(ctx, m.vppConn, s.from, s.to, state); err != nil {
+		if deleteErr := target.Delete(ctx, m.vppConn, to); deleteErr != nil {
+			log.FromContext(ctx).Warnf("failed to delete %s: %s", target.String(), deleteErr.Error())
+		}
+		return "", err
+	}
+	m.sessions[id] = s
+
+	log.FromContext(ctx).Infof("traffic of connection %s is mirrored to %s", id, target.String())
+	return target.String(), nil
+}
+
+// Stop stops mirroring the traffic of the connection with the ID and deletes the target interface
+func (m *Mirror) Stop(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[id]
+	if !ok {
+		return errors.Errorf("traffic of connection %s is not mirrored", id)
+	}
+	delete(m.sessions, id)
+
+	// The connection interface may be already deleted, so the target is deleted anyway
+	if err := spanEnableDisable(ctx, m.vppConn, s.from, s.to, span.SPAN_STATE_API_DISABLED); err != nil {
+		log.FromContext(ctx).Warnf("failed to disable mirror of connection %s: %s", id, err.Error())
+	}
+	if err := s.target.Delete(ctx, m.vppConn, s.to); err != nil {
+		return errors.Wrapf(err, "failed to delete %s", s.target.String())
+	}
+
+	log.FromContext(ctx).Infof("traffic of connection %s is not mirrored anymore", id)
+	return nil
+}
+
+func (m *Mirror) newTarget(targetType, name string) (attach.Interface, error) {
+	if name == "" || strings.ContainsAny(name, "/ ") {
+		return nil, errors.Errorf("invalid target name %q", name)
+	}
+	switch targetType {
+	case MemifTarget:
+		if m.socketDir == "" {
+			return nil, errors.New("memif mirrors are disabled")
+		}
+		return attach.NewMemif(filepath.Join(m.socketDir, name+".sock")), nil
+	case TapTarget:
+		if len(name) > maxTapName {
+			return nil, errors.Errorf("tap name %q is longer than %d characters", name, maxTapName)
+		}
+		return attach.NewTap(name), nil
+	default:
+		return nil, errors.Errorf("invalid target type %q", targetType)
+	}
+}
+
+func spanEnableDisable(ctx context.Context, vppConn api.Connection, from, to interface_types.InterfaceIndex, state span.SpanState) error {
+	now := time.Now()
+	if _, err := span.NewServiceClient(vppConn).SwInterfaceSpanEnableDisable(ctx, &span.SwInterfaceSpanEnableDisable{
+		SwIfIndexFrom: from,
+		SwIfIndexTo:   to,
+		State:         state,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi SwInterfaceSpanEnableDisable returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndexFrom", from).
+		WithField("swIfIndexTo", to).
+		WithField("state", state).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "SwInterfaceSpanEnableDisable").Debug("completed")
+	return nil
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mtu provides a client chain element overriding the MTU of the connection interfaces and clamping the
+// TCP MSS to it
+package mtu
+
+import (
+	"context"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/mss_clamp"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/postpone"
+)
+
+// IPv4 and IPv6 headers with the TCP header without options
+const (
+	ipv4TCPHeaders = 40
+	ipv6TCPHeaders = 60
+)
+
+// Config is the MTU config of a connection
+type Config struct {
+	// MTU overrides the MTU of the connection context if not 0
+	MTU uint32
+	// MSSClamp enables the TCP MSS clamping to the MTU on the connection interface
+	MSSClamp bool
+}
+
+type contextKey struct{}
+
+// WithConfig returns a context requesting the connection with the MTU config
+func WithConfig(ctx context.Context, config *Config) context.Context {
+	return context.WithValue(ctx, contextKey{}, config)
+}
+
+// clamped is the state of the MSS clamping of a connection
+type clamped struct {
+	config    *Config
+	swIfIndex interface_types.InterfaceIndex
+	mtu       uint32
+}
+
+type metadataKey struct{}
+
+type mtuClient struct {
+	vppConn  api.Connection
+	mssClamp bool
+}
+
+// NewClient returns a client chain element applying the MTU config requested with WithConfig. If mssClamp is set,
+// the TCP MSS is clamped on all the connection interfaces. It should be placed after the connection context
+// clients and before the mechanism clients, so the overridden MTU is set on the interface.
+func NewClient(vppConn api.Connection, mssClamp bool) networkservice.NetworkServiceClient {
+	return &mtuClient{
+		vppConn:  vppConn,
+		mssClamp: mssClamp,
+	}
+}
+
+func (c *mtuClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	s, loaded := load(ctx)
+	if !loaded {
+		config, _ := ctx.Value(contextKey{}).(*Config)
+		if config == nil {
+			config = new(Config)
+		}
+		s = &clamped{
+			config: &Config{
+				MTU:      config.MTU,
+				MSSClamp: config.MSSClamp || c.mssClamp,
+			},
+		}
+		store(ctx, s)
+	}
+	if s.config.MTU != 0 {
+		if request.GetConnection().GetContext() == nil {
+			request.GetConnection().Context = &networkservice.ConnectionContext{}
+		}
+		request.GetConnection().GetContext().MTU = s.config.MTU
+	}
+
+	postponeCtxFunc := postpone.ContextWithValues(ctx)
+
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		if !loaded {
+			metadata.Map(ctx, true).Delete(metadataKey{})
+		}
+		return nil, err
+	}
+
+	// The MTU of the interface is set by the connection context client from the response
+	if s.config.MTU != 0 && conn.GetContext() != nil {
+		conn.GetContext().MTU = s.config.MTU
+	}
+
+	if err := c.clamp(ctx, s, conn.GetContext().GetMTU()); err != nil {
+		closeCtx, cancelClose := postponeCtxFunc()
+		defer cancelClose()
+
+		if _, closeErr := c.Close(closeCtx, conn, opts...); closeErr != nil {
+			err = errors.Wrapf(err, "connection closed with error: %s", closeErr.Error())
+		}
+
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (c *mtuClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	if rawValue, ok := metadata.Map(ctx, true).LoadAndDelete(metadataKey{}); ok {
+		if s, ok := rawValue.(*clamped); ok && s.swIfIndex != 0 {
+			if err := mssClampEnableDisable(ctx, c.vppConn, s.swIfIndex, 0, false); err != nil {
+				log.FromContext(ctx).Warnf("failed to disable TCP MSS clamping: %s", err.Error())
+			}
+		}
+	}
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
+
+func (c *mtuClient) clamp(ctx context.Context, s *clamped, mtu uint32) error {
+	if !s.config.MSSClamp || mtu <= ipv6TCPHeaders {
+		return nil
+	}
+	swIfIndex, ok := ifindex.Load(ctx, true)
+	if !ok || (swIfIndex == s.swIfIndex && mtu == s.mtu) {
+		return nil
+	}
+	if err := mssClampEnableDisable(ctx, c.vppConn, swIfIndex, mtu, true); err != nil {
+		return err
+	}
+	s.swIfIndex = swIfIndex
+	s.mtu = mtu
+	return nil
+}
+
+func mssClampEnableDisable(ctx context.Context, vppConn api.Connection, swIfIndex interface_types.InterfaceIndex, mtu uint32, enable bool) error {
+	req := &mss_clamp.MssClampEnableDisable{
+		SwIfIndex:     swIfIndex,
+		IPv4Direction: mss_clamp.MSS_CLAMP_DIR_NONE,
+		IPv6Direction: mss_clamp.MSS_CLAMP_DIR_NONE,
+	}
+	if enable {
+		req.IPv4Mss = uint16(mtu - ipv4TCPHeaders)
+		req.IPv6Mss = uint16(mtu - ipv6TCPHeaders)
+		req.IPv4Direction = mss_clamp.MSS_CLAMP_DIR_RX | mss_clamp.MSS_CLAMP_DIR_TX
+		req.IPv6Direction = mss_clamp.MSS_CLAMP_DIR_RX | mss_clamp.MSS_CLAMP_DIR_TX
+	}
+
+	now := time.Now()
+	if _, err := mss_clamp.NewServiceClient(vppConn).MssClampEnableDisable(ctx, req); err != nil {
+		return errors.Wrap(err, "vppapi MssClampEnableDisable returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", swIfIndex).
+		WithField("ipv4Mss", req.IPv4Mss).
+		WithField("ipv6Mss", req.IPv6Mss).
+		WithField("enable", enable).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "MssClampEnableDisable").Debug("completed")
+	return nil
+}
+
+func store(ctx context.Context, s *clamped) {
+	metadata.Map(ctx, true).Store(metadataKey{}, s)
+}
+
+func load(ctx context.Context) (*clamped, bool) {
+	rawValue, ok := metadata.Map(ctx, true).Load(metadataKey{})
+	if !ok {
+		return nil, false
+	}
+	s, ok := rawValue.(*clamped)
+	return s, ok
+}
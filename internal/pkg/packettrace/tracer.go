@@ -0,0 +1,104 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package packettrace runs VPP packet traces on the input nodes of the connections
+package packettrace
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/vppcli"
+)
+
+// inputNodes are the VPP input nodes receiving the packets of the mechanisms
+var inputNodes = map[string]string{
+	memif.MECHANISM:  "memif-input",
+	kernel.MECHANISM: "virtio-input",
+}
+
+// MechanismFunc returns the mechanism type of the connection with the ID
+type MechanismFunc func(id string) (string, bool)
+
+// Tracer runs a single VPP packet trace at a time. VPP traces all the packets passing through the input node, so the
+// trace of a connection includes the packets of the other connections with the same mechanism.
+type Tracer struct {
+	vppConn   api.Connection
+	mechanism MechanismFunc
+
+	mu      sync.Mutex
+	packets uint32
+}
+
+// New creates a Tracer looking up the connection mechanisms with mechanism
+func New(vppConn api.Connection, mechanism MechanismFunc) *Tracer {
+	return &Tracer{
+		vppConn:   vppConn,
+		mechanism: mechanism,
+	}
+}
+
+// Start clears the previous trace and traces up to packets packets on the input node of the connection with the ID.
+// The node is selected by the connection mechanism if empty, e.g. memif-input for memif.
+func (t *Tracer) Start(ctx context.Context, id, node string, packets uint32) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if node == "" {
+		mechanism, ok := t.mechanism(id)
+		if !ok {
+			return errors.Errorf("connection %s not found", id)
+		}
+		if node, ok = inputNodes[mechanism]; !ok {
+			return errors.Errorf("no input node known for %s mechanism of connection %s", mechanism, id)
+		}
+	}
+
+	if err := vppcli.Exec(ctx, t.vppConn, "clear trace"); err != nil {
+		return err
+	}
+	if err := vppcli.Exec(ctx, t.vppConn, fmt.Sprintf("trace add %s %d", node, packets)); err != nil {
+		return err
+	}
+	t.packets = packets
+	return nil
+}
+
+// Stop stops and clears the trace
+func (t *Tracer) Stop(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return vppcli.Exec(ctx, t.vppConn, "clear trace")
+}
+
+// Dump returns the traced packets
+func (t *Tracer) Dump(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.packets == 0 {
+		return "", errors.New("packet trace is not started")
+	}
+	return vppcli.Run(ctx, t.vppConn, fmt.Sprintf("show trace max %d", t.packets))
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pcap captures the traffic of the VPP interfaces into pcap files
+package pcap
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/pkg/errors"
+
+	interfaces "github.com/networkservicemesh/govpp/binapi/interface"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/vppcli"
+)
+
+// vppDir is the directory VPP writes the pcap files to
+const vppDir = "/tmp"
+
+// Capturer runs the VPP pcap captures one at a time, as VPP supports only a single pcap trace
+type Capturer struct {
+	vppConn api.Connection
+	dir     string
+
+	mu sync.Mutex
+}
+
+// New creates a Capturer writing the pcap files to dir
+func New(vppConn api.Connection, dir string) *Capturer {
+	return &Capturer{
+		vppConn: vppConn,
+		dir:     dir,
+	}
+}
+
+// Capture captures up to maxPackets received and transmitted packets of the VPP interface ifIndex for the duration
+// and returns the path of the pcap file. The file name starts with the name.
+func (c *Capturer) Capture(ctx context.Context, name string, ifIndex uint32, maxPackets uint32, duration time.Duration) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ifName, err := c.interfaceName(ctx, ifIndex)
+	if err != nil {
+		return "", err
+	}
+
+	fileName := fmt.Sprintf("%s-%s.pcap", strings.NewReplacer("/", "-", " ", "-").Replace(name), time.Now().UTC().Format("20060102T150405Z"))
+	vppFile := filepath.Join(vppDir, fileName)
+	if err = vppcli.Exec(ctx, c.vppConn, fmt.Sprintf("pcap trace rx tx max %d intfc %s file %s", maxPackets, ifName, fileName)); err != nil {
+		return "", err
+	}
+	log.FromContext(ctx).Infof("capturing %d packets of %s for %s", maxPackets, ifName, duration)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(duration):
+	}
+
+	// The capture is stopped by VPP itself after maxPackets, so the reply is ignored
+	offCtx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	_, _ = vppcli.Run(offCtx, c.vppConn, "pcap trace off")
+
+	if _, err = os.Stat(vppFile); err != nil {
+		return "", errors.Wrapf(err, "no packets captured on %s", ifName)
+	}
+	defer func() { _ = os.Remove(vppFile) }()
+
+	path := filepath.Join(c.dir, fileName)
+	if err = copyFile(vppFile, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func (c *Capturer) interfaceName(ctx context.Context, ifIndex uint32) (string, error) {
+	stream, err := interfaces.NewServiceClient(c.vppConn).SwInterfaceDump(ctx, &interfaces.SwInterfaceDump{
+		SwIfIndex: interface_types.InterfaceIndex(ifIndex),
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to dump VPP interface %d", ifIndex)
+	}
+	details, err := stream.Recv()
+	if err == io.EOF {
+		return "", errors.Errorf("VPP interface %d not found", ifIndex)
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to dump VPP interface %d", ifIndex)
+	}
+	for err == nil {
+		_, err = stream.Recv()
+	}
+	return details.InterfaceName, nil
+}
+
+// copyFile copies the file, as the target directory is usually a mounted volume and can't be renamed to
+func copyFile(from, to string) error {
+	src, err := os.Open(filepath.Clean(from))
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", from)
+	}
+	defer func() { _ = src.Close() }()
+
+	if err = os.MkdirAll(filepath.Dir(to), 0o750); err != nil {
+		return errors.Wrapf(err, "failed to create directory for %s", to)
+	}
+	dst, err := os.OpenFile(filepath.Clean(to), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o640)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", to)
+	}
+	if _, err = io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return errors.Wrapf(err, "failed to write %s", to)
+	}
+	return errors.Wrapf(dst.Close(), "failed to write %s", to)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package policy provides a client chain element checking the received connections with the Rego policies
+package policy
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/opa"
+	"github.com/networkservicemesh/sdk/pkg/tools/postpone"
+)
+
+// input is the policy input: the connection with the path segments and the index at the root, so the NSM path
+// policies can be used as is
+type input struct {
+	*networkservice.Connection
+	PathSegments []*networkservice.PathSegment `json:"path_segments,omitempty"`
+	Index        uint32                        `json:"index"`
+}
+
+type policyClient struct {
+	policies []*opa.AuthorizationPolicy
+}
+
+// NewClient returns a client chain element checking the received connections with the Rego policies found by the
+// policyPaths file masks. The policies should set the valid rule for the input being the connection: the path,
+// the labels, the connection context and the other connection fields. The connection not satisfying any of the
+// policies is closed and the request fails.
+func NewClient(policyPaths ...string) (networkservice.NetworkServiceClient, error) {
+	policies, err := opa.PoliciesByFileMask(policyPaths...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read policies")
+	}
+	return &policyClient{
+		policies: policies,
+	}, nil
+}
+
+func (c *policyClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	var p peer.Peer
+	opts = append(opts, grpc.Peer(&p))
+
+	postponeCtxFunc := postpone.ContextWithValues(ctx)
+
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	checkCtx := ctx
+	if p != (peer.Peer{}) {
+		checkCtx = peer.NewContext(ctx, &p)
+	}
+	if err = c.check(checkCtx, conn); err != nil {
+		closeCtx, cancelClose := postponeCtxFunc()
+		defer cancelClose()
+
+		if _, closeErr := next.Client(ctx).Close(closeCtx, conn, opts...); closeErr != nil {
+			err = errors.Wrapf(err, "connection closed with error: %s", closeErr.Error())
+		}
+
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (c *policyClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
+
+func (c *policyClient) check(ctx context.Context, conn *networkservice.Connection) error {
+	in := &input{
+		Connection:   conn,
+		PathSegments: conn.GetPath().GetPathSegments(),
+		Index:        conn.GetPath().GetIndex(),
+	}
+	for _, policy := range c.policies {
+		if err := policy.Check(ctx, in); err != nil {
+			log.FromContext(ctx).Errorf("policy failed: %v", policy.Name())
+			return errors.Wrapf(err, "connection %s doesn't satisfy policy %s", conn.GetId(), policy.Name())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package probes provides HTTP endpoints for Kubernetes liveness, readiness and startup probes
+package probes
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const checkTimeout = time.Second * 5
+
+// Check returns an error if the checked subsystem is not healthy
+type Check func(ctx context.Context) error
+
+// Probes serves /healthz, /readyz and /startupz endpoints
+type Probes struct {
+	started   atomic.Bool
+	liveness  []Check
+	readiness []Check
+}
+
+// Option is an option pattern for New
+type Option func(p *Probes)
+
+// WithLivenessChecks adds checks for the /healthz endpoint
+func WithLivenessChecks(checks ...Check) Option {
+	return func(p *Probes) {
+		p.liveness = append(p.liveness, checks...)
+	}
+}
+
+// WithReadinessChecks adds checks for the /readyz endpoint
+func WithReadinessChecks(checks ...Check) Option {
+	return func(p *Probes) {
+		p.readiness = append(p.readiness, checks...)
+	}
+}
+
+// New creates new Probes
+func New(opts ...Option) *Probes {
+	p := new(Probes)
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// SetStarted marks the application as started, /startupz and /readyz fail until it is called
+func (p *Probes) SetStarted() {
+	p.started.Store(true)
+}
+
+// Register registers the probes endpoints on mux
+func (p *Probes) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		serveChecks(w, r, p.liveness)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		serveChecks(w, r, append([]Check{p.startupCheck}, p.readiness...))
+	})
+	mux.HandleFunc("/startupz", func(w http.ResponseWriter, r *http.Request) {
+		serveChecks(w, r, []Check{p.startupCheck})
+	})
+}
+
+func (p *Probes) startupCheck(_ context.Context) error {
+	if !p.started.Load() {
+		return errors.New("not started yet")
+	}
+	return nil
+}
+
+func serveChecks(w http.ResponseWriter, r *http.Request, checks []Check) {
+	ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+	defer cancel()
+
+	for _, check := range checks {
+		if err := check(ctx); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+	}
+	_, _ = w.Write([]byte("ok"))
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probes
+
+import (
+	"context"
+	"io"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/pkg/errors"
+
+	interfaces "github.com/networkservicemesh/govpp/binapi/interface"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/memclnt"
+)
+
+// VPPAlive returns a check failing if VPP doesn't reply to the control ping
+func VPPAlive(vppConn api.Connection) Check {
+	return func(ctx context.Context) error {
+		if _, err := memclnt.NewServiceClient(vppConn).ControlPing(ctx, &memclnt.ControlPing{}); err != nil {
+			return errors.Wrap(err, "VPP API is not responding")
+		}
+		return nil
+	}
+}
+
+// InterfacesUp returns a check failing if any of the VPP interfaces returned by ifIndexes is not admin and link up
+func InterfacesUp(vppConn api.Connection, ifIndexes func() []uint32) Check {
+	return func(ctx context.Context) error {
+		for _, ifIndex := range ifIndexes() {
+			stream, err := interfaces.NewServiceClient(vppConn).SwInterfaceDump(ctx, &interfaces.SwInterfaceDump{
+				SwIfIndex: interface_types.InterfaceIndex(ifIndex),
+			})
+			if err != nil {
+				return errors.Wrapf(err, "failed to dump VPP interface %d", ifIndex)
+			}
+			details, err := stream.Recv()
+			if err == io.EOF {
+				return errors.Errorf("VPP interface %d not found", ifIndex)
+			}
+			if err != nil {
+				return errors.Wrapf(err, "failed to dump VPP interface %d", ifIndex)
+			}
+			for err == nil {
+				_, err = stream.Recv()
+			}
+
+			upFlags := interface_types.IF_STATUS_API_FLAG_ADMIN_UP | interface_types.IF_STATUS_API_FLAG_LINK_UP
+			if details.Flags&upFlags != upFlags {
+				return errors.Errorf("VPP interface %d %s is down", ifIndex, details.InterfaceName)
+			}
+		}
+		return nil
+	}
+}
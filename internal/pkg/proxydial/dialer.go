@@ -0,0 +1,177 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package proxydial provides the gRPC dialer connecting to the TCP targets through the HTTP, HTTPS or SOCKS5 proxy
+package proxydial
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/proxy"
+)
+
+const unixPrefix = "unix:"
+
+// DialFunc is the gRPC context dialer
+type DialFunc func(ctx context.Context, addr string) (net.Conn, error)
+
+// New returns the gRPC context dialer connecting to the TCP targets through the proxy at proxyURL:
+//   - http://[user:password@]host:port - HTTP CONNECT
+//   - https://[user:password@]host:port - HTTP CONNECT over TLS to the proxy
+//   - socks5://[user:password@]host:port - SOCKS5
+//
+// The hosts, domains, IPs and CIDRs in the comma separated noProxy list are dialed directly, as well as the unix
+// socket targets.
+func New(proxyURL *url.URL, noProxy string) (DialFunc, error) {
+	direct := &net.Dialer{}
+
+	var proxyDialer proxy.Dialer
+	switch proxyURL.Scheme {
+	case "http", "https":
+		proxyDialer = &connectDialer{
+			proxyURL: proxyURL,
+			forward:  direct,
+		}
+	case "socks5":
+		var auth *proxy.Auth
+		if proxyURL.User != nil {
+			password, _ := proxyURL.User.Password()
+			auth = &proxy.Auth{
+				User:     proxyURL.User.Username(),
+				Password: password,
+			}
+		}
+		d, err := proxy.SOCKS5("tcp", proxyURL.Host, auth, direct)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create SOCKS5 dialer for %s", proxyURL.Redacted())
+		}
+		proxyDialer = d
+	default:
+		return nil, errors.Errorf("unsupported proxy scheme: %s", proxyURL.Scheme)
+	}
+
+	perHost := proxy.NewPerHost(proxyDialer, direct)
+	perHost.AddFromString(noProxy)
+
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		if strings.HasPrefix(addr, unixPrefix) {
+			path := strings.TrimPrefix(strings.TrimPrefix(addr, unixPrefix), "//")
+			return direct.DialContext(ctx, "unix", path)
+		}
+		return perHost.DialContext(ctx, "tcp", addr)
+	}, nil
+}
+
+// connectDialer tunnels the connections with the HTTP CONNECT method
+type connectDialer struct {
+	proxyURL *url.URL
+	forward  *net.Dialer
+}
+
+func (d *connectDialer) Dial(network, addr string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, addr)
+}
+
+func (d *connectDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := d.forward.DialContext(ctx, network, hostPort(d.proxyURL))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to dial proxy %s", d.proxyURL.Redacted())
+	}
+	if d.proxyURL.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{
+			ServerName: d.proxyURL.Hostname(),
+			MinVersion: tls.VersionTLS12,
+		})
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, errors.Wrapf(err, "TLS handshake with proxy %s has failed", d.proxyURL.Redacted())
+		}
+		conn = tlsConn
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	result, err := d.connect(conn, addr)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return result, nil
+}
+
+func (d *connectDialer) connect(conn net.Conn, addr string) (net.Conn, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if d.proxyURL.User != nil {
+		password, _ := d.proxyURL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(d.proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, errors.Wrapf(err, "failed to send CONNECT %s to proxy %s", addr, d.proxyURL.Redacted())
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read CONNECT %s response from proxy %s", addr, d.proxyURL.Redacted())
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("proxy %s has refused CONNECT %s: %s", d.proxyURL.Redacted(), addr, resp.Status)
+	}
+
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn returns the data read from the connection with the CONNECT response first
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// hostPort returns the proxy address with the default port of the scheme if not set
+func hostPort(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	if u.Scheme == "https" {
+		return net.JoinHostPort(u.Hostname(), "443")
+	}
+	return net.JoinHostPort(u.Hostname(), "80")
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxydial_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/proxydial"
+)
+
+// listen starts serving the connections accepted on the listener until the test is done
+func listen(t *testing.T, network, address string, serve func(conn net.Conn)) net.Listener {
+	l, err := net.Listen(network, address)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				serve(conn)
+			}()
+		}
+	}()
+	return l
+}
+
+// echo writes the received data back
+func echo(conn net.Conn) {
+	_, _ = io.Copy(conn, conn)
+}
+
+// connectProxy is the HTTP CONNECT proxy counting the tunnels, it requires the credentials if set
+type connectProxy struct {
+	credentials string
+	tunnels     int32
+}
+
+func (p *connectProxy) serve(conn net.Conn) {
+	req, err := http.ReadRequest(bufio.NewReader(conn))
+	if err != nil || req.Method != http.MethodConnect {
+		return
+	}
+	if p.credentials != "" && req.Header.Get("Proxy-Authorization") != "Basic "+base64.StdEncoding.EncodeToString([]byte(p.credentials)) {
+		_, _ = io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+		return
+	}
+	target, err := net.Dial("tcp", req.Host)
+	if err != nil {
+		_, _ = io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+		return
+	}
+	defer func() { _ = target.Close() }()
+
+	atomic.AddInt32(&p.tunnels, 1)
+	_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection Established\r\n\r\n")
+	go func() { _, _ = io.Copy(target, conn) }()
+	_, _ = io.Copy(conn, target)
+}
+
+func TestNew(t *testing.T) {
+	target := listen(t, "tcp", "127.0.0.1:0", echo)
+	socket := filepath.Join(t.TempDir(), "nsm.io.sock")
+	listen(t, "unix", socket, echo)
+
+	for _, tc := range []struct {
+		name        string
+		scheme      string
+		user        *url.Userinfo
+		credentials string
+		noProxy     string
+		addr        string
+		tunneled    bool
+		err         bool
+		dialErr     bool
+	}{
+		{
+			name:     "http",
+			scheme:   "http",
+			addr:     target.Addr().String(),
+			tunneled: true,
+		},
+		{
+			name:        "http with credentials",
+			scheme:      "http",
+			user:        url.UserPassword("user", "password"),
+			credentials: "user:password",
+			addr:        target.Addr().String(),
+			tunneled:    true,
+		},
+		{
+			name:        "http with wrong credentials",
+			scheme:      "http",
+			user:        url.UserPassword("user", "wrong"),
+			credentials: "user:password",
+			addr:        target.Addr().String(),
+			dialErr:     true,
+		},
+		{
+			name:    "no proxy",
+			scheme:  "http",
+			noProxy: "localhost,127.0.0.0/8",
+			addr:    target.Addr().String(),
+		},
+		{
+			name:   "unix",
+			scheme: "http",
+			addr:   "unix://" + socket,
+		},
+		{
+			name:   "socks5",
+			scheme: "socks5",
+		},
+		{
+			name:   "unsupported scheme",
+			scheme: "ftp",
+			err:    true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			p := &connectProxy{credentials: tc.credentials}
+			proxyURL := &url.URL{
+				Scheme: tc.scheme,
+				User:   tc.user,
+				Host:   listen(t, "tcp", "127.0.0.1:0", p.serve).Addr().String(),
+			}
+
+			dial, err := proxydial.New(proxyURL, tc.noProxy)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tc.addr == "" {
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			conn, err := dial(ctx, tc.addr)
+			if tc.dialErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer func() { _ = conn.Close() }()
+
+			_, err = io.WriteString(conn, "ping")
+			require.NoError(t, err)
+			reply := make([]byte, 4)
+			_, err = io.ReadFull(conn, reply)
+			require.NoError(t, err)
+			require.Equal(t, "ping", string(reply))
+
+			require.Equal(t, tc.tunneled, atomic.LoadInt32(&p.tunnels) == 1)
+		})
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package qos provides a client chain element policing the ingress and limiting the egress traffic rate of the
+// connection interfaces in VPP and marking the DSCP of the egress traffic
+package qos
+
+import (
+	"context"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/policer"
+	"github.com/networkservicemesh/govpp/binapi/policer_types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/postpone"
+)
+
+// Directions of the policers
+const (
+	ingress = "in"
+	egress  = "out"
+)
+
+type metadataKey struct{}
+
+// applied is the state of the QoS config of a connection
+type applied struct {
+	swIfIndex interface_types.InterfaceIndex
+	// names of the created policers by the direction
+	names map[string]string
+	// dscp is the DSCP of the acquired egress map if not nil
+	dscp *uint8
+}
+
+// binding applies the QoS feature to the interface or removes it
+type binding struct {
+	name  string
+	apply func(ctx context.Context, swIfIndex interface_types.InterfaceIndex, apply bool) error
+}
+
+type qosClient struct {
+	vppConn api.Connection
+	maps    *egressMaps
+}
+
+// NewClient returns a client chain element applying the QoS config requested with WithConfig. The ingress traffic
+// exceeding the rate is dropped by the input policer, the egress one by the output policer, the burst absorbs the
+// peaks. The DSCP of the egress IP packets is rewritten. It should be placed before the mechanism client to see the
+// interface index.
+func NewClient(vppConn api.Connection) networkservice.NetworkServiceClient {
+	return &qosClient{
+		vppConn: vppConn,
+		maps:    newEgressMaps(vppConn),
+	}
+}
+
+func (c *qosClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	postponeCtxFunc := postpone.ContextWithValues(ctx)
+
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.apply(ctx, conn); err != nil {
+		closeCtx, cancelClose := postponeCtxFunc()
+		defer cancelClose()
+
+		if _, closeErr := c.Close(closeCtx, conn, opts...); closeErr != nil {
+			err = errors.Wrapf(err, "connection closed with error: %s", closeErr.Error())
+		}
+
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (c *qosClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	a, ok := loadAndDelete(ctx)
+	if ok && a.swIfIndex != 0 {
+		c.unbind(ctx, a)
+	}
+
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+
+	if ok {
+		for _, name := range a.names {
+			if delErr := addDel(ctx, c.vppConn, name, nil, false); delErr != nil {
+				log.FromContext(ctx).Warnf("failed to delete policer %s: %s", name, delErr.Error())
+			}
+		}
+		if a.dscp != nil {
+			c.maps.release(ctx, *a.dscp)
+		}
+	}
+	return rv, err
+}
+
+func (c *qosClient) apply(ctx context.Context, conn *networkservice.Connection) error {
+	config := configFromContext(ctx)
+	if config == nil || (config.Ingress == nil && config.Egress == nil && config.DSCP == nil) {
+		return nil
+	}
+	swIfIndex, ok := ifindex.Load(ctx, true)
+	if !ok {
+		return nil
+	}
+
+	a, loaded := load(ctx)
+	if loaded && a.swIfIndex == swIfIndex {
+		return nil
+	}
+	if !loaded {
+		a = &applied{names: make(map[string]string)}
+		store(ctx, a)
+		for direction, limit := range map[string]*Limit{ingress: config.Ingress, egress: config.Egress} {
+			if limit == nil {
+				continue
+			}
+			name := conn.GetId() + "-" + direction
+			if err := addDel(ctx, c.vppConn, name, limit, true); err != nil {
+				return err
+			}
+			a.names[direction] = name
+		}
+		if config.DSCP != nil {
+			if err := c.maps.acquire(ctx, *config.DSCP); err != nil {
+				return err
+			}
+			a.dscp = config.DSCP
+		}
+	} else if a.swIfIndex != 0 {
+		// The NSM interface is created again on reselect, so the QoS config is moved to the new one
+		c.unbind(ctx, a)
+	}
+
+	bindings := c.bindings(a)
+	for i, b := range bindings {
+		if err := b.apply(ctx, swIfIndex, true); err != nil {
+			for _, bound := range bindings[:i] {
+				if unbindErr := bound.apply(ctx, swIfIndex, false); unbindErr != nil {
+					log.FromContext(ctx).Warnf("failed to remove %s: %s", bound.name, unbindErr.Error())
+				}
+			}
+			a.swIfIndex = 0
+			return err
+		}
+	}
+	a.swIfIndex = swIfIndex
+
+	log.FromContext(ctx).Infof("QoS config is applied to interface %d", swIfIndex)
+	return nil
+}
+
+func (c *qosClient) unbind(ctx context.Context, a *applied) {
+	for _, b := range c.bindings(a) {
+		if err := b.apply(ctx, a.swIfIndex, false); err != nil {
+			log.FromContext(ctx).Warnf("failed to remove %s: %s", b.name, err.Error())
+		}
+	}
+}
+
+// bindings returns the QoS features of the connection applied to the interface
+func (c *qosClient) bindings(a *applied) []binding {
+	var bindings []binding
+	if name, ok := a.names[ingress]; ok {
+		bindings = append(bindings, binding{
+			name: "policer " + name,
+			apply: func(ctx context.Context, swIfIndex interface_types.InterfaceIndex, apply bool) error {
+				return input(ctx, c.vppConn, name, swIfIndex, apply)
+			},
+		})
+	}
+	if name, ok := a.names[egress]; ok {
+		bindings = append(bindings, binding{
+			name: "policer " + name,
+			apply: func(ctx context.Context, swIfIndex interface_types.InterfaceIndex, apply bool) error {
+				return output(ctx, c.vppConn, name, swIfIndex, apply)
+			},
+		})
+	}
+	if a.dscp != nil {
+		dscp := *a.dscp
+		bindings = append(bindings, binding{
+			name: "DSCP marking",
+			apply: func(ctx context.Context, swIfIndex interface_types.InterfaceIndex, apply bool) error {
+				return mark(ctx, c.vppConn, dscp, swIfIndex, apply)
+			},
+		})
+	}
+	return bindings
+}
+
+// addDel adds the single rate two color policer dropping the traffic exceeding the limit or deletes it
+func addDel(ctx context.Context, vppConn api.Connection, name string, limit *Limit, isAdd bool) error {
+	req := &policer.PolicerAddDel{
+		IsAdd: isAdd,
+		Name:  name,
+	}
+	if isAdd {
+		req.Cir = limit.Rate
+		req.Cb = limit.burst()
+		req.RateType = policer_types.SSE2_QOS_RATE_API_KBPS
+		req.RoundType = policer_types.SSE2_QOS_ROUND_API_TO_CLOSEST
+		req.Type = policer_types.SSE2_QOS_POLICER_TYPE_API_1R2C
+		req.ConformAction = policer_types.Sse2QosAction{Type: policer_types.SSE2_QOS_ACTION_API_TRANSMIT}
+		req.ExceedAction = policer_types.Sse2QosAction{Type: policer_types.SSE2_QOS_ACTION_API_DROP}
+		req.ViolateAction = policer_types.Sse2QosAction{Type: policer_types.SSE2_QOS_ACTION_API_DROP}
+	}
+
+	now := time.Now()
+	if _, err := policer.NewServiceClient(vppConn).PolicerAddDel(ctx, req); err != nil {
+		return errors.Wrap(err, "vppapi PolicerAddDel returned error")
+	}
+	log.FromContext(ctx).
+		WithField("name", name).
+		WithField("cir", req.Cir).
+		WithField("cb", req.Cb).
+		WithField("isAdd", isAdd).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "PolicerAddDel").Debug("completed")
+	return nil
+}
+
+func input(ctx context.Context, vppConn api.Connection, name string, swIfIndex interface_types.InterfaceIndex, apply bool) error {
+	now := time.Now()
+	if _, err := policer.NewServiceClient(vppConn).PolicerInput(ctx, &policer.PolicerInput{
+		Name:      name,
+		SwIfIndex: swIfIndex,
+		Apply:     apply,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi PolicerInput returned error")
+	}
+	log.FromContext(ctx).
+		WithField("name", name).
+		WithField("swIfIndex", swIfIndex).
+		WithField("apply", apply).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "PolicerInput").Debug("completed")
+	return nil
+}
+
+func output(ctx context.Context, vppConn api.Connection, name string, swIfIndex interface_types.InterfaceIndex, apply bool) error {
+	now := time.Now()
+	if _, err := policer.NewServiceClient(vppConn).PolicerOutput(ctx, &policer.PolicerOutput{
+		Name:      name,
+		SwIfIndex: swIfIndex,
+		Apply:     apply,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi PolicerOutput returned error")
+	}
+	log.FromContext(ctx).
+		WithField("name", name).
+		WithField("swIfIndex", swIfIndex).
+		WithField("apply", apply).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "PolicerOutput").Debug("completed")
+	return nil
+}
+
+func store(ctx context.Context, a *applied) {
+	metadata.Map(ctx, true).Store(metadataKey{}, a)
+}
+
+func load(ctx context.Context) (*applied, bool) {
+	rawValue, ok := metadata.Map(ctx, true).Load(metadataKey{})
+	if !ok {
+		return nil, false
+	}
+	a, ok := rawValue.(*applied)
+	return a, ok
+}
+
+func loadAndDelete(ctx context.Context) (*applied, bool) {
+	rawValue, ok := metadata.Map(ctx, true).LoadAndDelete(metadataKey{})
+	if !ok {
+		return nil, false
+	}
+	a, ok := rawValue.(*applied)
+	return a, ok
+}
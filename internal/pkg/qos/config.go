@@ -0,0 +1,101 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package qos
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// defaultBurstDivisor makes the default burst the traffic sent in 100ms at the rate
+const defaultBurstDivisor = 10
+
+// minBurst is the default burst lower bound in bytes fitting a jumbo frame
+const minBurst = 9216
+
+// Limit is a rate limit of a direction
+type Limit struct {
+	// Rate is the committed rate in kbit/s
+	Rate uint32
+	// Burst is the committed burst in bytes, the default one is used if 0
+	Burst uint64
+}
+
+// burst returns the committed burst in bytes
+func (l *Limit) burst() uint64 {
+	if l.Burst != 0 {
+		return l.Burst
+	}
+	burst := uint64(l.Rate) * 1000 / 8 / defaultBurstDivisor
+	if burst < minBurst {
+		return minBurst
+	}
+	return burst
+}
+
+// Config is the QoS config of a connection
+type Config struct {
+	// Ingress polices the traffic coming from the connection if not nil
+	Ingress *Limit
+	// Egress limits the traffic sent to the connection if not nil
+	Egress *Limit
+	// DSCP rewrites the DSCP of the IP packets sent to the connection if not nil
+	DSCP *uint8
+}
+
+type contextKey struct{}
+
+// WithConfig returns a context requesting the connection with the QoS config
+func WithConfig(ctx context.Context, config *Config) context.Context {
+	return context.WithValue(ctx, contextKey{}, config)
+}
+
+func configFromContext(ctx context.Context) *Config {
+	config, _ := ctx.Value(contextKey{}).(*Config)
+	return config
+}
+
+// ParseRate parses the rate in bit/s with an optional k, M or G suffix, e.g. 500k or 10M, to kbit/s
+func ParseRate(s string) (uint32, error) {
+	value := strings.TrimSpace(s)
+	multiplier := uint64(1)
+	if n := len(value); n > 0 {
+		switch value[n-1] {
+		case 'k', 'K':
+			multiplier = 1e3
+		case 'm', 'M':
+			multiplier = 1e6
+		case 'g', 'G':
+			multiplier = 1e9
+		}
+		if multiplier != 1 {
+			value = value[:n-1]
+		}
+	}
+	rate, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, errors.Errorf("invalid rate %q", s)
+	}
+	kbps := rate * multiplier / 1000
+	if kbps == 0 || kbps > uint64(^uint32(0)) {
+		return 0, errors.Errorf("rate %q is out of range from 1k to 4T", s)
+	}
+	return uint32(kbps), nil
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package qos_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/qos"
+)
+
+func TestParseRate(t *testing.T) {
+	for _, tc := range []struct {
+		rate     string
+		expected uint32
+		err      bool
+	}{
+		{rate: "1000", expected: 1},
+		{rate: "500k", expected: 500},
+		{rate: "500K", expected: 500},
+		{rate: "10M", expected: 10000},
+		{rate: "10m", expected: 10000},
+		{rate: "2G", expected: 2000000},
+		{rate: " 100M ", expected: 100000},
+		{rate: "4000G", expected: 4000000000},
+		{rate: "", err: true},
+		{rate: "k", err: true},
+		{rate: "fast", err: true},
+		{rate: "-1M", err: true},
+		{rate: "1.5M", err: true},
+		{rate: "999", err: true},
+		{rate: "0k", err: true},
+		{rate: "5000G", err: true},
+		{rate: "10T", err: true},
+	} {
+		tc := tc
+		t.Run(tc.rate, func(t *testing.T) {
+			rate, err := qos.ParseRate(tc.rate)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, rate)
+		})
+	}
+}
+
+func TestParseDSCP(t *testing.T) {
+	for _, tc := range []struct {
+		dscp     string
+		expected uint8
+		err      bool
+	}{
+		{dscp: "0", expected: 0},
+		{dscp: "46", expected: 46},
+		{dscp: "63", expected: 63},
+		{dscp: "ef", expected: 46},
+		{dscp: "EF", expected: 46},
+		{dscp: "af41", expected: 34},
+		{dscp: "cs6", expected: 48},
+		{dscp: "be", expected: 0},
+		{dscp: "64", err: true},
+		{dscp: "-1", err: true},
+		{dscp: "af44", err: true},
+		{dscp: "", err: true},
+	} {
+		tc := tc
+		t.Run(tc.dscp, func(t *testing.T) {
+			dscp, err := qos.ParseDSCP(tc.dscp)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, dscp)
+		})
+	}
+}
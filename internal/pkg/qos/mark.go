@@ -0,0 +1,148 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package qos
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/qos"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// maxDSCP is the largest 6 bit DSCP value
+const maxDSCP = 63
+
+// dscpNames are the standard DSCP names, RFC 2474, 2597, 3246
+var dscpNames = map[string]uint8{
+	"be": 0, "cs0": 0, "cs1": 8, "cs2": 16, "cs3": 24, "cs4": 32, "cs5": 40, "cs6": 48, "cs7": 56,
+	"af11": 10, "af12": 12, "af13": 14,
+	"af21": 18, "af22": 20, "af23": 22,
+	"af31": 26, "af32": 28, "af33": 30,
+	"af41": 34, "af42": 36, "af43": 38,
+	"ef": 46,
+}
+
+// ParseDSCP parses the DSCP value from 0 to 63 or its name, e.g. ef or af41
+func ParseDSCP(s string) (uint8, error) {
+	if dscp, ok := dscpNames[strings.ToLower(s)]; ok {
+		return dscp, nil
+	}
+	dscp, err := strconv.ParseUint(s, 10, 8)
+	if err != nil || dscp > maxDSCP {
+		return 0, errors.Errorf("invalid DSCP %q", s)
+	}
+	return uint8(dscp), nil
+}
+
+// egressMaps are the VPP QoS egress maps shared by the connections with the same DSCP, the map ID is the DSCP
+type egressMaps struct {
+	vppConn api.Connection
+
+	mu   sync.Mutex
+	refs map[uint8]int
+}
+
+func newEgressMaps(vppConn api.Connection) *egressMaps {
+	return &egressMaps{
+		vppConn: vppConn,
+		refs:    make(map[uint8]int),
+	}
+}
+
+// acquire creates the egress map rewriting any value to the DSCP if it is not created yet
+func (m *egressMaps) acquire(ctx context.Context, dscp uint8) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.refs[dscp] == 0 {
+		// The IP mark writes the whole TOS/traffic class byte, so the DSCP is shifted over the ECN bits
+		egressMap := qos.QosEgressMap{ID: uint32(dscp)}
+		for i := range egressMap.Rows {
+			outputs := make([]byte, 256)
+			for j := range outputs {
+				outputs[j] = dscp << 2
+			}
+			egressMap.Rows[i].Outputs = outputs
+		}
+
+		now := time.Now()
+		if _, err := qos.NewServiceClient(m.vppConn).QosEgressMapUpdate(ctx, &qos.QosEgressMapUpdate{
+			Map: egressMap,
+		}); err != nil {
+			return errors.Wrap(err, "vppapi QosEgressMapUpdate returned error")
+		}
+		log.FromContext(ctx).
+			WithField("id", egressMap.ID).
+			WithField("duration", time.Since(now)).
+			WithField("vppapi", "QosEgressMapUpdate").Debug("completed")
+	}
+	m.refs[dscp]++
+	return nil
+}
+
+// release deletes the egress map of the DSCP once it is not used by any connection
+func (m *egressMaps) release(ctx context.Context, dscp uint8) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.refs[dscp]--; m.refs[dscp] > 0 {
+		return
+	}
+	delete(m.refs, dscp)
+
+	now := time.Now()
+	if _, err := qos.NewServiceClient(m.vppConn).QosEgressMapDelete(ctx, &qos.QosEgressMapDelete{
+		ID: uint32(dscp),
+	}); err != nil {
+		log.FromContext(ctx).Warnf("failed to delete QoS egress map %d: %s", dscp, err.Error())
+		return
+	}
+	log.FromContext(ctx).
+		WithField("id", dscp).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "QosEgressMapDelete").Debug("completed")
+}
+
+// mark enables or disables the IP marking with the egress map of the DSCP on the interface
+func mark(ctx context.Context, vppConn api.Connection, dscp uint8, swIfIndex interface_types.InterfaceIndex, enable bool) error {
+	now := time.Now()
+	if _, err := qos.NewServiceClient(vppConn).QosMarkEnableDisable(ctx, &qos.QosMarkEnableDisable{
+		Enable: enable,
+		Mark: qos.QosMark{
+			SwIfIndex:    uint32(swIfIndex),
+			MapID:        uint32(dscp),
+			OutputSource: qos.QOS_API_SOURCE_IP,
+		},
+	}); err != nil {
+		return errors.Wrap(err, "vppapi QosMarkEnableDisable returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", swIfIndex).
+		WithField("dscp", dscp).
+		WithField("enable", enable).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "QosMarkEnableDisable").Debug("completed")
+	return nil
+}
@@ -0,0 +1,286 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redundancy keeps a single connection of a redundancy group active in VPP and fails over to the standby
+// one once the datapath of the active connection is lost
+package redundancy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/golang/protobuf/ptypes/empty"
+	interfaces "github.com/networkservicemesh/govpp/binapi/interface"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/heal"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/postpone"
+)
+
+type contextKey struct{}
+
+// WithGroup returns a context requesting the connection as a member of the redundancy group
+func WithGroup(ctx context.Context, group string) context.Context {
+	return context.WithValue(ctx, contextKey{}, group)
+}
+
+// member is a connection of a redundancy group
+type member struct {
+	id        string
+	nse       string
+	swIfIndex interface_types.InterfaceIndex
+	active    bool
+}
+
+// Groups keep the members of the redundancy groups. The first established member of a group is active, the other
+// ones are standby: their interfaces are admin down, so VPP doesn't route via them.
+type Groups struct {
+	vppConn api.Connection
+
+	mu      sync.Mutex
+	groups  map[string][]*member
+	members map[string]string
+}
+
+// New creates new Groups
+func New(vppConn api.Connection) *Groups {
+	return &Groups{
+		vppConn: vppConn,
+		groups:  make(map[string][]*member),
+		members: make(map[string]string),
+	}
+}
+
+// NewClient returns a client chain element adding the connection requested with WithGroup to the group. It should
+// be placed before the client setting the interfaces up, so the standby interface is set down after it.
+func (g *Groups) NewClient() networkservice.NetworkServiceClient {
+	return &redundancyClient{groups: g}
+}
+
+// DatapathCheck wraps the check, so the standby connections are alive while they are parked. Nil check is returned
+// as is.
+func (g *Groups) DatapathCheck(check heal.LivenessCheck) heal.LivenessCheck {
+	if check == nil {
+		return nil
+	}
+	return func(deadlineCtx context.Context, conn *networkservice.Connection) bool {
+		return g.isStandby(conn.GetId()) || check(deadlineCtx, conn)
+	}
+}
+
+// LivenessCheck wraps the check, so the standby connections are alive while they are parked and the active one
+// fails over to a standby one once its check fails. The failed connection is healed then and joins the group as a
+// standby one. Nil check is returned as is.
+func (g *Groups) LivenessCheck(check heal.LivenessCheck) heal.LivenessCheck {
+	if check == nil {
+		return nil
+	}
+	return func(deadlineCtx context.Context, conn *networkservice.Connection) bool {
+		if g.isStandby(conn.GetId()) {
+			return true
+		}
+		if check(deadlineCtx, conn) {
+			return true
+		}
+		g.failover(deadlineCtx, conn.GetId())
+		return false
+	}
+}
+
+func (g *Groups) isStandby(id string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	m := g.find(id)
+	return m != nil && !m.active
+}
+
+// failover activates a standby member of the group of the active member with the ID and parks the latter
+func (g *Groups) failover(ctx context.Context, id string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	failed := g.find(id)
+	if failed == nil || !failed.active {
+		return
+	}
+	if g.activate(ctx, g.members[id], failed) == nil {
+		log.FromContext(ctx).Warnf("no standby connection to fail over from %s", id)
+		return
+	}
+	failed.active = false
+	if err := setFlags(ctx, g.vppConn, failed.swIfIndex, false); err != nil {
+		log.FromContext(ctx).Warnf("failed to set interface %d of connection %s down: %s", failed.swIfIndex, id, err.Error())
+	}
+}
+
+// activate sets up the interface of the first standby member of the group except the one and returns it
+func (g *Groups) activate(ctx context.Context, group string, except *member) *member {
+	for _, m := range g.groups[group] {
+		if m == except || m.active {
+			continue
+		}
+		if err := setFlags(ctx, g.vppConn, m.swIfIndex, true); err != nil {
+			log.FromContext(ctx).Warnf("failed to activate connection %s: %s", m.id, err.Error())
+			continue
+		}
+		m.active = true
+		log.FromContext(ctx).Infof("connection %s of redundancy group %s is active", m.id, group)
+		return m
+	}
+	return nil
+}
+
+// join adds the connection to the group or updates its interface and parks it if the group has an active member
+func (g *Groups) join(ctx context.Context, group string, conn *networkservice.Connection, swIfIndex interface_types.InterfaceIndex) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	m := g.find(conn.GetId())
+	if m == nil {
+		m = &member{id: conn.GetId()}
+		g.groups[group] = append(g.groups[group], m)
+		g.members[m.id] = group
+	}
+	m.nse = conn.GetNetworkServiceEndpointName()
+	m.swIfIndex = swIfIndex
+
+	var hasActive bool
+	for _, other := range g.groups[group] {
+		if other == m {
+			continue
+		}
+		if other.nse == m.nse {
+			log.FromContext(ctx).Warnf("connections %s and %s of redundancy group %s use the same endpoint %s", other.id, m.id, group, m.nse)
+		}
+		hasActive = hasActive || other.active
+	}
+	if !hasActive {
+		m.active = true
+		log.FromContext(ctx).Infof("connection %s of redundancy group %s is active", m.id, group)
+		return nil
+	}
+	m.active = false
+	if err := setFlags(ctx, g.vppConn, swIfIndex, false); err != nil {
+		return err
+	}
+	log.FromContext(ctx).Infof("connection %s of redundancy group %s is standby", m.id, group)
+	return nil
+}
+
+// leave removes the connection from its group and activates a standby member if it was active
+func (g *Groups) leave(ctx context.Context, id string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	group, ok := g.members[id]
+	if !ok {
+		return
+	}
+	delete(g.members, id)
+	members := g.groups[group]
+	for i, m := range members {
+		if m.id != id {
+			continue
+		}
+		members = append(members[:i], members[i+1:]...)
+		if m.active {
+			g.groups[group] = members
+			g.activate(ctx, group, nil)
+		}
+		break
+	}
+	if len(members) == 0 {
+		delete(g.groups, group)
+		return
+	}
+	g.groups[group] = members
+}
+
+func (g *Groups) find(id string) *member {
+	for _, m := range g.groups[g.members[id]] {
+		if m.id == id {
+			return m
+		}
+	}
+	return nil
+}
+
+type redundancyClient struct {
+	groups *Groups
+}
+
+func (c *redundancyClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	postponeCtxFunc := postpone.ContextWithValues(ctx)
+
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	group, _ := ctx.Value(contextKey{}).(string)
+	swIfIndex, ok := ifindex.Load(ctx, true)
+	if group == "" || !ok {
+		return conn, nil
+	}
+	if err := c.groups.join(ctx, group, conn, swIfIndex); err != nil {
+		closeCtx, cancelClose := postponeCtxFunc()
+		defer cancelClose()
+
+		if _, closeErr := c.Close(closeCtx, conn, opts...); closeErr != nil {
+			err = errors.Wrapf(err, "connection closed with error: %s", closeErr.Error())
+		}
+
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (c *redundancyClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	c.groups.leave(ctx, conn.GetId())
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
+
+func setFlags(ctx context.Context, vppConn api.Connection, swIfIndex interface_types.InterfaceIndex, up bool) error {
+	var flags interface_types.IfStatusFlags
+	if up {
+		flags = interface_types.IF_STATUS_API_FLAG_ADMIN_UP
+	}
+
+	now := time.Now()
+	if _, err := interfaces.NewServiceClient(vppConn).SwInterfaceSetFlags(ctx, &interfaces.SwInterfaceSetFlags{
+		SwIfIndex: swIfIndex,
+		Flags:     flags,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi SwInterfaceSetFlags returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", swIfIndex).
+		WithField("flags", flags).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "SwInterfaceSetFlags").Debug("completed")
+	return nil
+}
@@ -0,0 +1,305 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package registry keeps the actual state of the NSC connections for inspection
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	memifMech "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/heal"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/connlog"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/loglevels"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/memif"
+)
+
+// States of the connection additional to the networkservice.State ones
+const (
+	// StateHealing - the datapath liveness check has failed and the connection is not healed yet
+	StateHealing = "HEALING"
+)
+
+// Info is the inspected state of the connection
+type Info struct {
+	ID                     string            `json:"id"`
+	NetworkService         string            `json:"networkService"`
+	NetworkServiceEndpoint string            `json:"networkServiceEndpoint,omitempty"`
+	Mechanism              string            `json:"mechanism,omitempty"`
+	SrcIPs                 []string          `json:"srcIPs,omitempty"`
+	DstIPs                 []string          `json:"dstIPs,omitempty"`
+	IfIndex                uint32            `json:"ifindex"`
+	MemifSocket            string            `json:"memifSocket,omitempty"`
+	MemifParameters        map[string]string `json:"memifParameters,omitempty"`
+	State                  string            `json:"state"`
+	LastHealTime           *time.Time        `json:"lastHealTime,omitempty"`
+}
+
+type entry struct {
+	conn         *networkservice.Connection
+	ifIndex      uint32
+	memifSocket  string
+	healing      bool
+	lastHealTime *time.Time
+}
+
+// Event is the connection lifecycle event
+type Event string
+
+// Connection lifecycle events
+const (
+	// EventEstablished - the connection is requested for the first time
+	EventEstablished Event = "established"
+	// EventHealed - the connection is requested again after the failed liveness check or with a new NSE
+	EventHealed Event = "healed"
+	// EventDegraded - the liveness check of the connection has failed
+	EventDegraded Event = "degraded"
+	// EventClosed - the connection is closed
+	EventClosed Event = "closed"
+)
+
+// EventHandler is called on the connection lifecycle events
+type EventHandler func(ctx context.Context, event Event, conn *networkservice.Connection)
+
+// Registry keeps the state of the connections passing through its client
+type Registry struct {
+	handlers []EventHandler
+
+	mu      sync.RWMutex
+	entries map[string]*entry
+}
+
+// Option is an option pattern for New
+type Option func(r *Registry)
+
+// WithEventHandler adds the handler called on the connection lifecycle events
+func WithEventHandler(handler EventHandler) Option {
+	return func(r *Registry) {
+		r.handlers = append(r.handlers, handler)
+	}
+}
+
+// New creates a new Registry
+func New(opts ...Option) *Registry {
+	r := &Registry{
+		entries: make(map[string]*entry),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// NewClient returns a client chain element updating the registry on every Request and Close. It should be
+// placed before the mechanism client to see the interface index.
+func (r *Registry) NewClient() networkservice.NetworkServiceClient {
+	return &registryClient{registry: r}
+}
+
+// LivenessCheck wraps the check, so the registry marks connections with the failed check as healing. Nil check is
+// returned as is.
+func (r *Registry) LivenessCheck(check heal.LivenessCheck) heal.LivenessCheck {
+	if check == nil {
+		return nil
+	}
+	return func(deadlineCtx context.Context, conn *networkservice.Connection) bool {
+		deadlineCtx = log.WithLog(deadlineCtx, log.FromContext(deadlineCtx).
+			WithField(connlog.IDField, conn.GetId()).
+			WithField(loglevels.ComponentField, loglevels.HealComponent))
+		ok := check(deadlineCtx, conn)
+		if !ok {
+			var degraded bool
+			r.mu.Lock()
+			if e, loaded := r.entries[conn.GetId()]; loaded {
+				degraded = !e.healing
+				e.healing = true
+			}
+			r.mu.Unlock()
+			if degraded {
+				r.notify(deadlineCtx, EventDegraded, conn)
+			}
+		}
+		return ok
+	}
+}
+
+// Connections returns the state of all known connections sorted by ID
+func (r *Registry) Connections() []*Info {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*Info, 0, len(r.entries))
+	for _, e := range r.entries {
+		info := &Info{
+			ID:                     e.conn.GetId(),
+			NetworkService:         e.conn.GetNetworkService(),
+			NetworkServiceEndpoint: e.conn.GetNetworkServiceEndpointName(),
+			Mechanism:              e.conn.GetMechanism().GetType(),
+			SrcIPs:                 e.conn.GetContext().GetIpContext().GetSrcIpAddrs(),
+			DstIPs:                 e.conn.GetContext().GetIpContext().GetDstIpAddrs(),
+			IfIndex:                e.ifIndex,
+			MemifSocket:            e.memifSocket,
+			State:                  e.conn.GetState().String(),
+			LastHealTime:           e.lastHealTime,
+		}
+		if e.conn.GetMechanism().GetType() == memifMech.MECHANISM {
+			info.MemifParameters = e.conn.GetMechanism().GetParameters()
+		}
+		if e.healing {
+			info.State = StateHealing
+		}
+		result = append(result, info)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// Check returns an error if any of the known connections is not up
+func (r *Registry) Check(_ context.Context) error {
+	for _, info := range r.Connections() {
+		if info.State != networkservice.State_UP.String() {
+			return errors.Errorf("connection %s is %s", info.ID, info.State)
+		}
+	}
+	return nil
+}
+
+// IfIndexes returns VPP interface indexes of the known connections
+func (r *Registry) IfIndexes() []uint32 {
+	var result []uint32
+	for _, info := range r.Connections() {
+		if info.IfIndex != 0 {
+			result = append(result, info.IfIndex)
+		}
+	}
+	return result
+}
+
+// IfIndex returns VPP interface index of the connection with the ID
+func (r *Registry) IfIndex(id string) (uint32, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.entries[id]
+	if !ok || e.ifIndex == 0 {
+		return 0, false
+	}
+	return e.ifIndex, true
+}
+
+// ServeHTTP writes the state of all known connections as JSON
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(r.Connections())
+}
+
+func (r *Registry) update(ctx context.Context, conn *networkservice.Connection) {
+	if event, ok := r.store(ctx, conn); ok {
+		r.notify(ctx, event, conn)
+	}
+}
+
+// store stores the connection and returns the lifecycle event if there is one
+func (r *Registry) store(ctx context.Context, conn *networkservice.Connection) (Event, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, loaded := r.entries[conn.GetId()]
+	if !loaded {
+		e = new(entry)
+		r.entries[conn.GetId()] = e
+	}
+	healed := loaded && (e.healing || e.conn.GetNetworkServiceEndpointName() != conn.GetNetworkServiceEndpointName())
+	if healed {
+		now := time.Now()
+		e.lastHealTime = &now
+	}
+	e.healing = false
+	e.conn = conn.Clone()
+	if swIfIndex, ok := ifindex.Load(ctx, true); ok {
+		e.ifIndex = uint32(swIfIndex)
+	}
+	if socket, ok := memif.SocketFilename(ctx); ok {
+		e.memifSocket = socket
+	}
+
+	switch {
+	case !loaded:
+		return EventEstablished, true
+	case healed:
+		return EventHealed, true
+	default:
+		return "", false
+	}
+}
+
+func (r *Registry) delete(ctx context.Context, conn *networkservice.Connection) {
+	r.mu.Lock()
+	_, loaded := r.entries[conn.GetId()]
+	delete(r.entries, conn.GetId())
+	r.mu.Unlock()
+
+	if loaded {
+		r.notify(ctx, EventClosed, conn)
+	}
+}
+
+func (r *Registry) notify(ctx context.Context, event Event, conn *networkservice.Connection) {
+	for _, handler := range r.handlers {
+		handler(ctx, event, conn)
+	}
+}
+
+type registryClient struct {
+	registry *Registry
+}
+
+func (c *registryClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.registry.update(ctx, conn)
+	return conn, nil
+}
+
+func (c *registryClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	c.registry.delete(ctx, conn)
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
@@ -0,0 +1,283 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package routes installs additional static and source based policy routes into VPP via the NSM connection
+// interfaces
+package routes
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/vrf"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/postpone"
+)
+
+// Routes are the additional routes via the NSM connection interface
+type Routes struct {
+	// Static are the destination prefixes routed via the connection
+	Static []*net.IPNet
+	// Policy are the source prefixes: the traffic from them coming to VPP from the other NSM connections is
+	// forwarded via the connection regardless of the destination
+	Policy []*net.IPNet
+}
+
+func (r *Routes) append(other *Routes) *Routes {
+	if other == nil {
+		return r
+	}
+	return &Routes{
+		Static: append(r.Static[:len(r.Static):len(r.Static)], other.Static...),
+		Policy: append(r.Policy[:len(r.Policy):len(r.Policy)], other.Policy...),
+	}
+}
+
+type contextKey struct{}
+
+// WithRoutes returns a context requesting the connection with the additional routes r
+func WithRoutes(ctx context.Context, r *Routes) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// installed is the state of the routes of a single connection
+type installed struct {
+	routes    *Routes
+	swIfIndex interface_types.InterfaceIndex
+	tables    map[bool]uint32
+	nextHops  []net.IP
+	policies  []*policy
+}
+
+// Router keeps the routes installed for the connections
+type Router struct {
+	vppConn api.Connection
+	global  *Routes
+
+	mu           sync.Mutex
+	conns        map[string]*installed
+	nextPolicyID uint32
+}
+
+// New creates a new Router, the global routes are installed for every connection
+func New(vppConn api.Connection, global *Routes) *Router {
+	if global == nil {
+		global = new(Routes)
+	}
+	return &Router{
+		vppConn: vppConn,
+		global:  global,
+		conns:   make(map[string]*installed),
+	}
+}
+
+// NewClient returns a client chain element installing the global routes and the routes requested with WithRoutes.
+// It should be placed before the mechanism client to see the interface index.
+func (r *Router) NewClient() networkservice.NetworkServiceClient {
+	return &routesClient{router: r}
+}
+
+type routesClient struct {
+	router *Router
+}
+
+func (c *routesClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	requested, _ := ctx.Value(contextKey{}).(*Routes)
+
+	postponeCtxFunc := postpone.ContextWithValues(ctx)
+
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return conn, err
+	}
+
+	if err := c.router.install(ctx, conn, requested); err != nil {
+		closeCtx, cancelClose := postponeCtxFunc()
+		defer cancelClose()
+
+		if _, closeErr := c.Close(closeCtx, conn, opts...); closeErr != nil {
+			err = errors.Wrapf(err, "connection closed with error: %s", closeErr.Error())
+		}
+
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (c *routesClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	c.router.uninstall(ctx, conn.GetId())
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
+
+func (r *Router) install(ctx context.Context, conn *networkservice.Connection, requested *Routes) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	swIfIndex, ok := ifindex.Load(ctx, true)
+	if !ok {
+		return nil
+	}
+
+	i, loaded := r.conns[conn.GetId()]
+	if loaded && i.swIfIndex == swIfIndex {
+		return nil
+	}
+	var routes *Routes
+	if loaded {
+		// The NSM interface is created again on reselect, so the routes are moved to the new one
+		routes = i.routes
+		r.remove(ctx, conn.GetId(), i)
+	} else {
+		routes = r.global.append(requested)
+	}
+	if len(routes.Static) == 0 && len(routes.Policy) == 0 {
+		return nil
+	}
+
+	i = &installed{
+		routes:    routes,
+		swIfIndex: swIfIndex,
+		tables:    make(map[bool]uint32),
+		nextHops:  nextHops(conn),
+	}
+	// The static routes are installed into the connection VRF if there is one
+	for _, isIPv6 := range []bool{false, true} {
+		i.tables[isIPv6], _ = vrf.Load(ctx, true, isIPv6)
+	}
+	r.conns[conn.GetId()] = i
+	if err := r.add(ctx, i); err != nil {
+		r.remove(ctx, conn.GetId(), i)
+		return err
+	}
+
+	log.FromContext(ctx).Infof("%d static and %d policy routes are installed via the connection %s",
+		len(routes.Static), len(routes.Policy), conn.GetId())
+	return nil
+}
+
+func (r *Router) uninstall(ctx context.Context, id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if i, ok := r.conns[id]; ok {
+		r.remove(ctx, id, i)
+	}
+}
+
+func (r *Router) add(ctx context.Context, i *installed) error {
+	for _, prefix := range i.routes.Static {
+		if err := routeAddDel(ctx, r.vppConn, i.tables[prefix.IP.To4() == nil], i.swIfIndex, nextHop(i.nextHops, prefix), prefix, true); err != nil {
+			return err
+		}
+	}
+	for _, prefix := range i.routes.Policy {
+		r.nextPolicyID++
+		p, err := addPolicy(ctx, r.vppConn, r.nextPolicyID, i.swIfIndex, nextHop(i.nextHops, prefix), prefix)
+		if err != nil {
+			return err
+		}
+		i.policies = append(i.policies, p)
+	}
+
+	// The policies of the connection are attached to the other NSM interfaces and vice versa
+	for _, other := range r.conns {
+		if other == i {
+			continue
+		}
+		for _, p := range i.policies {
+			if err := p.attach(ctx, r.vppConn, other.swIfIndex, true); err != nil {
+				return err
+			}
+		}
+		for _, p := range other.policies {
+			if err := p.attach(ctx, r.vppConn, i.swIfIndex, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// remove deletes the routes and policies of the connection ignoring the errors, so everything possible is removed
+func (r *Router) remove(ctx context.Context, id string, i *installed) {
+	delete(r.conns, id)
+
+	for _, other := range r.conns {
+		for _, p := range other.policies {
+			p.detach(ctx, r.vppConn, i.swIfIndex)
+		}
+	}
+	for _, p := range i.policies {
+		p.del(ctx, r.vppConn)
+	}
+	i.policies = nil
+	for _, prefix := range i.routes.Static {
+		if err := routeAddDel(ctx, r.vppConn, i.tables[prefix.IP.To4() == nil], i.swIfIndex, nextHop(i.nextHops, prefix), prefix, false); err != nil {
+			log.FromContext(ctx).Warnf("failed to delete route %s: %s", prefix.String(), err.Error())
+		}
+	}
+}
+
+// nextHops returns the destination IPs of the connection used as the next hops of the routes
+func nextHops(conn *networkservice.Connection) []net.IP {
+	var result []net.IP
+	for _, dst := range conn.GetContext().GetIpContext().GetDstIPNets() {
+		result = append(result, dst.IP)
+	}
+	return result
+}
+
+// nextHop returns the next hop of the same family as the prefix, nil if there is none
+func nextHop(nextHops []net.IP, prefix *net.IPNet) net.IP {
+	isV6 := prefix.IP.To4() == nil
+	for _, nh := range nextHops {
+		if (nh.To4() == nil) == isV6 {
+			return nh
+		}
+	}
+	return nil
+}
+
+// ParsePrefixes parses the comma separated CIDR prefixes from values
+func ParsePrefixes(values ...string) ([]*net.IPNet, error) {
+	var result []*net.IPNet
+	for _, value := range values {
+		for _, s := range strings.Split(value, ",") {
+			if s = strings.TrimSpace(s); s == "" {
+				continue
+			}
+			_, prefix, err := net.ParseCIDR(s)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid route prefix %s", s)
+			}
+			result = append(result, prefix)
+		}
+	}
+	return result, nil
+}
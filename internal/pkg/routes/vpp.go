@@ -0,0 +1,201 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routes
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/networkservicemesh/govpp/binapi/abf"
+	"github.com/networkservicemesh/govpp/binapi/acl"
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/govpp/binapi/fib_types"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/ip"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/types"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// policyPriority is the priority of the ABF policies attached to the NSM interfaces
+const policyPriority = 10
+
+func fibPath(swIfIndex interface_types.InterfaceIndex, nh net.IP, isV6 bool) fib_types.FibPath {
+	path := fib_types.FibPath{
+		SwIfIndex: uint32(swIfIndex),
+		Weight:    1,
+		Type:      fib_types.FIB_API_PATH_TYPE_NORMAL,
+		Flags:     fib_types.FIB_API_PATH_FLAG_NONE,
+		Proto:     types.IsV6toFibProto(isV6),
+	}
+	if nh != nil {
+		path.Nh.Address = types.ToVppAddress(nh).Un
+	}
+	return path
+}
+
+func routeAddDel(ctx context.Context, vppConn api.Connection, tableID uint32, swIfIndex interface_types.InterfaceIndex, nh net.IP, prefix *net.IPNet, isAdd bool) error {
+	now := time.Now()
+	if _, err := ip.NewServiceClient(vppConn).IPRouteAddDel(ctx, &ip.IPRouteAddDel{
+		IsAdd: isAdd,
+		Route: ip.IPRoute{
+			TableID: tableID,
+			Prefix:  types.ToVppPrefix(prefix),
+			NPaths:  1,
+			Paths:   []fib_types.FibPath{fibPath(swIfIndex, nh, prefix.IP.To4() == nil)},
+		},
+	}); err != nil {
+		return errors.Wrap(err, "vppapi IPRouteAddDel returned error")
+	}
+	log.FromContext(ctx).
+		WithField("tableID", tableID).
+		WithField("swIfIndex", swIfIndex).
+		WithField("prefix", prefix.String()).
+		WithField("isAdd", isAdd).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "IPRouteAddDel").Debug("completed")
+	return nil
+}
+
+// policy is an ABF policy forwarding the traffic from the source prefix via the NSM interface
+type policy struct {
+	id       uint32
+	aclIndex uint32
+	isV6     bool
+	attached []interface_types.InterfaceIndex
+}
+
+func addPolicy(ctx context.Context, vppConn api.Connection, id uint32, swIfIndex interface_types.InterfaceIndex, nh net.IP, src *net.IPNet) (*policy, error) {
+	isV6 := src.IP.To4() == nil
+	anyDst := &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, net.IPv4len*8)}
+	if isV6 {
+		anyDst = &net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, net.IPv6len*8)}
+	}
+
+	now := time.Now()
+	reply, err := acl.NewServiceClient(vppConn).ACLAddReplace(ctx, &acl.ACLAddReplace{
+		ACLIndex: ^uint32(0),
+		Tag:      "nsc-policy-route",
+		R: []acl_types.ACLRule{
+			{
+				IsPermit:  acl_types.ACL_ACTION_API_PERMIT,
+				SrcPrefix: types.ToVppPrefix(src),
+				DstPrefix: types.ToVppPrefix(anyDst),
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "vppapi ACLAddReplace returned error")
+	}
+	log.FromContext(ctx).
+		WithField("aclIndex", reply.ACLIndex).
+		WithField("src", src.String()).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "ACLAddReplace").Debug("completed")
+
+	p := &policy{
+		id:       id,
+		aclIndex: reply.ACLIndex,
+		isV6:     isV6,
+	}
+	now = time.Now()
+	if _, err = abf.NewServiceClient(vppConn).AbfPolicyAddDel(ctx, &abf.AbfPolicyAddDel{
+		IsAdd: true,
+		Policy: abf.AbfPolicy{
+			PolicyID: id,
+			ACLIndex: reply.ACLIndex,
+			NPaths:   1,
+			Paths:    []fib_types.FibPath{fibPath(swIfIndex, nh, isV6)},
+		},
+	}); err != nil {
+		p.delACL(ctx, vppConn)
+		return nil, errors.Wrap(err, "vppapi AbfPolicyAddDel returned error")
+	}
+	log.FromContext(ctx).
+		WithField("policyID", id).
+		WithField("swIfIndex", swIfIndex).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "AbfPolicyAddDel").Debug("completed")
+	return p, nil
+}
+
+func (p *policy) attach(ctx context.Context, vppConn api.Connection, swIfIndex interface_types.InterfaceIndex, isAdd bool) error {
+	now := time.Now()
+	if _, err := abf.NewServiceClient(vppConn).AbfItfAttachAddDel(ctx, &abf.AbfItfAttachAddDel{
+		IsAdd: isAdd,
+		Attach: abf.AbfItfAttach{
+			PolicyID:  p.id,
+			SwIfIndex: swIfIndex,
+			Priority:  policyPriority,
+			IsIPv6:    p.isV6,
+		},
+	}); err != nil {
+		return errors.Wrap(err, "vppapi AbfItfAttachAddDel returned error")
+	}
+	log.FromContext(ctx).
+		WithField("policyID", p.id).
+		WithField("swIfIndex", swIfIndex).
+		WithField("isAdd", isAdd).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "AbfItfAttachAddDel").Debug("completed")
+
+	if isAdd {
+		p.attached = append(p.attached, swIfIndex)
+	}
+	return nil
+}
+
+// detach detaches the policy from the interface swIfIndex if it is attached
+func (p *policy) detach(ctx context.Context, vppConn api.Connection, swIfIndex interface_types.InterfaceIndex) {
+	for i, attached := range p.attached {
+		if attached != swIfIndex {
+			continue
+		}
+		if err := p.attach(ctx, vppConn, swIfIndex, false); err != nil {
+			log.FromContext(ctx).Warnf("failed to detach policy %d: %s", p.id, err.Error())
+		}
+		p.attached = append(p.attached[:i], p.attached[i+1:]...)
+		return
+	}
+}
+
+// del detaches the policy from all the interfaces and deletes it
+func (p *policy) del(ctx context.Context, vppConn api.Connection) {
+	for len(p.attached) > 0 {
+		p.detach(ctx, vppConn, p.attached[0])
+	}
+	if _, err := abf.NewServiceClient(vppConn).AbfPolicyAddDel(ctx, &abf.AbfPolicyAddDel{
+		IsAdd: false,
+		Policy: abf.AbfPolicy{
+			PolicyID: p.id,
+			ACLIndex: p.aclIndex,
+		},
+	}); err != nil {
+		log.FromContext(ctx).Warnf("failed to delete policy %d: %s", p.id, err.Error())
+	}
+	p.delACL(ctx, vppConn)
+}
+
+func (p *policy) delACL(ctx context.Context, vppConn api.Connection) {
+	if _, err := acl.NewServiceClient(vppConn).ACLDel(ctx, &acl.ACLDel{ACLIndex: p.aclIndex}); err != nil {
+		log.FromContext(ctx).Warnf("failed to delete ACL %d: %s", p.aclIndex, err.Error())
+	}
+}
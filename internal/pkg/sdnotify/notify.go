@@ -0,0 +1,110 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sdnotify implements the systemd service notification protocol, so the NSC can be supervised by systemd
+package sdnotify
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// Service states
+const (
+	// Ready - the service startup is finished
+	Ready = "READY=1"
+	// Stopping - the service is beginning its shutdown
+	Stopping = "STOPPING=1"
+	// Watchdog - the service is alive
+	Watchdog = "WATCHDOG=1"
+)
+
+// Enabled returns true if the NSC is run by systemd with the notification socket
+func Enabled() bool {
+	return os.Getenv("NOTIFY_SOCKET") != ""
+}
+
+// Notify sends the state to systemd, it does nothing if the NSC is not run by systemd
+func Notify(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	// Abstract socket names start with @
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to systemd notification socket")
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err = conn.Write([]byte(state)); err != nil {
+		return errors.Wrapf(err, "failed to notify systemd %s", state)
+	}
+	return nil
+}
+
+// WatchdogInterval returns the interval the watchdog keepalives should be sent at, 0 if the watchdog is disabled
+func WatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	// Keepalives are sent twice per timeout as recommended by sd_watchdog_enabled(3)
+	return time.Duration(usec) * time.Microsecond / 2
+}
+
+// RunWatchdog sends the watchdog keepalives while check succeeds until ctx is done. It does nothing if the watchdog
+// is disabled.
+func RunWatchdog(ctx context.Context, check func(ctx context.Context) error) {
+	interval := WatchdogInterval()
+	if interval == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, interval)
+		err := check(checkCtx)
+		cancel()
+		if err != nil {
+			log.FromContext(ctx).Warnf("skipping systemd watchdog keepalive: %v", err.Error())
+			continue
+		}
+		if err = Notify(Watchdog); err != nil {
+			log.FromContext(ctx).Warnf("%v", err.Error())
+		}
+	}
+}
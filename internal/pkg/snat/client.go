@@ -0,0 +1,191 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package snat source NATs the traffic leaving VPP via the NSM connection interfaces to the connection source
+// addresses, so the applications not able to bind to them use the connections
+package snat
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/nat44_ed"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/postpone"
+)
+
+type metadataKey struct{}
+
+type snatClient struct {
+	vppConn api.Connection
+
+	mu      sync.Mutex
+	enabled bool
+}
+
+// NewClient returns a client chain element enabling NAT44 on the connection interfaces with their addresses as the
+// NAT pool. It should be placed before the mechanism client to see the interface index.
+func NewClient(vppConn api.Connection) networkservice.NetworkServiceClient {
+	return &snatClient{vppConn: vppConn}
+}
+
+func (c *snatClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	postponeCtxFunc := postpone.ContextWithValues(ctx)
+
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.add(ctx); err != nil {
+		closeCtx, cancelClose := postponeCtxFunc()
+		defer cancelClose()
+
+		if _, closeErr := c.Close(closeCtx, conn, opts...); closeErr != nil {
+			err = errors.Wrapf(err, "connection closed with error: %s", closeErr.Error())
+		}
+
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (c *snatClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	if swIfIndex, ok := loadAndDelete(ctx); ok {
+		if err := interfaceAddDel(ctx, c.vppConn, swIfIndex, false); err != nil {
+			log.FromContext(ctx).Warnf("failed to disable NAT44 on interface %d: %s", swIfIndex, err.Error())
+		}
+	}
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
+
+func (c *snatClient) add(ctx context.Context) error {
+	swIfIndex, ok := ifindex.Load(ctx, true)
+	if !ok {
+		return nil
+	}
+	prev, loaded := load(ctx)
+	if loaded && prev == swIfIndex {
+		return nil
+	}
+
+	if err := c.enable(ctx); err != nil {
+		return err
+	}
+	// The NSM interface is created again on reselect, so NAT44 is moved to the new one
+	if loaded {
+		if err := interfaceAddDel(ctx, c.vppConn, prev, false); err != nil {
+			log.FromContext(ctx).Warnf("failed to disable NAT44 on interface %d: %s", prev, err.Error())
+		}
+	}
+	if err := interfaceAddDel(ctx, c.vppConn, swIfIndex, true); err != nil {
+		// VPP may be restarted since the plugin is enabled
+		c.mu.Lock()
+		c.enabled = false
+		c.mu.Unlock()
+		return err
+	}
+	store(ctx, swIfIndex)
+
+	log.FromContext(ctx).Infof("NAT44 is enabled on interface %d", swIfIndex)
+	return nil
+}
+
+func (c *snatClient) enable(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.enabled {
+		return nil
+	}
+	now := time.Now()
+	if _, err := nat44_ed.NewServiceClient(c.vppConn).Nat44EdPluginEnableDisable(ctx, &nat44_ed.Nat44EdPluginEnableDisable{
+		Enable: true,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi Nat44EdPluginEnableDisable returned error")
+	}
+	log.FromContext(ctx).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "Nat44EdPluginEnableDisable").Debug("completed")
+	c.enabled = true
+	return nil
+}
+
+// interfaceAddDel adds or deletes the interface addresses to the NAT pool and the NAT44 output feature on the
+// interface
+func interfaceAddDel(ctx context.Context, vppConn api.Connection, swIfIndex interface_types.InterfaceIndex, isAdd bool) error {
+	now := time.Now()
+	if _, err := nat44_ed.NewServiceClient(vppConn).Nat44AddDelInterfaceAddr(ctx, &nat44_ed.Nat44AddDelInterfaceAddr{
+		IsAdd:     isAdd,
+		SwIfIndex: swIfIndex,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi Nat44AddDelInterfaceAddr returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", swIfIndex).
+		WithField("isAdd", isAdd).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "Nat44AddDelInterfaceAddr").Debug("completed")
+
+	now = time.Now()
+	if _, err := nat44_ed.NewServiceClient(vppConn).Nat44EdAddDelOutputInterface(ctx, &nat44_ed.Nat44EdAddDelOutputInterface{
+		IsAdd:     isAdd,
+		SwIfIndex: swIfIndex,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi Nat44EdAddDelOutputInterface returned error")
+	}
+	log.FromContext(ctx).
+		WithField("swIfIndex", swIfIndex).
+		WithField("isAdd", isAdd).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "Nat44EdAddDelOutputInterface").Debug("completed")
+	return nil
+}
+
+func store(ctx context.Context, swIfIndex interface_types.InterfaceIndex) {
+	metadata.Map(ctx, true).Store(metadataKey{}, swIfIndex)
+}
+
+func load(ctx context.Context) (interface_types.InterfaceIndex, bool) {
+	rawValue, ok := metadata.Map(ctx, true).Load(metadataKey{})
+	if !ok {
+		return 0, false
+	}
+	swIfIndex, ok := rawValue.(interface_types.InterfaceIndex)
+	return swIfIndex, ok
+}
+
+func loadAndDelete(ctx context.Context) (interface_types.InterfaceIndex, bool) {
+	rawValue, ok := metadata.Map(ctx, true).LoadAndDelete(metadataKey{})
+	if !ok {
+		return 0, false
+	}
+	swIfIndex, ok := rawValue.(interface_types.InterfaceIndex)
+	return swIfIndex, ok
+}
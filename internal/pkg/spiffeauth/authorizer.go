@@ -0,0 +1,89 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spiffeauth provides the authorizer of the NSMgr SPIFFE ID
+package spiffeauth
+
+import (
+	"crypto/x509"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+)
+
+// Config is the config of the SPIFFE IDs allowed for the peer, all the set fields should match
+type Config struct {
+	// IDs is the list of the allowed SPIFFE IDs, any ID is allowed if empty
+	IDs []string
+	// TrustDomain is the trust domain of the allowed SPIFFE IDs, any trust domain is allowed if empty
+	TrustDomain string
+	// Pattern is the regular expression matching the whole allowed SPIFFE IDs, any ID is allowed if empty
+	Pattern string
+}
+
+// IsEmpty returns true if no restrictions are set and any SPIFFE ID is allowed
+func (c *Config) IsEmpty() bool {
+	return len(c.IDs) == 0 && c.TrustDomain == "" && c.Pattern == ""
+}
+
+// NewAuthorizer returns the authorizer allowing the SPIFFE IDs matching the config
+func NewAuthorizer(config *Config) (tlsconfig.Authorizer, error) {
+	var matchers []spiffeid.Matcher
+	if len(config.IDs) > 0 {
+		var ids []spiffeid.ID
+		for _, value := range config.IDs {
+			id, err := spiffeid.FromString(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid SPIFFE ID %s", value)
+			}
+			ids = append(ids, id)
+		}
+		matchers = append(matchers, spiffeid.MatchOneOf(ids...))
+	}
+	if config.TrustDomain != "" {
+		trustDomain, err := spiffeid.TrustDomainFromString(config.TrustDomain)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid trust domain %s", config.TrustDomain)
+		}
+		matchers = append(matchers, spiffeid.MatchMemberOf(trustDomain))
+	}
+	if config.Pattern != "" {
+		pattern, err := regexp.Compile("^(?:" + config.Pattern + ")$")
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid SPIFFE ID pattern %s", config.Pattern)
+		}
+		matchers = append(matchers, func(actual spiffeid.ID) error {
+			if !pattern.MatchString(actual.String()) {
+				return errors.Errorf("unexpected ID %q", actual.String())
+			}
+			return nil
+		})
+	}
+	if len(matchers) == 0 {
+		return tlsconfig.AuthorizeAny(), nil
+	}
+
+	return func(actual spiffeid.ID, _ [][]*x509.Certificate) error {
+		for _, matcher := range matchers {
+			if err := matcher(actual); err != nil {
+				return errors.Wrap(err, "peer is not authorized")
+			}
+		}
+		return nil
+	}, nil
+}
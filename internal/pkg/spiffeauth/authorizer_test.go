@@ -0,0 +1,104 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spiffeauth_test
+
+import (
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/spiffeauth"
+)
+
+func TestNewAuthorizer(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		config     spiffeauth.Config
+		empty      bool
+		allowed    []string
+		disallowed []string
+		err        bool
+	}{
+		{
+			name:    "any",
+			empty:   true,
+			allowed: []string{"spiffe://example.org/nsmgr", "spiffe://other.org/nse"},
+		},
+		{
+			name:       "ids",
+			config:     spiffeauth.Config{IDs: []string{"spiffe://example.org/nsmgr", "spiffe://example.org/nsmgr-proxy"}},
+			allowed:    []string{"spiffe://example.org/nsmgr", "spiffe://example.org/nsmgr-proxy"},
+			disallowed: []string{"spiffe://example.org/nse", "spiffe://other.org/nsmgr"},
+		},
+		{
+			name:       "trust domain",
+			config:     spiffeauth.Config{TrustDomain: "example.org"},
+			allowed:    []string{"spiffe://example.org/nsmgr", "spiffe://example.org/ns/nsm-system/sa/nsmgr"},
+			disallowed: []string{"spiffe://other.org/nsmgr"},
+		},
+		{
+			name:       "pattern",
+			config:     spiffeauth.Config{Pattern: "spiffe://example.org/ns/nsm-system/sa/nsmgr(-.*)?"},
+			allowed:    []string{"spiffe://example.org/ns/nsm-system/sa/nsmgr", "spiffe://example.org/ns/nsm-system/sa/nsmgr-1"},
+			disallowed: []string{"spiffe://example.org/ns/nsm-system/sa/nse", "spiffe://example.org/ns/nsm-system/sa/nsmgr/x/y"},
+		},
+		{
+			name: "all",
+			config: spiffeauth.Config{
+				IDs:         []string{"spiffe://example.org/nsmgr", "spiffe://other.org/nsmgr", "spiffe://example.org/nse"},
+				TrustDomain: "example.org",
+				Pattern:     ".*/nsmgr",
+			},
+			allowed:    []string{"spiffe://example.org/nsmgr"},
+			disallowed: []string{"spiffe://other.org/nsmgr", "spiffe://example.org/nse"},
+		},
+		{
+			name:   "invalid id",
+			config: spiffeauth.Config{IDs: []string{"http://example.org/nsmgr"}},
+			err:    true,
+		},
+		{
+			name:   "invalid trust domain",
+			config: spiffeauth.Config{TrustDomain: "Example Org"},
+			err:    true,
+		},
+		{
+			name:   "invalid pattern",
+			config: spiffeauth.Config{Pattern: "spiffe://example.org/(nsmgr"},
+			err:    true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			authorizer, err := spiffeauth.NewAuthorizer(&tc.config)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.empty, tc.config.IsEmpty())
+
+			for _, value := range tc.allowed {
+				require.NoError(t, authorizer(spiffeid.RequireFromString(value), nil), value)
+			}
+			for _, value := range tc.disallowed {
+				require.Error(t, authorizer(spiffeid.RequireFromString(value), nil), value)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+// Package stats exports VPP counters of the NSC interfaces as OpenTelemetry metrics
+package stats
+
+import (
+	"context"
+
+	"git.fd.io/govpp.git/adapter/statsclient"
+	"git.fd.io/govpp.git/api"
+	"git.fd.io/govpp.git/core"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/registry"
+)
+
+type counter struct {
+	name        string
+	description string
+	unit        string
+	value       func(iface *api.InterfaceCounters) uint64
+}
+
+var counters = []*counter{
+	{
+		name:        "vpp_memif_rx_packets",
+		description: "Packets received on the memif interface of the connection",
+		unit:        "{packet}",
+		value:       func(iface *api.InterfaceCounters) uint64 { return iface.Rx.Packets },
+	},
+	{
+		name:        "vpp_memif_tx_packets",
+		description: "Packets sent on the memif interface of the connection",
+		unit:        "{packet}",
+		value:       func(iface *api.InterfaceCounters) uint64 { return iface.Tx.Packets },
+	},
+	{
+		name:        "vpp_memif_rx_bytes",
+		description: "Bytes received on the memif interface of the connection",
+		unit:        "By",
+		value:       func(iface *api.InterfaceCounters) uint64 { return iface.Rx.Bytes },
+	},
+	{
+		name:        "vpp_memif_tx_bytes",
+		description: "Bytes sent on the memif interface of the connection",
+		unit:        "By",
+		value:       func(iface *api.InterfaceCounters) uint64 { return iface.Tx.Bytes },
+	},
+	{
+		name:        "vpp_memif_drops",
+		description: "Packets dropped on the memif interface of the connection",
+		unit:        "{packet}",
+		value:       func(iface *api.InterfaceCounters) uint64 { return iface.Drops },
+	},
+}
+
+// Start connects to the VPP stats socket and registers OpenTelemetry counters of the memif interfaces of the
+// connections returned by connections. The counters are read from VPP on every metrics collection, each one is
+// tagged with the network service name and the connection ID. The stats connection is closed when ctx is done.
+func Start(ctx context.Context, statsSocket string, connections func() []*registry.Info) error {
+	statsConn, err := core.ConnectStats(statsclient.NewStatsClient(statsSocket))
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to VPP stats socket %s", statsSocket)
+	}
+
+	meter := otel.Meter("")
+	instruments := make([]metric.Int64ObservableCounter, len(counters))
+	observables := make([]metric.Observable, len(counters))
+	for i, c := range counters {
+		instruments[i], err = meter.Int64ObservableCounter(c.name, metric.WithDescription(c.description), metric.WithUnit(c.unit))
+		if err != nil {
+			statsConn.Disconnect()
+			return errors.Wrapf(err, "failed to create counter %s", c.name)
+		}
+		observables[i] = instruments[i]
+	}
+
+	registration, err := meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		ifaces := memifInterfaces(connections())
+		if len(ifaces) == 0 {
+			return nil
+		}
+		stats := new(api.InterfaceStats)
+		if statsErr := statsConn.GetInterfaceStats(stats); statsErr != nil {
+			return errors.Wrap(statsErr, "failed to get VPP interface stats")
+		}
+		for idx := range stats.Interfaces {
+			iface := &stats.Interfaces[idx]
+			info, ok := ifaces[iface.InterfaceIndex]
+			if !ok {
+				continue
+			}
+			attributes := metric.WithAttributes(
+				attribute.String("network_service", info.NetworkService),
+				attribute.String("connection", info.ID),
+			)
+			for i, c := range counters {
+				o.ObserveInt64(instruments[i], int64(c.value(iface)), attributes)
+			}
+		}
+		return nil
+	}, observables...)
+	if err != nil {
+		statsConn.Disconnect()
+		return errors.Wrap(err, "failed to register VPP stats callback")
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = registration.Unregister()
+		statsConn.Disconnect()
+	}()
+	return nil
+}
+
+// memifInterfaces returns the connections with the memif mechanism by their VPP interface indexes
+func memifInterfaces(connections []*registry.Info) map[uint32]*registry.Info {
+	result := make(map[uint32]*registry.Info)
+	for _, info := range connections {
+		if info.Mechanism == memif.MECHANISM && info.IfIndex != 0 {
+			result[info.IfIndex] = info
+		}
+	}
+	return result
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tlsprofile provides the hardening of the mTLS client config: TLS versions, cipher suites and profiles
+package tlsprofile
+
+import (
+	"crypto/tls"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Profiles
+const (
+	// DefaultProfile uses the Go defaults restricted by the config
+	DefaultProfile = "default"
+	// FIPSProfile allows FIPS 140-2 approved TLS 1.2 ECDHE AES-GCM cipher suites and NIST curves only. TLS 1.3 is
+	// disabled since its cipher suites can't be restricted.
+	FIPSProfile = "fips"
+)
+
+// versions are the allowed TLS versions, the older ones are insecure
+var versions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var fipsCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+}
+
+var fipsCurves = []tls.CurveID{
+	tls.CurveP256,
+	tls.CurveP384,
+}
+
+// Config is the TLS hardening config
+type Config struct {
+	// Profile is DefaultProfile or FIPSProfile, DefaultProfile if empty
+	Profile string
+	// MinVersion is the minimum TLS version: 1.2 or 1.3, not changed if empty
+	MinVersion string
+	// MaxVersion is the maximum TLS version: 1.2 or 1.3, not changed if empty
+	MaxVersion string
+	// CipherSuites are the names of the allowed TLS 1.0-1.2 cipher suites, e.g. TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	// not changed if empty
+	CipherSuites []string
+}
+
+// Apply applies the config to tlsConfig
+func Apply(tlsConfig *tls.Config, config *Config) error {
+	switch strings.ToLower(config.Profile) {
+	case "", DefaultProfile:
+	case FIPSProfile:
+		tlsConfig.MinVersion = tls.VersionTLS12
+		tlsConfig.MaxVersion = tls.VersionTLS12
+		tlsConfig.CipherSuites = fipsCipherSuites
+		tlsConfig.CurvePreferences = fipsCurves
+	default:
+		return errors.Errorf("invalid TLS profile: %s", config.Profile)
+	}
+
+	if config.MinVersion != "" {
+		version, ok := versions[config.MinVersion]
+		if !ok {
+			return errors.Errorf("invalid TLS min version: %s", config.MinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+	if config.MaxVersion != "" {
+		version, ok := versions[config.MaxVersion]
+		if !ok {
+			return errors.Errorf("invalid TLS max version: %s", config.MaxVersion)
+		}
+		tlsConfig.MaxVersion = version
+	}
+	if tlsConfig.MaxVersion != 0 && tlsConfig.MaxVersion < tlsConfig.MinVersion {
+		return errors.Errorf("TLS max version %s is less than min version %s",
+			tls.VersionName(tlsConfig.MaxVersion), tls.VersionName(tlsConfig.MinVersion))
+	}
+
+	if len(config.CipherSuites) > 0 {
+		cipherSuites, err := parseCipherSuites(config.CipherSuites)
+		if err != nil {
+			return err
+		}
+		if strings.EqualFold(config.Profile, FIPSProfile) {
+			for _, id := range cipherSuites {
+				if !contains(fipsCipherSuites, id) {
+					return errors.Errorf("cipher suite %s is not allowed by the %s profile", tls.CipherSuiteName(id), FIPSProfile)
+				}
+			}
+		}
+		tlsConfig.CipherSuites = cipherSuites
+	}
+	return nil
+}
+
+func parseCipherSuites(names []string) ([]uint16, error) {
+	ids := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		ids[suite.Name] = suite.ID
+	}
+	var result []uint16
+	for _, name := range names {
+		id, ok := ids[strings.TrimSpace(name)]
+		if !ok {
+			return nil, errors.Errorf("unknown or insecure cipher suite: %s", name)
+		}
+		result = append(result, id)
+	}
+	return result, nil
+}
+
+func contains(ids []uint16, id uint16) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
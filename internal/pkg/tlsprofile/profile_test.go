@@ -0,0 +1,133 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlsprofile_test
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/tlsprofile"
+)
+
+func TestApply(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		config   tlsprofile.Config
+		expected *tls.Config
+		err      bool
+	}{
+		{
+			name:     "default",
+			expected: &tls.Config{MinVersion: tls.VersionTLS12},
+		},
+		{
+			name:     "versions",
+			config:   tlsprofile.Config{Profile: tlsprofile.DefaultProfile, MinVersion: "1.3", MaxVersion: "1.3"},
+			expected: &tls.Config{MinVersion: tls.VersionTLS13, MaxVersion: tls.VersionTLS13},
+		},
+		{
+			name: "fips",
+			config: tlsprofile.Config{
+				Profile: "FIPS",
+			},
+			expected: &tls.Config{
+				MinVersion: tls.VersionTLS12,
+				MaxVersion: tls.VersionTLS12,
+				CipherSuites: []uint16{
+					tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+					tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+					tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+					tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+				},
+				CurvePreferences: []tls.CurveID{tls.CurveP256, tls.CurveP384},
+			},
+		},
+		{
+			name: "fips cipher suites",
+			config: tlsprofile.Config{
+				Profile:      tlsprofile.FIPSProfile,
+				CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384", " TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+			},
+			expected: &tls.Config{
+				MinVersion: tls.VersionTLS12,
+				MaxVersion: tls.VersionTLS12,
+				CipherSuites: []uint16{
+					tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+					tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+				},
+				CurvePreferences: []tls.CurveID{tls.CurveP256, tls.CurveP384},
+			},
+		},
+		{
+			name:   "cipher suites",
+			config: tlsprofile.Config{CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256"}},
+			expected: &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256},
+			},
+		},
+		{
+			name:   "fips disallowed cipher suite",
+			config: tlsprofile.Config{Profile: tlsprofile.FIPSProfile, CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256"}},
+			err:    true,
+		},
+		{
+			name:   "insecure cipher suite",
+			config: tlsprofile.Config{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+			err:    true,
+		},
+		{
+			name:   "invalid profile",
+			config: tlsprofile.Config{Profile: "modern"},
+			err:    true,
+		},
+		{
+			name:   "invalid min version",
+			config: tlsprofile.Config{MinVersion: "1.1"},
+			err:    true,
+		},
+		{
+			name:   "invalid max version",
+			config: tlsprofile.Config{MaxVersion: "1.0"},
+			err:    true,
+		},
+		{
+			name:   "max version less than min version",
+			config: tlsprofile.Config{MinVersion: "1.3", MaxVersion: "1.2"},
+			err:    true,
+		},
+		{
+			name:   "fips max version",
+			config: tlsprofile.Config{Profile: tlsprofile.FIPSProfile, MinVersion: "1.3"},
+			err:    true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+			err := tlsprofile.Apply(tlsConfig, &tc.config)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, tlsConfig)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vcl provides a client chain element enabling VPP host stack on the connection interfaces, so the
+// applications use the VCL sockets over the NSM connections instead of raw memif
+package vcl
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/session"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// maxNamespaceIDLen is the length limit of VPP app namespace IDs
+const maxNamespaceIDLen = 64
+
+var invalidNamespaceChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+type vclClient struct {
+	vppConn api.Connection
+	dir     string
+	secret  uint64
+
+	mu         sync.Mutex
+	enabled    bool
+	namespaces map[string]interface_types.InterfaceIndex
+}
+
+// NewClient returns a client chain element adding a VPP app namespace bound to the interface of every established
+// connection and writing the VCL config of the namespace to <dir>/<id>.conf. The applications set VCL_CONFIG to the
+// file and attach to VPP through the app socket <dir>/<id>.sock. It should be placed before the mechanism client
+// to get the VPP interface index.
+func NewClient(vppConn api.Connection, dir string) (networkservice.NetworkServiceClient, error) {
+	var secret [8]byte
+	if _, err := rand.Read(secret[:]); err != nil {
+		return nil, errors.Wrap(err, "failed to generate app namespace secret")
+	}
+	return &vclClient{
+		vppConn:    vppConn,
+		dir:        dir,
+		secret:     binary.BigEndian.Uint64(secret[:]),
+		namespaces: make(map[string]interface_types.InterfaceIndex),
+	}, nil
+}
+
+func (c *vclClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	swIfIndex, ok := ifindex.Load(ctx, true)
+	if !ok {
+		return conn, nil
+	}
+	if addErr := c.add(ctx, conn.GetId(), swIfIndex); addErr != nil {
+		log.FromContext(ctx).Errorf("failed to enable VCL on connection %s: %s", conn.GetId(), addErr.Error())
+	}
+	return conn, nil
+}
+
+func (c *vclClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	if delErr := c.del(ctx, conn.GetId()); delErr != nil {
+		log.FromContext(ctx).Errorf("failed to disable VCL on connection %s: %s", conn.GetId(), delErr.Error())
+	}
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
+
+func (c *vclClient) add(ctx context.Context, id string, swIfIndex interface_types.InterfaceIndex) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.enable(ctx); err != nil {
+		return err
+	}
+	if index, ok := c.namespaces[id]; ok && index == swIfIndex {
+		return nil
+	}
+
+	namespaceID := namespaceID(id)
+	if _, err := session.NewServiceClient(c.vppConn).AppNamespaceAddDelV3(ctx, &session.AppNamespaceAddDelV3{
+		Secret:      c.secret,
+		IsAdd:       true,
+		SwIfIndex:   swIfIndex,
+		NamespaceID: namespaceID,
+		SockName:    c.socketPath(namespaceID),
+	}); err != nil {
+		return errors.Wrapf(err, "vppapi AppNamespaceAddDelV3 returned error for namespace %s", namespaceID)
+	}
+	c.namespaces[id] = swIfIndex
+
+	configPath := filepath.Join(c.dir, namespaceID+".conf")
+	if err := os.WriteFile(configPath, []byte(c.config(namespaceID)), 0o644); err != nil {
+		return errors.Wrapf(err, "failed to write VCL config %s", configPath)
+	}
+	log.FromContext(ctx).Infof("VCL config of connection %s is written to %s", id, configPath)
+	return nil
+}
+
+func (c *vclClient) del(ctx context.Context, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.namespaces[id]; !ok {
+		return nil
+	}
+	delete(c.namespaces, id)
+
+	namespaceID := namespaceID(id)
+	configPath := filepath.Join(c.dir, namespaceID+".conf")
+	if err := os.Remove(configPath); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to remove VCL config %s", configPath)
+	}
+	if _, err := session.NewServiceClient(c.vppConn).AppNamespaceAddDelV3(ctx, &session.AppNamespaceAddDelV3{
+		IsAdd:       false,
+		SwIfIndex:   ^interface_types.InterfaceIndex(0),
+		NamespaceID: namespaceID,
+	}); err != nil {
+		return errors.Wrapf(err, "vppapi AppNamespaceAddDelV3 returned error for namespace %s", namespaceID)
+	}
+	return nil
+}
+
+// enable enables the app socket API and the session layer, the socket API can't be enabled after the session layer
+func (c *vclClient) enable(ctx context.Context) error {
+	if c.enabled {
+		return nil
+	}
+	if _, err := session.NewServiceClient(c.vppConn).SessionSapiEnableDisable(ctx, &session.SessionSapiEnableDisable{
+		IsEnable: true,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi SessionSapiEnableDisable returned error")
+	}
+	if _, err := session.NewServiceClient(c.vppConn).SessionEnableDisable(ctx, &session.SessionEnableDisable{
+		IsEnable: true,
+	}); err != nil {
+		return errors.Wrap(err, "vppapi SessionEnableDisable returned error")
+	}
+	c.enabled = true
+	return nil
+}
+
+func (c *vclClient) socketPath(namespaceID string) string {
+	return filepath.Join(c.dir, namespaceID+".sock")
+}
+
+func (c *vclClient) config(namespaceID string) string {
+	return fmt.Sprintf(`vcl {
+  app-socket-api %s
+  namespace-id %s
+  namespace-secret %d
+  app-scope-local
+  app-scope-global
+}
+`, c.socketPath(namespaceID), namespaceID, c.secret)
+}
+
+// namespaceID returns the VPP app namespace ID of the connection, safe to be used as a file name
+func namespaceID(id string) string {
+	result := invalidNamespaceChars.ReplaceAllString(id, "_")
+	if len(result) > maxNamespaceIDLen {
+		result = result[:maxNamespaceIDLen]
+	}
+	return result
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/vppcli"
+)
+
+const (
+	// probeInterval is the interval between the bursts of the echo requests and the time the replies are waited for
+	probeInterval = 0.01
+	probeBurst    = 10
+	mtuInterval   = 0.2
+	// maxProbes limits the echo requests of the throughput probe, so the CLI output of the replies stays small
+	maxProbes = 2000
+)
+
+// Sizes of the IP and ICMP headers of the echo requests
+const (
+	ipv4ICMPHeaders = 28
+	ipv6ICMPHeaders = 48
+)
+
+// Minimal MTU of the IP links
+const (
+	ipv4MinMTU = 68
+	ipv6MinMTU = 1280
+)
+
+var (
+	statisticsLine = regexp.MustCompile(`Statistics: (\d+) sent, (\d+) received`)
+	rttValue       = regexp.MustCompile(`time=([0-9.]+) ms`)
+)
+
+// pingResult is the result of a VPP CLI ping
+type pingResult struct {
+	sent     int
+	received int
+	rtt      time.Duration
+}
+
+// prober sends the ICMP echo requests to the destination in the table with the VPP CLI ping
+type prober struct {
+	vppConn api.Connection
+	dst     net.IP
+	tableID uint32
+}
+
+func (p *prober) headers() int {
+	if p.dst.To4() == nil {
+		return ipv6ICMPHeaders
+	}
+	return ipv4ICMPHeaders
+}
+
+func (p *prober) ping(ctx context.Context, size, repeat, burst int, interval float64) (*pingResult, error) {
+	cmd := fmt.Sprintf("ping %s table-id %d size %d repeat %d burst %d interval %g",
+		p.dst, p.tableID, size-p.headers(), repeat, burst, interval)
+	reply, err := vppcli.Run(ctx, p.vppConn, cmd)
+	if err != nil {
+		return nil, err
+	}
+	match := statisticsLine.FindStringSubmatch(reply)
+	if match == nil {
+		return nil, errors.Errorf("unexpected output of VPP command %q: %s", cmd, reply)
+	}
+	result := new(pingResult)
+	result.sent, _ = strconv.Atoi(match[1])
+	result.received, _ = strconv.Atoi(match[2])
+
+	var total float64
+	rtts := rttValue.FindAllStringSubmatch(reply, -1)
+	for _, rtt := range rtts {
+		value, _ := strconv.ParseFloat(rtt[1], 64)
+		total += value
+	}
+	if len(rtts) > 0 {
+		result.rtt = time.Duration(total / float64(len(rtts)) * float64(time.Millisecond))
+	}
+	return result, nil
+}
+
+// pathMTU returns the size of the largest echo request up to maxMTU answered by the destination, 0 if none is
+func (p *prober) pathMTU(ctx context.Context, maxMTU int) (int, error) {
+	low := ipv4MinMTU
+	if p.dst.To4() == nil {
+		low = ipv6MinMTU
+	}
+	if maxMTU < low {
+		return 0, errors.Errorf("MTU %d is less than the minimal one %d", maxMTU, low)
+	}
+	answered := func(size int) (bool, error) {
+		result, err := p.ping(ctx, size, 1, 1, mtuInterval)
+		if err != nil {
+			return false, err
+		}
+		return result.received > 0, nil
+	}
+
+	// The full size requests pass on the most of the paths, so they are tried before the search
+	if ok, err := answered(maxMTU); ok || err != nil {
+		return maxMTU, err
+	}
+	found, high := 0, maxMTU-1
+	for low <= high {
+		size := (low + high) / 2
+		ok, err := answered(size)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			found, low = size, size+1
+		} else {
+			high = size - 1
+		}
+	}
+	return found, nil
+}
+
+// throughput sends the bursts of the size bytes long echo requests for duration and returns the throughput of
+// the answered ones in kbps
+func (p *prober) throughput(ctx context.Context, size int, duration time.Duration) (uint64, error) {
+	repeat := int(duration.Seconds() / probeInterval)
+	if repeat*probeBurst > maxProbes {
+		repeat = maxProbes / probeBurst
+	}
+	if repeat < 1 {
+		repeat = 1
+	}
+	now := time.Now()
+	result, err := p.ping(ctx, size, repeat, probeBurst, probeInterval)
+	if err != nil {
+		return 0, err
+	}
+	elapsed := time.Since(now).Seconds()
+	return uint64(float64(result.received*size*8) / elapsed / 1000), nil
+}
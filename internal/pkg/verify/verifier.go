@@ -0,0 +1,245 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package verify probes the datapath of the established connections: the path MTU, the latency and the throughput
+// to the NSE, so the NSC is not ready until the connections meet the configured thresholds
+package verify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/golang/protobuf/ptypes/empty"
+	interfaces "github.com/networkservicemesh/govpp/binapi/interface"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+const (
+	defaultMTU     = 1500
+	verifyTimeout  = time.Minute
+	latencyProbes  = 5
+	latencyTimeout = 0.2
+	// latencyPayload is the ICMP payload size of the latency probes, the default of ping
+	latencyPayload = 56
+)
+
+// Thresholds are the minimal qualities of the connection datapath, the zero ones are not verified
+type Thresholds struct {
+	// MinPathMTU is the minimal size of the IP packets passing the path to the NSE
+	MinPathMTU int
+	// MaxLatency is the maximal average round trip time to the NSE
+	MaxLatency time.Duration
+	// MinThroughput is the minimal throughput of the echo requests answered by the NSE in kbps
+	MinThroughput uint64
+}
+
+// IsZero returns true if no threshold is set
+func (t *Thresholds) IsZero() bool {
+	return t.MinPathMTU == 0 && t.MaxLatency == 0 && t.MinThroughput == 0
+}
+
+type verification struct {
+	key    string
+	cancel context.CancelFunc
+	done   bool
+	err    error
+}
+
+// Verifier verifies the datapath of the connections in background once they are established or their endpoint or
+// interface changes
+type Verifier struct {
+	ctx           context.Context
+	vppConn       api.Connection
+	thresholds    Thresholds
+	probeDuration time.Duration
+
+	mu            sync.Mutex
+	verifications map[string]*verification
+}
+
+// New creates a Verifier probing the throughput for probeDuration. The verifications are stopped once ctx is done.
+func New(ctx context.Context, vppConn api.Connection, thresholds Thresholds, probeDuration time.Duration) *Verifier {
+	return &Verifier{
+		ctx:           ctx,
+		vppConn:       vppConn,
+		thresholds:    thresholds,
+		probeDuration: probeDuration,
+		verifications: make(map[string]*verification),
+	}
+}
+
+// NewClient returns a client chain element starting the verification of the established connections
+func (v *Verifier) NewClient() networkservice.NetworkServiceClient {
+	return &verifyClient{verifier: v}
+}
+
+// Check returns an error if the datapath of any connection is not verified yet or doesn't meet the thresholds
+func (v *Verifier) Check(_ context.Context) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	ids := make([]string, 0, len(v.verifications))
+	for id := range v.verifications {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		switch verification := v.verifications[id]; {
+		case !verification.done:
+			return errors.Errorf("datapath verification of %s is pending", id)
+		case verification.err != nil:
+			return errors.Wrapf(verification.err, "datapath verification of %s has failed", id)
+		}
+	}
+	return nil
+}
+
+func (v *Verifier) start(conn *networkservice.Connection, swIfIndex interface_types.InterfaceIndex) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key := fmt.Sprintf("%s/%d", conn.GetNetworkServiceEndpointName(), swIfIndex)
+	if current, ok := v.verifications[conn.GetId()]; ok {
+		if current.key == key {
+			return
+		}
+		current.cancel()
+	}
+	ctx, cancel := context.WithTimeout(v.ctx, verifyTimeout)
+	verification := &verification{key: key, cancel: cancel}
+	v.verifications[conn.GetId()] = verification
+
+	conn = conn.Clone()
+	go func() {
+		defer cancel()
+		err := v.verify(ctx, conn, swIfIndex)
+		if ctx.Err() == context.Canceled {
+			return
+		}
+
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		verification.done = true
+		verification.err = err
+	}()
+}
+
+func (v *Verifier) stop(id string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if verification, ok := v.verifications[id]; ok {
+		verification.cancel()
+		delete(v.verifications, id)
+	}
+}
+
+// verify probes the datapath to the first destination address of the connection and checks it meets the thresholds
+func (v *Verifier) verify(ctx context.Context, conn *networkservice.Connection, swIfIndex interface_types.InterfaceIndex) error {
+	logger := log.FromContext(ctx).WithField("connection", conn.GetId())
+
+	dstIPs := conn.GetContext().GetIpContext().GetDstIPNets()
+	if len(dstIPs) == 0 {
+		logger.Infof("no destination address to verify the datapath")
+		return nil
+	}
+	dst := dstIPs[0].IP
+
+	now := time.Now()
+	table, err := interfaces.NewServiceClient(v.vppConn).SwInterfaceGetTable(ctx, &interfaces.SwInterfaceGetTable{
+		SwIfIndex: swIfIndex,
+		IsIPv6:    dst.To4() == nil,
+	})
+	if err != nil {
+		return errors.Wrap(err, "vppapi SwInterfaceGetTable returned error")
+	}
+	logger.
+		WithField("swIfIndex", swIfIndex).
+		WithField("vrfID", table.VrfID).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "SwInterfaceGetTable").Debug("completed")
+	p := &prober{vppConn: v.vppConn, dst: dst, tableID: table.VrfID}
+
+	mtu := int(conn.GetContext().GetMTU())
+	if mtu == 0 {
+		mtu = defaultMTU
+	}
+	if v.thresholds.MinPathMTU > 0 {
+		if mtu, err = p.pathMTU(ctx, mtu); err != nil {
+			return err
+		}
+		logger.Infof("path MTU to %s is %d", dst, mtu)
+		if mtu < v.thresholds.MinPathMTU {
+			return errors.Errorf("path MTU to %s is %d, less than %d", dst, mtu, v.thresholds.MinPathMTU)
+		}
+	}
+	if v.thresholds.MaxLatency > 0 {
+		result, pingErr := p.ping(ctx, p.headers()+latencyPayload, latencyProbes, 1, latencyTimeout)
+		if pingErr != nil {
+			return pingErr
+		}
+		if result.received == 0 {
+			return errors.Errorf("no echo replies from %s", dst)
+		}
+		logger.Infof("latency to %s is %s, %d of %d echo requests answered", dst, result.rtt, result.received, result.sent)
+		if result.rtt > v.thresholds.MaxLatency {
+			return errors.Errorf("latency to %s is %s, more than %s", dst, result.rtt, v.thresholds.MaxLatency)
+		}
+	}
+	if v.thresholds.MinThroughput > 0 {
+		throughput, throughputErr := p.throughput(ctx, mtu, v.probeDuration)
+		if throughputErr != nil {
+			return throughputErr
+		}
+		logger.Infof("throughput to %s is %d kbps", dst, throughput)
+		if throughput < v.thresholds.MinThroughput {
+			return errors.Errorf("throughput to %s is %d kbps, less than %d kbps", dst, throughput, v.thresholds.MinThroughput)
+		}
+	}
+	return nil
+}
+
+type verifyClient struct {
+	verifier *Verifier
+}
+
+func (c *verifyClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if swIfIndex, ok := ifindex.Load(ctx, true); ok {
+		c.verifier.start(conn, swIfIndex)
+	}
+	return conn, nil
+}
+
+func (c *verifyClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	c.verifier.stop(conn.GetId())
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package version provides the build info of the NSC and the version of VPP it is connected to
+package version
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/govpp/binapi/vpe"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/vppcli"
+)
+
+// Version is the version of the NSC, set at build time with
+// -ldflags "-X github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/version.Version=v1.0.0"
+var Version = "dev"
+
+const (
+	sdkModule    = "github.com/networkservicemesh/sdk"
+	sdkVPPModule = "github.com/networkservicemesh/sdk-vpp"
+)
+
+// Info is the build info of the NSC and the version of VPP it is connected to
+type Info struct {
+	Version    string   `json:"version"`
+	Commit     string   `json:"commit,omitempty"`
+	GoVersion  string   `json:"goVersion"`
+	SDK        string   `json:"sdk,omitempty"`
+	SDKVPP     string   `json:"sdkVpp,omitempty"`
+	VPP        string   `json:"vpp,omitempty"`
+	VPPPlugins []string `json:"vppPlugins,omitempty"`
+}
+
+// Get returns the build info of the binary
+func Get() *Info {
+	info := &Info{
+		Version:   Version,
+		GoVersion: runtime.Version(),
+	}
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	var modified bool
+	for _, setting := range buildInfo.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if modified && info.Commit != "" {
+		info.Commit += "-dirty"
+	}
+	for _, dep := range buildInfo.Deps {
+		switch dep.Path {
+		case sdkModule:
+			info.SDK = moduleVersion(dep)
+		case sdkVPPModule:
+			info.SDKVPP = moduleVersion(dep)
+		}
+	}
+	return info
+}
+
+// moduleVersion returns the version of the module with the replacement if it is replaced
+func moduleVersion(module *debug.Module) string {
+	if module.Replace == nil {
+		return module.Version
+	}
+	replace := module.Replace.Path
+	if module.Replace.Version != "" && module.Replace.Version != "(devel)" {
+		replace += "@" + module.Replace.Version
+	}
+	return fmt.Sprintf("%s => %s", module.Version, replace)
+}
+
+// WithVPP returns a copy of the info with the version and the loaded plugins of VPP on vppConn
+func (i *Info) WithVPP(ctx context.Context, vppConn api.Connection) (*Info, error) {
+	now := time.Now()
+	reply, err := vpe.NewServiceClient(vppConn).ShowVersion(ctx, &vpe.ShowVersion{})
+	if err != nil {
+		return nil, errors.Wrap(err, "vppapi ShowVersion returned error")
+	}
+	log.FromContext(ctx).
+		WithField("version", reply.Version).
+		WithField("duration", time.Since(now)).
+		WithField("vppapi", "ShowVersion").Debug("completed")
+
+	plugins, err := vppcli.Run(ctx, vppConn, "show plugins")
+	if err != nil {
+		return nil, err
+	}
+
+	info := *i
+	info.VPP = reply.Version
+	info.VPPPlugins = parsePlugins(plugins)
+	return &info, nil
+}
+
+// parsePlugins returns the plugin names from the "show plugins" output rows like
+// "  1. memif_plugin.so    23.02-release    Packet Memory Interface (memif) -- Experimental"
+func parsePlugins(output string) []string {
+	var plugins []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.HasSuffix(fields[0], ".") {
+			continue
+		}
+		if strings.Trim(fields[0], "0123456789") != "." {
+			continue
+		}
+		plugins = append(plugins, strings.TrimSuffix(fields[1], ".so"))
+	}
+	return plugins
+}
+
+// String returns the info as "name: value" lines
+func (i *Info) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "version: %s\n", i.Version)
+	for _, field := range []struct{ name, value string }{
+		{"commit", i.Commit},
+		{"go", i.GoVersion},
+		{"sdk", i.SDK},
+		{"sdk-vpp", i.SDKVPP},
+		{"vpp", i.VPP},
+		{"vpp plugins", strings.Join(i.VPPPlugins, ", ")},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(&sb, "%s: %s\n", field.name, field.value)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vppcli runs VPP CLI commands through the binary API
+package vppcli
+
+import (
+	"context"
+	"strings"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/govpp/binapi/vlib"
+)
+
+// Run runs the VPP CLI command and returns its output
+func Run(ctx context.Context, vppConn api.Connection, cmd string) (string, error) {
+	reply, err := vlib.NewServiceClient(vppConn).CliInband(ctx, &vlib.CliInband{Cmd: cmd})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to run VPP command %q", cmd)
+	}
+	return reply.Reply, nil
+}
+
+// Exec runs the VPP CLI command printing nothing on success. VPP doesn't return the CLI errors in the retval, so any
+// output is returned as an error.
+func Exec(ctx context.Context, vppConn api.Connection, cmd string) error {
+	reply, err := Run(ctx, vppConn, cmd)
+	if err != nil {
+		return err
+	}
+	if reply = strings.TrimSpace(reply); reply != "" {
+		return errors.Errorf("VPP command %q has failed: %s", cmd, reply)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package vppconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// CPU is the VPP threads configuration
+type CPU struct {
+	// MainCore is the CPU the VPP main thread is pinned to, not pinned if negative
+	MainCore int
+	// CorelistWorkers is the list of CPUs VPP worker threads are pinned to, e.g. 2-3,5
+	CorelistWorkers string
+	// Workers is the number of VPP worker threads, can't be used together with CorelistWorkers
+	Workers int
+}
+
+// Section validates the CPU configuration against the cpuset of the process and returns the VPP cpu section.
+// It returns an empty string if there is nothing to configure, so VPP runs single-threaded.
+func (c *CPU) Section() (string, error) {
+	if c.MainCore < 0 && c.CorelistWorkers == "" && c.Workers <= 0 {
+		return "", nil
+	}
+	if c.CorelistWorkers != "" && c.Workers > 0 {
+		return "", errors.New("corelist-workers and workers can't be used together")
+	}
+	if c.Workers < 0 {
+		return "", errors.Errorf("invalid number of workers: %d", c.Workers)
+	}
+
+	var cpuset unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &cpuset); err != nil {
+		return "", errors.Wrap(err, "failed to get the cpuset")
+	}
+
+	var lines []string
+	if c.MainCore >= 0 {
+		if !cpuset.IsSet(c.MainCore) {
+			return "", errors.Errorf("main-core %d is not in the cpuset", c.MainCore)
+		}
+		lines = append(lines, fmt.Sprintf("main-core %d", c.MainCore))
+	}
+	if c.CorelistWorkers != "" {
+		cores, err := parseCorelist(c.CorelistWorkers)
+		if err != nil {
+			return "", err
+		}
+		for _, core := range cores {
+			if !cpuset.IsSet(core) {
+				return "", errors.Errorf("worker core %d is not in the cpuset", core)
+			}
+			if core == c.MainCore {
+				return "", errors.Errorf("worker core %d is the main-core", core)
+			}
+		}
+		lines = append(lines, "corelist-workers "+c.CorelistWorkers)
+	}
+	if c.Workers > 0 {
+		if c.Workers >= cpuset.Count() {
+			return "", errors.Errorf("%d workers don't fit in the cpuset of %d CPUs", c.Workers, cpuset.Count())
+		}
+		lines = append(lines, fmt.Sprintf("workers %d", c.Workers))
+	}
+	return "cpu {\n  " + strings.Join(lines, "\n  ") + "\n}", nil
+}
+
+func parseCorelist(corelist string) ([]int, error) {
+	var cores []int
+	for _, item := range strings.Split(corelist, ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(item), "-")
+		from, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid corelist: %s", corelist)
+		}
+		to := from
+		if isRange {
+			if to, err = strconv.Atoi(last); err != nil {
+				return nil, errors.Wrapf(err, "invalid corelist: %s", corelist)
+			}
+		}
+		if from < 0 || to < from {
+			return nil, errors.Errorf("invalid corelist: %s", corelist)
+		}
+		for core := from; core <= to; core++ {
+			cores = append(cores, core)
+		}
+	}
+	return cores, nil
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vppconfig builds the startup config of the VPP instance started by the NSC
+package vppconfig
+
+import (
+	"os"
+	"strings"
+
+	"github.com/edwarnicke/vpphelper"
+	"github.com/pkg/errors"
+)
+
+// Filename is the file vpphelper writes the VPP config to, vpphelper keeps the file if it already exists
+const Filename = "/etc/vpp/helper/vpp.conf"
+
+// DefaultTemplate is the default vpp.conf template, %[1]s is replaced with the VPP root dir
+const DefaultTemplate = `unix {
+  nodaemon
+  log %[1]s/var/log/vpp/vpp.log
+  full-coredump
+  cli-listen %[1]s/var/run/vpp/cli.sock
+  gid vpp
+}
+
+buffers {
+  buffers-per-numa 32768
+  default data-size 3776
+}
+
+api-trace {
+  on
+}
+
+api-segment {
+  gid vpp
+}
+
+socksvr {
+  socket-name %[1]s/var/run/vpp/api.sock
+}
+
+statseg {
+  socket-name %[1]s/var/run/vpp/stats.sock
+}
+
+plugins {
+  plugin dpdk_plugin.so { disable }
+}
+`
+
+// Build returns the vpp.conf template read from the configFile, or DefaultTemplate if configFile is empty, with
+// the non-empty fragments appended. It returns an empty template if there is nothing to override, so the vpphelper
+// default is used.
+func Build(configFile string, fragments ...string) (string, error) {
+	var nonEmpty []string
+	for _, fragment := range fragments {
+		if fragment != "" {
+			nonEmpty = append(nonEmpty, fragment)
+		}
+	}
+	if configFile == "" && len(nonEmpty) == 0 {
+		return "", nil
+	}
+
+	template := DefaultTemplate
+	if configFile != "" {
+		data, err := os.ReadFile(configFile) // nolint:gosec
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read VPP config file %s", configFile)
+		}
+		template = string(data)
+	}
+	for _, fragment := range nonEmpty {
+		// Fragments are not templates, so they can contain '%'
+		template = strings.TrimRight(template, "\n") + "\n\n" + strings.ReplaceAll(fragment, "%", "%%") + "\n"
+	}
+	return template, nil
+}
+
+// Options returns vpphelper options starting VPP with the config built from the configFile and the fragments
+func Options(configFile string, fragments ...string) ([]vpphelper.Option, error) {
+	template, err := Build(configFile, fragments...)
+	if err != nil {
+		return nil, err
+	}
+	if template == "" {
+		return nil, nil
+	}
+	// vpphelper doesn't overwrite an existing config file, so remove the one left by the previous run
+	if err := os.Remove(Filename); err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to remove VPP config file %s", Filename)
+	}
+	return []vpphelper.Option{vpphelper.WithVppConfig(template)}, nil
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vppsupervisor
+
+import "time"
+
+// Option is an option pattern for New
+type Option func(s *Supervisor)
+
+// WithMaxRestarts sets how many times VPP is restarted before Supervisor gives up, VPP is never restarted if 0
+func WithMaxRestarts(maxRestarts int) Option {
+	return func(s *Supervisor) {
+		if maxRestarts >= 0 {
+			s.maxRestarts = maxRestarts
+		}
+	}
+}
+
+// WithCheckInterval sets the interval of the VPP API liveness checks
+func WithCheckInterval(checkInterval time.Duration) Option {
+	return func(s *Supervisor) {
+		if checkInterval > 0 {
+			s.checkInterval = checkInterval
+		}
+	}
+}
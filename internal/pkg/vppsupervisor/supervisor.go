@@ -0,0 +1,182 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vppsupervisor keeps the NSC connected to a running VPP, restarting and re-dialing VPP when it fails
+package vppsupervisor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/edwarnicke/vpphelper"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/govpp/binapi/memclnt"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/loglevels"
+)
+
+const failureThreshold = 3
+
+// DialFunc starts or dials VPP. The returned errCh receives an error when the started VPP exits, it is nil if VPP is
+// not started by the NSC.
+type DialFunc func(ctx context.Context) (conn vpphelper.Connection, errCh <-chan error)
+
+// Supervisor is a vpphelper.Connection to the current VPP instance. If VPP exits or its API stops responding,
+// Supervisor restarts it with the DialFunc and notifies the restart handler, so the datapath can be restored.
+type Supervisor struct {
+	dial          DialFunc
+	maxRestarts   int
+	checkInterval time.Duration
+
+	mu        sync.RWMutex
+	conn      vpphelper.Connection
+	onRestart func(ctx context.Context)
+}
+
+// New creates a new Supervisor dialing VPP with dial
+func New(dial DialFunc, opts ...Option) *Supervisor {
+	s := &Supervisor{
+		dial:          dial,
+		maxRestarts:   5,
+		checkInterval: time.Second,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Start dials VPP and supervises it until ctx is done. The returned channel receives an error if VPP has failed
+// more than the max restarts times, it is closed when the last VPP instance exits.
+func (s *Supervisor) Start(ctx context.Context) <-chan error {
+	errCh := make(chan error, 1)
+	vppCtx, cancelVPP := context.WithCancel(ctx)
+	conn, vppErrCh := s.dial(vppCtx)
+	s.mu.Lock()
+	s.conn = conn
+	s.mu.Unlock()
+
+	go s.run(ctx, vppCtx, cancelVPP, vppErrCh, errCh)
+	return errCh
+}
+
+// OnRestart sets the handler called after VPP has been restarted
+func (s *Supervisor) OnRestart(onRestart func(ctx context.Context)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.onRestart = onRestart
+}
+
+func (s *Supervisor) run(ctx, vppCtx context.Context, cancelVPP context.CancelFunc, vppErrCh <-chan error, errCh chan<- error) {
+	defer close(errCh)
+
+	for restarts := 0; ; restarts++ {
+		err := s.watch(vppCtx, s.current(), vppErrCh)
+		cancelVPP()
+		if vppErrCh != nil {
+			for range vppErrCh {
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if restarts >= s.maxRestarts {
+			errCh <- errors.Wrapf(err, "VPP has failed after %d restarts", restarts)
+			return
+		}
+		log.FromContext(ctx).WithField(loglevels.ComponentField, loglevels.VPPComponent).Errorf("restarting VPP: %+v", err)
+
+		var conn vpphelper.Connection
+		vppCtx, cancelVPP = context.WithCancel(ctx)
+		conn, vppErrCh = s.dial(vppCtx)
+		s.mu.Lock()
+		s.conn = conn
+		onRestart := s.onRestart
+		s.mu.Unlock()
+
+		if onRestart != nil {
+			onRestart(ctx)
+		}
+	}
+}
+
+// watch returns an error when VPP exits or its API stops responding after being alive
+func (s *Supervisor) watch(ctx context.Context, conn vpphelper.Connection, vppErrCh <-chan error) error {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	var alive bool
+	var failures int
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-vppErrCh:
+			if err == nil {
+				return errors.New("VPP has exited")
+			}
+			return errors.Wrap(err, "VPP has exited")
+		case <-ticker.C:
+		}
+
+		checkCtx, cancelCheck := context.WithTimeout(ctx, s.checkInterval)
+		_, err := memclnt.NewServiceClient(conn).ControlPing(checkCtx, &memclnt.ControlPing{})
+		cancelCheck()
+		switch {
+		case err == nil:
+			alive = true
+			failures = 0
+		case alive && ctx.Err() == nil:
+			if failures++; failures >= failureThreshold {
+				return errors.Wrap(err, "VPP API is not responding")
+			}
+		}
+	}
+}
+
+func (s *Supervisor) current() vpphelper.Connection {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.conn
+}
+
+// NewStream creates a new stream to the current VPP instance
+func (s *Supervisor) NewStream(ctx context.Context, options ...api.StreamOption) (api.Stream, error) {
+	return s.current().NewStream(ctx, options...)
+}
+
+// Invoke invokes the request on the current VPP instance
+func (s *Supervisor) Invoke(ctx context.Context, req, reply api.Message) error {
+	return s.current().Invoke(ctx, req, reply)
+}
+
+// NewAPIChannel returns a new channel to the current VPP instance
+func (s *Supervisor) NewAPIChannel() (api.Channel, error) {
+	return s.current().NewAPIChannel()
+}
+
+// NewAPIChannelBuffered returns a new buffered channel to the current VPP instance
+func (s *Supervisor) NewAPIChannelBuffered(reqChanBufSize, replyChanBufSize int) (api.Channel, error) {
+	return s.current().NewAPIChannelBuffered(reqChanBufSize, replyChanBufSize)
+}
+
+var _ vpphelper.Connection = &Supervisor{}
@@ -0,0 +1,159 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package x509files provides the X.509 SVID and bundle source reading the certificates and the key from the files,
+// e.g. mounted from the cert-manager secret, instead of the SPIFFE Workload API
+package x509files
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// Source is the X.509 SVID and bundle source reloading the files on change
+type Source struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	updated chan struct{}
+
+	mu          sync.RWMutex
+	data        [][]byte
+	svid        *x509svid.SVID
+	authorities []*x509.Certificate
+}
+
+// New returns the source loading the SVID from the PEM encoded certificate chain in certFile and the private key in
+// keyFile, and the trusted CA certificates from caFile. The files are checked for changes every reloadInterval until
+// ctx is done, the reload is disabled if reloadInterval is 0.
+func New(ctx context.Context, certFile, keyFile, caFile string, reloadInterval time.Duration) (*Source, error) {
+	s := &Source{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		updated:  make(chan struct{}, 1),
+	}
+	if _, err := s.reload(); err != nil {
+		return nil, err
+	}
+	if reloadInterval > 0 {
+		go s.watch(ctx, reloadInterval)
+	}
+	return s, nil
+}
+
+// Updated returns a channel that is sent on whenever the source is reloaded
+func (s *Source) Updated() <-chan struct{} {
+	return s.updated
+}
+
+// GetX509SVID returns the current X.509 SVID
+func (s *Source) GetX509SVID() (*x509svid.SVID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.svid, nil
+}
+
+// GetX509BundleForTrustDomain returns the bundle of the trusted CA certificates for any trust domain, the peers
+// are restricted by the authorizer
+func (s *Source) GetX509BundleForTrustDomain(trustDomain spiffeid.TrustDomain) (*x509bundle.Bundle, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return x509bundle.FromX509Authorities(trustDomain, s.authorities), nil
+}
+
+func (s *Source) watch(ctx context.Context, reloadInterval time.Duration) {
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := s.reload()
+			if err != nil {
+				log.FromContext(ctx).Warnf("failed to reload X.509 files, keeping the previous SVID: %s", err.Error())
+				continue
+			}
+			if reloaded {
+				svid, _ := s.GetX509SVID()
+				log.FromContext(ctx).Infof("reloaded X.509 SVID %q", svid.ID)
+				select {
+				case s.updated <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// reload loads the files if any of them has changed and returns true if the SVID or the bundle has been updated
+func (s *Source) reload() (bool, error) {
+	var data [][]byte
+	for _, path := range []string{s.certFile, s.keyFile, s.caFile} {
+		b, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to read %s", path)
+		}
+		data = append(data, b)
+	}
+
+	s.mu.RLock()
+	changed := false
+	for i := range data {
+		if len(s.data) != len(data) || !bytes.Equal(s.data[i], data[i]) {
+			changed = true
+			break
+		}
+	}
+	s.mu.RUnlock()
+	if !changed {
+		return false, nil
+	}
+
+	svid, err := x509svid.Parse(data[0], data[1])
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to parse X.509 SVID from %s and %s", s.certFile, s.keyFile)
+	}
+	bundle, err := x509bundle.Parse(svid.ID.TrustDomain(), data[2])
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to parse CA certificates from %s", s.caFile)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data = data
+	s.svid = svid
+	s.authorities = bundle.X509Authorities()
+	return true, nil
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plugins provides the registration of the extensions compiled into the NSC: the additional client chain
+// elements, liveness checks and mechanism handlers. The extensions register themselves in init and are compiled in
+// by the blank imports from the plugin_*.go files of the main package guarded by build tags, so main.go is not
+// modified.
+package plugins
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/edwarnicke/vpphelper"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/heal"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/config"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/nsc"
+)
+
+// Env is the environment the extensions are created in
+type Env struct {
+	// Config is the configuration of the NSC
+	Config *config.Config
+	// VPPConn is the VPP API connection
+	VPPConn vpphelper.Connection
+}
+
+// ClientFunc returns the options of the client chain, e.g. nsc.WithBefore and nsc.WithAfter inserting the additional
+// elements around the built-in ones
+type ClientFunc func(ctx context.Context, env *Env) ([]nsc.Option, error)
+
+// LivenessCheckFunc returns the datapath liveness check of the connections
+type LivenessCheckFunc func(ctx context.Context, env *Env) (heal.LivenessCheck, error)
+
+// MechanismFunc returns the client handling the mechanism. It gets only the mechanism preferences of its type.
+type MechanismFunc func(ctx context.Context, env *Env) networkservice.NetworkServiceClient
+
+type clientPlugin struct {
+	name string
+	f    ClientFunc
+}
+
+var (
+	mu             sync.Mutex
+	clients        []clientPlugin
+	livenessChecks = make(map[string]LivenessCheckFunc)
+	mechanisms     = make(map[string]MechanismFunc)
+)
+
+// RegisterClient registers the extension of the client chain with the name. The options of the extensions are
+// applied in the registration order. It panics if the name is already registered.
+func RegisterClient(name string, f ClientFunc) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, c := range clients {
+		if c.name == name {
+			panic("plugins: client " + name + " is already registered")
+		}
+	}
+	clients = append(clients, clientPlugin{name: name, f: f})
+}
+
+// RegisterLivenessCheck registers the liveness check selected by the LivenessCheck config value strategy. It
+// panics if the strategy is already registered.
+func RegisterLivenessCheck(strategy string, f LivenessCheckFunc) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := livenessChecks[strategy]; ok {
+		panic("plugins: liveness check " + strategy + " is already registered")
+	}
+	livenessChecks[strategy] = f
+}
+
+// RegisterMechanism registers the handler of the mechanism type replacing the built-in one if any. It panics if the
+// mechanism is already registered.
+func RegisterMechanism(mechanism string, f MechanismFunc) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := mechanisms[mechanism]; ok {
+		panic("plugins: mechanism " + mechanism + " is already registered")
+	}
+	mechanisms[mechanism] = f
+}
+
+// ClientOptions returns the options of the client chain of all the registered extensions
+func ClientOptions(ctx context.Context, env *Env) ([]nsc.Option, error) {
+	mu.Lock()
+	registered := append([]clientPlugin(nil), clients...)
+	mu.Unlock()
+
+	var opts []nsc.Option
+	for _, c := range registered {
+		clientOpts, err := c.f(ctx, env)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create client plugin %s", c.name)
+		}
+		opts = append(opts, clientOpts...)
+	}
+	return opts, nil
+}
+
+// LivenessCheck returns the registered liveness check for the strategy
+func LivenessCheck(strategy string) (LivenessCheckFunc, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	f, ok := livenessChecks[strategy]
+	return f, ok
+}
+
+// Mechanisms returns the clients of all the registered mechanism handlers by the mechanism type
+func Mechanisms(ctx context.Context, env *Env) map[string]networkservice.NetworkServiceClient {
+	mu.Lock()
+	registered := make(map[string]MechanismFunc, len(mechanisms))
+	for mechanism, f := range mechanisms {
+		registered[mechanism] = f
+	}
+	mu.Unlock()
+
+	result := make(map[string]networkservice.NetworkServiceClient, len(registered))
+	for mechanism, f := range registered {
+		result[mechanism] = f(ctx, env)
+	}
+	return result
+}
+
+// MechanismTypes returns the sorted types of the mechanisms handled by the registered mechanism handlers
+func MechanismTypes() []string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var types []string
+	for mechanism := range mechanisms {
+		types = append(types, mechanism)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Names returns the sorted names of all the registered extensions
+func Names() []string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var names []string
+	for _, c := range clients {
+		names = append(names, "client:"+c.name)
+	}
+	for strategy := range livenessChecks {
+		names = append(names, "liveness:"+strategy)
+	}
+	for mechanism := range mechanisms {
+		names = append(names, "mechanism:"+mechanism)
+	}
+	sort.Strings(names)
+	return names
+}
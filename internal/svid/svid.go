@@ -0,0 +1,104 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package svid provides the X.509 SVID of the NSC fetched from the files or from the SPIFFE Workload API
+package svid
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/config"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/x509files"
+)
+
+// Source is the source of the X.509 SVID and the trust bundles
+type Source interface {
+	x509svid.Source
+	x509bundle.Source
+	Updated() <-chan struct{}
+}
+
+// NewSource returns the X.509 source from the files or from the SPIFFE Workload API retrying with backoff until
+// X.509 SVID is fetched or cfg.SvidMaxWait has elapsed
+func NewSource(ctx context.Context, cfg *config.Config) (Source, error) {
+	waitCtx := ctx
+	if cfg.SvidMaxWait > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, cfg.SvidMaxWait)
+		defer cancel()
+	}
+
+	var sourceOptions []workloadapi.X509SourceOption
+	if cfg.WorkloadAPIAddress != "" {
+		sourceOptions = append(sourceOptions, workloadapi.WithClientOptions(workloadapi.WithAddr(cfg.WorkloadAPIAddress)))
+	}
+
+	backoff := cfg.DialBackoff
+	for attempt := 1; ; attempt++ {
+		var source Source
+		var err error
+		if cfg.X509CertFile != "" {
+			source, err = x509files.New(ctx, cfg.X509CertFile, cfg.X509KeyFile, cfg.X509CaFile, cfg.X509ReloadInterval)
+		} else {
+			fetchCtx, cancelFetch := context.WithTimeout(waitCtx, cfg.SvidFetchTimeout)
+			source, err = workloadapi.NewX509Source(fetchCtx, sourceOptions...)
+			cancelFetch()
+		}
+		if err == nil {
+			return source, nil
+		}
+		log.FromContext(ctx).Warnf("attempt %d to get X.509 SVID has failed, retrying in %s: %v", attempt, backoff, err.Error())
+
+		select {
+		case <-waitCtx.Done():
+			return nil, errors.Wrapf(err, "X.509 SVID is not available after %d attempts", attempt)
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > cfg.DialMaxBackoff {
+			backoff = cfg.DialMaxBackoff
+		}
+	}
+}
+
+// WatchRotation calls onRotate every time the source is updated with the new X.509 SVID until ctx is done
+func WatchRotation(ctx context.Context, source Source, onRotate func()) {
+	current, _ := source.GetX509SVID()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-source.Updated():
+		}
+		svid, err := source.GetX509SVID()
+		if err != nil || svid == nil || len(svid.Certificates) == 0 {
+			continue
+		}
+		if current != nil && len(current.Certificates) > 0 && current.Certificates[0].Equal(svid.Certificates[0]) {
+			continue
+		}
+		current = svid
+		log.FromContext(ctx).WithField("expiresAt", svid.Certificates[0].NotAfter).Infof("X.509 SVID %q has been rotated", svid.ID)
+		onRotate()
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2023 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+// Package vppinit provides the VPP lifecycle of the NSC: starting or dialing VPP with restarts on failures and
+// draining the connections before VPP is stopped
+package vppinit
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/edwarnicke/vpphelper"
+	interfaces "github.com/networkservicemesh/govpp/binapi/interface"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/config"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/connections"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/vppconfig"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/vppsupervisor"
+)
+
+// Wrapper wraps the dialed VPP API connection, e.g. to trace or to fail the VPP API calls
+type Wrapper func(conn vpphelper.Connection) vpphelper.Connection
+
+// NewDialFunc returns the function connecting to the existing VPP on config.VppAPISocket or starting a new VPP
+// instance configured by config if it is empty. The dialed connections are wrapped with wrappers in order.
+func NewDialFunc(config *config.Config, wrappers ...Wrapper) (vppsupervisor.DialFunc, error) {
+	var dial vppsupervisor.DialFunc
+	if config.VppAPISocket != "" { // If we have a VppAPISocket, use that
+		dial = func(ctx context.Context) (vpphelper.Connection, <-chan error) {
+			return vpphelper.DialContext(ctx, config.VppAPISocket), nil
+		}
+	} else { // If we don't have a VPPAPISocket, start VPP and use that
+		cpu := &vppconfig.CPU{
+			MainCore:        config.VppMainCore,
+			CorelistWorkers: config.VppCorelistWorkers,
+			Workers:         config.VppWorkers,
+		}
+		cpuSection, err := cpu.Section()
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid VPP cpu config")
+		}
+		vppOpts, err := vppconfig.Options(config.VppConfigFile, cpuSection, config.VppInit)
+		if err != nil {
+			return nil, errors.Wrap(err, "error building VPP config")
+		}
+		dial = func(ctx context.Context) (vpphelper.Connection, <-chan error) {
+			return vpphelper.StartAndDialContext(ctx, vppOpts...)
+		}
+	}
+	if len(wrappers) == 0 {
+		return dial, nil
+	}
+	return func(ctx context.Context) (vpphelper.Connection, <-chan error) {
+		conn, errCh := dial(ctx)
+		for _, wrap := range wrappers {
+			conn = wrap(conn)
+		}
+		return conn, errCh
+	}, nil
+}
+
+// Start starts the supervisor of the VPP connections dialed with dial restarting VPP up to config.VppMaxRestarts
+// times. The returned channel receives an error if VPP can't be restarted and is closed once ctx is done.
+func Start(ctx context.Context, config *config.Config, dial vppsupervisor.DialFunc) (*vppsupervisor.Supervisor, <-chan error) {
+	vppConn := vppsupervisor.New(dial, vppsupervisor.WithMaxRestarts(config.VppMaxRestarts))
+	return vppConn, vppConn.Start(ctx)
+}
+
+// Drain closes all the connections in parallel and waits for their VPP interfaces to be deleted, so VPP can be
+// stopped. It uses a separate context, as ctx may be already canceled on shutdown.
+func Drain(ctx context.Context, timeout time.Duration, connManager *connections.Manager, vppConn vpphelper.Connection, ifIndexes func() []uint32) {
+	log.FromContext(ctx).Infof("draining connections")
+	start := time.Now()
+
+	drainCtx, cancelDrain := context.WithTimeout(log.WithLog(context.Background(), log.FromContext(ctx)), timeout)
+	defer cancelDrain()
+
+	attached := ifIndexes()
+	connManager.CloseAll(drainCtx)
+	if err := waitInterfacesDeleted(drainCtx, vppConn, attached); err != nil {
+		log.FromContext(ctx).Warnf("failed to drain connections: %+v", err)
+		return
+	}
+	log.FromContext(ctx).WithField("duration", time.Since(start)).Infof("connections are drained")
+}
+
+// waitInterfacesDeleted waits until the VPP interfaces with ifIndexes are deleted
+func waitInterfacesDeleted(ctx context.Context, vppConn vpphelper.Connection, ifIndexes []uint32) error {
+	const pollInterval = 100 * time.Millisecond
+	for len(ifIndexes) > 0 {
+		var remaining []uint32
+		for _, ifIndex := range ifIndexes {
+			stream, err := interfaces.NewServiceClient(vppConn).SwInterfaceDump(ctx, &interfaces.SwInterfaceDump{
+				SwIfIndex: interface_types.InterfaceIndex(ifIndex),
+			})
+			if err != nil {
+				return errors.Wrapf(err, "failed to dump VPP interface %d", ifIndex)
+			}
+			var found bool
+			for {
+				if _, err = stream.Recv(); err != nil {
+					break
+				}
+				found = true
+			}
+			if err != io.EOF {
+				return errors.Wrapf(err, "failed to dump VPP interface %d", ifIndex)
+			}
+			if found {
+				remaining = append(remaining, ifIndex)
+			}
+		}
+		if ifIndexes = remaining; len(ifIndexes) == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Errorf("VPP interfaces %v are not deleted", ifIndexes)
+		case <-time.After(pollInterval):
+		}
+	}
+	return nil
+}
@@ -21,8 +21,10 @@ package main
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/pprof"
 	"net/url"
 	"os"
 	"os/signal"
@@ -32,83 +34,63 @@ import (
 
 	nested "github.com/antonfisher/nested-logrus-formatter"
 	"github.com/edwarnicke/debug"
-	"github.com/edwarnicke/grpcfd"
-	"github.com/edwarnicke/vpphelper"
-	"github.com/golang/protobuf/ptypes/empty"
-	"github.com/kelseyhightower/envconfig"
-	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
-	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
-	"github.com/spiffe/go-spiffe/v2/workloadapi"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
-	"github.com/networkservicemesh/govpp/binapi/interface_types"
-	"github.com/networkservicemesh/govpp/binapi/ip_types"
-	"github.com/networkservicemesh/govpp/binapi/ping"
-	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/connectioncontext"
-	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/mechanisms/memif"
-	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/up"
 	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
 
-	"github.com/networkservicemesh/sdk/pkg/networkservice/chains/client"
-	"github.com/networkservicemesh/sdk/pkg/networkservice/common/clientinfo"
-	"github.com/networkservicemesh/sdk/pkg/networkservice/common/excludedprefixes"
-	"github.com/networkservicemesh/sdk/pkg/networkservice/common/heal"
-	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/recvfd"
-	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/sendfd"
-	"github.com/networkservicemesh/sdk/pkg/networkservice/common/retry"
-	"github.com/networkservicemesh/sdk/pkg/networkservice/common/upstreamrefresh"
-	"github.com/networkservicemesh/sdk/pkg/tools/awarenessgroups"
 	"github.com/networkservicemesh/sdk/pkg/tools/grpcutils"
 	"github.com/networkservicemesh/sdk/pkg/tools/log"
 	"github.com/networkservicemesh/sdk/pkg/tools/log/logruslogger"
-	"github.com/networkservicemesh/sdk/pkg/tools/nsurl"
 	"github.com/networkservicemesh/sdk/pkg/tools/opentelemetry"
-	"github.com/networkservicemesh/sdk/pkg/tools/spiffejwt"
-	"github.com/networkservicemesh/sdk/pkg/tools/token"
-	"github.com/networkservicemesh/sdk/pkg/tools/tracing"
-)
-
-// Config - configuration for cmd-forwarder-vpp
-type Config struct {
-	Name                  string                  `default:"cmd-nsc-vpp" desc:"Name of Endpoint"`
-	DialTimeout           time.Duration           `default:"5s" desc:"timeout to dial NSMgr" split_words:"true"`
-	RequestTimeout        time.Duration           `default:"35s" desc:"timeout to request NSE" split_words:"true"`
-	ConnectTo             url.URL                 `default:"unix:///var/lib/networkservicemesh/nsm.io.sock" desc:"url to connect to" split_words:"true"`
-	MaxTokenLifetime      time.Duration           `default:"10m" desc:"maximum lifetime of tokens" split_words:"true"`
-	NetworkServices       []url.URL               `default:"" desc:"A list of Network Service Requests" split_words:"true"`
-	AwarenessGroups       awarenessgroups.Decoder `defailt:"" desc:"Awareness groups for mutually aware NSEs" split_words:"true"`
-	LogLevel              string                  `default:"INFO" desc:"Log level" split_words:"true"`
-	OpenTelemetryEndpoint string                  `default:"otel-collector.observability.svc.cluster.local:4317" desc:"OpenTelemetry Collector Endpoint"`
-}
 
-type ifIndexGetClient struct {
-	ifindex *interface_types.InterfaceIndex
-}
+	nscclient "github.com/networkservicemesh/cmd-nsc-vpp/internal/client"
+	nscconfig "github.com/networkservicemesh/cmd-nsc-vpp/internal/config"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/health"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/nsmgr"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/admin"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/apitrace"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/attach"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/benchmark"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/chaos"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/connections"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/connlog"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/debugbundle"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/failover"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/hooks"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/httputils"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/ifmap"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/jwttoken"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/k8sevents"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/labels"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/logfile"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/loglevels"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/metrics"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/mirror"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/packettrace"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/pcap"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/probes"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/qos"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/redundancy"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/registry"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/sdnotify"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/stats"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/verify"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/pkg/version"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/plugins"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/svid"
+	"github.com/networkservicemesh/cmd-nsc-vpp/internal/vppinit"
+)
 
-func NewClient(ctx context.Context, ifindex *interface_types.InterfaceIndex) networkservice.NetworkServiceClient {
-	return &ifIndexGetClient{
-		ifindex: ifindex,
+func main() {
+	buildInfo := version.Get()
+	if len(os.Args) > 1 && os.Args[1] == "--version" {
+		fmt.Print(buildInfo.String())
+		return
 	}
-}
-
-func (u *ifIndexGetClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
-	conn, err := next.Client(ctx).Request(ctx, request, opts...)
-
-	ifindex, _ := ifindex.Load(ctx, true)
-	*u.ifindex = ifindex
-	log.FromContext(ctx).Infof("ifindex: %v", ifindex)
-
-	return conn, err
-}
 
-func (u *ifIndexGetClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
-	return next.Client(ctx).Close(ctx, conn, opts...)
-}
-
-func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -143,20 +125,74 @@ func main() {
 	// ********************************************************************************
 	now := time.Now()
 
-	config := &Config{}
-	if err := envconfig.Usage("nsm", config); err != nil {
+	config := &nscconfig.Config{}
+	loadErr := nscconfig.Load(config)
+	dryRun := config.DryRun || len(os.Args) > 1 && os.Args[1] == "validate"
+	if !dryRun {
+		if err := nscconfig.Usage(config); err != nil {
+			logrus.Fatal(err)
+		}
+	}
+	if loadErr != nil {
+		logrus.Fatal(loadErr)
+	}
+	if dryRun {
+		if !validateConfig(config) {
+			os.Exit(1)
+		}
+		logrus.Info("config is valid")
+		return
+	}
+	// SIGHUP is caught before VPP is started and the first connections are requested, so a reload sent during the
+	// startup is queued and handled once the connection manager is ready instead of killing the NSC
+	reloadCh := make(chan os.Signal, 1)
+	signal.Notify(reloadCh, syscall.SIGHUP)
+
+	log.FromContext(ctx).
+		WithField("commit", buildInfo.Commit).
+		WithField("go", buildInfo.GoVersion).
+		WithField("sdk", buildInfo.SDK).
+		WithField("sdk-vpp", buildInfo.SDKVPP).
+		Infof("version: %s", buildInfo.Version)
+	printedConfig := new(strings.Builder)
+	if err := nscconfig.Print(printedConfig, config); err != nil {
 		logrus.Fatal(err)
 	}
-	if err := envconfig.Process("nsm", config); err != nil {
-		logrus.Fatalf("error processing config from env: %+v", err)
+	log.FromContext(ctx).Infof("Config:\n%s", printedConfig.String())
+	if names := plugins.Names(); len(names) > 0 {
+		log.FromContext(ctx).Infof("plugins: %v", names)
 	}
-	log.FromContext(ctx).Infof("Config: %#v", config)
 
 	l, err := logrus.ParseLevel(config.LogLevel)
 	if err != nil {
 		logrus.Fatalf("invalid log level %s", config.LogLevel)
 	}
 	logrus.SetLevel(l)
+	if config.LogFile != "" {
+		logFile, fileErr := logfile.New(config.LogFile, int64(config.LogFileMaxSize)<<20, config.LogFileMaxBackups)
+		if fileErr != nil {
+			logrus.Fatalf("failed to open log file: %+v", fileErr)
+		}
+		logrus.SetOutput(io.MultiWriter(logrus.StandardLogger().Out, logFile))
+	}
+
+	connLogHook := connlog.NewHook()
+	logrus.AddHook(connLogHook)
+	// The recent log lines are kept only to be written into the debug bundles
+	var logBuffer *debugbundle.LogBuffer
+	if config.DebugBundleDir != "" {
+		logBuffer = debugbundle.NewLogBuffer(config.DebugLogLines)
+		logrus.AddHook(logBuffer)
+	}
+	if len(config.LogLevels) > 0 {
+		levelsHook, levelsErr := loglevels.New(logrus.StandardLogger().Out, config.LogLevels, l)
+		if levelsErr != nil {
+			logrus.Fatalf("invalid log levels: %+v", levelsErr)
+		}
+		logrus.SetOutput(io.Discard)
+		logrus.SetLevel(levelsHook.MaxLevel())
+		logrus.AddHook(levelsHook)
+	}
 
 	log.FromContext(ctx).WithField("duration", time.Since(now)).Infof("completed phase 1: get config from environment")
 
@@ -174,13 +210,46 @@ func main() {
 			}
 		}()
 	}
+	var prometheus *metrics.Prometheus
+	if config.PrometheusListen != "" {
+		if prometheus, err = metrics.NewPrometheus(ctx, config.Name); err != nil {
+			log.FromContext(ctx).Fatalf("failed to init Prometheus metrics: %+v", err)
+		}
+		defer func() {
+			if err = prometheus.Close(context.Background()); err != nil {
+				log.FromContext(ctx).Error(err.Error())
+			}
+		}()
+	}
 
 	// ********************************************************************************
 	log.FromContext(ctx).Infof("executing phase 2: run vpp and get a connection to it (time since start: %s)", time.Since(starttime))
 	// ********************************************************************************
 	now = time.Now()
 
-	vppConn, vppErrCh := vpphelper.StartAndDialContext(ctx)
+	var vppWrappers []vppinit.Wrapper
+	var faults *chaos.Chaos
+	if len(config.ChaosFaults) > 0 {
+		var chaosErr error
+		if faults, chaosErr = chaos.New(config.ChaosFaults, config.ChaosInterval, config.ChaosDuration); chaosErr != nil {
+			log.FromContext(ctx).Fatalf("invalid chaos config: %+v", chaosErr)
+		}
+		log.FromContext(ctx).Warnf("chaos mode is enabled, injecting faults %v", config.ChaosFaults)
+		vppWrappers = append(vppWrappers, faults.Wrap)
+	}
+
+	apiTrace := apitrace.New(config.VppAPITraceSize)
+	var apiTraceClient networkservice.NetworkServiceClient
+	if config.VppAPITraceSize > 0 {
+		vppWrappers = append(vppWrappers, apiTrace.Wrap)
+		apiTraceClient = apiTrace.NewClient()
+	}
+
+	dialVPP, err := vppinit.NewDialFunc(config, vppWrappers...)
+	if err != nil {
+		log.FromContext(ctx).Fatalf("%+v", err)
+	}
+	vppConn, vppErrCh := vppinit.Start(ctx, config, dialVPP)
 	exitOnErrCh(ctx, cancel, vppErrCh)
 
 	defer func() {
@@ -188,6 +257,12 @@ func main() {
 		<-vppErrCh
 	}()
 
+	if vppInfo, vppInfoErr := buildInfo.WithVPP(ctx, vppConn); vppInfoErr != nil {
+		log.FromContext(ctx).Warnf("failed to get VPP version: %+v", vppInfoErr)
+	} else {
+		log.FromContext(ctx).WithField("plugins", vppInfo.VPPPlugins).Infof("VPP version: %s", vppInfo.VPP)
+	}
+
 	log.FromContext(ctx).WithField("duration", time.Since(now)).Info("completed phase 2: run vpp and get a connection to it")
 
 	// ********************************************************************************
@@ -195,206 +270,450 @@ func main() {
 	// ********************************************************************************
 	now = time.Now()
 
-	source, err := workloadapi.NewX509Source(ctx)
+	source, err := svid.NewSource(ctx, config)
 	if err != nil {
 		logrus.Fatalf("error getting x509 source: %+v", err)
 	}
-	svid, err := source.GetX509SVID()
+	x509SVID, err := source.GetX509SVID()
 	if err != nil {
 		logrus.Fatalf("error getting x509 svid: %+v", err)
 	}
-	logrus.Infof("SVID: %q", svid.ID)
+	logrus.Infof("SVID: %q", x509SVID.ID)
 
 	log.FromContext(ctx).WithField("duration", time.Since(now)).Info("completed phase 3: retrieving svid")
 
-	tlsClientConfig := tlsconfig.MTLSClientConfig(source, source, tlsconfig.AuthorizeAny())
-	tlsClientConfig.MinVersion = tls.VersionTLS12
+	tlsClientConfig, err := nsmgr.TLSConfig(ctx, config, source, source)
+	if err != nil {
+		log.FromContext(ctx).Fatalf("%+v", err)
+	}
 
 	// ********************************************************************************
 	log.FromContext(ctx).Infof("executing phase 4: create network service client (time since start: %s)", time.Since(starttime))
 	// ********************************************************************************
-	dialOptions := append(tracing.WithTracingDial(),
-		grpc.WithDefaultCallOptions(
-			grpc.PerRPCCredentials(token.NewPerRPCCredentials(spiffejwt.TokenGeneratorFunc(source, config.MaxTokenLifetime))),
-		),
-		grpc.WithTransportCredentials(
-			grpcfd.TransportCredentials(
-				credentials.NewTLS(tlsClientConfig))),
-		grpcfd.WithChainStreamInterceptor(),
-		grpcfd.WithChainUnaryInterceptor(),
-	)
-
-	var ifindex interface_types.InterfaceIndex
+	nscMetrics, err := metrics.New()
+	if err != nil {
+		log.FromContext(ctx).Fatalf("failed to create metrics: %+v", err)
+	}
 
-	nsmClient := client.NewClient(
-		ctx,
-		client.WithClientURL(&config.ConnectTo),
-		client.WithName(config.Name),
-		client.WithHealClient(heal.NewClient(ctx,
-			heal.WithLivenessCheck(func(deadlineCtx context.Context, conn *networkservice.Connection) bool {
-				l := log.FromContext(ctx)
-
-				defer l.Info("Finish pinging")
-				defaultTimeout := time.Second
-				deadline, ok := deadlineCtx.Deadline()
-				if !ok {
-					deadline = time.Now().Add(defaultTimeout)
-				}
-				timeout := time.Until(deadline)
+	tokenGenerator := jwttoken.New(source, config.MaxTokenLifetime, config.TokenRefreshAhead, config.TokenAudience...)
 
-				packetCount := 4
-				interval := timeout.Seconds() / float64(packetCount) * 0.7
-				dstIP := conn.Context.IpContext.DstIpAddrs[0]
+	dialOptions, err := nsmgr.DialOptions(config, tlsClientConfig, tokenGenerator.GeneratorFunc(),
+		grpc.WithChainStreamInterceptor(nscMetrics.StreamClientInterceptor()),
+	)
+	if err != nil {
+		log.FromContext(ctx).Fatalf("%+v", err)
+	}
 
-				var msg ping.Ping
+	var capturer *pcap.Capturer
+	if config.PcapDir != "" {
+		capturer = pcap.New(vppConn, config.PcapDir)
+	}
 
-				dstAddrStr := strings.Split(dstIP, "/")[0]
+	var eventsClient networkservice.NetworkServiceClient
+	eventsHandler := func(context.Context, registry.Event, *networkservice.Connection) {}
+	if config.KubernetesEvents {
+		eventRecorder, eventsErr := k8sevents.New(ctx, config.PodName, config.PodNamespace, config.NodeName)
+		if eventsErr != nil {
+			log.FromContext(ctx).Fatalf("failed to create Kubernetes event recorder: %+v", eventsErr)
+		}
+		eventsClient = eventRecorder.NewClient()
+		eventsHandler = eventRecorder.Handle
+	}
 
-				dstAddress, _ := ip_types.ParseAddress(dstAddrStr)
+	connRegistry := registry.New(
+		registry.WithEventHandler(func(ctx context.Context, event registry.Event, conn *networkservice.Connection) {
+			if event == registry.EventHealed {
+				nscMetrics.Heal(ctx, conn)
+			}
+		}),
+		registry.WithEventHandler(hooks.New(ctx, config.HookCommands, config.HookWebhooks, config.HookTimeout).Handle),
+		registry.WithEventHandler(eventsHandler),
+		registry.WithEventHandler(func(eventCtx context.Context, event registry.Event, conn *networkservice.Connection) {
+			swIfIndex, ok := ifindex.Load(eventCtx, true)
+			if capturer == nil || !config.PcapOnConnect || event != registry.EventEstablished || !ok {
+				return
+			}
+			go func() {
+				path, captureErr := capturer.Capture(ctx, conn.GetId(), uint32(swIfIndex), config.PcapMaxPackets, config.PcapDuration)
+				if captureErr != nil {
+					log.FromContext(ctx).Errorf("failed to capture packets of connection %s: %+v", conn.GetId(), captureErr)
+					return
+				}
+				log.FromContext(ctx).Infof("packets of connection %s are captured to %s", conn.GetId(), path)
+			}()
+		}),
+	)
+	livenessCheck, err := health.NewLivenessCheck(ctx, config, vppConn, nscMetrics)
+	if err != nil {
+		log.FromContext(ctx).Fatalf("%+v", err)
+	}
+	if faults != nil {
+		livenessCheck = faults.LivenessCheck(livenessCheck)
+	}
+	attacher := attach.New(vppConn)
 
-				l.Infof("DstAddrStr: %v", dstAddrStr)
-				l.Infof("DstAddr parsed: %v", dstAddress)
+	var datapathVerifier *verify.Verifier
+	thresholds := verify.Thresholds{
+		MinPathMTU: config.VerifyMinPathMTU,
+		MaxLatency: config.VerifyMaxLatency,
+	}
+	if config.VerifyMinThroughput != "" {
+		minThroughput, rateErr := qos.ParseRate(config.VerifyMinThroughput)
+		if rateErr != nil {
+			log.FromContext(ctx).Fatalf("invalid minimal throughput: %+v", rateErr)
+		}
+		thresholds.MinThroughput = uint64(minThroughput)
+	}
+	if !thresholds.IsZero() {
+		datapathVerifier = verify.New(ctx, vppConn, thresholds, config.VerifyProbeDuration)
+	}
 
-				msg.Address = dstAddress
-				msg.Timeout = interval
+	// The same nodeName label is set from NODE_NAME by clientinfo, the explicit label takes precedence
+	if config.NodeName != "" {
+		if config.Labels == nil {
+			config.Labels = make(map[string]string)
+		}
+		if _, ok := config.Labels[labels.NodeNameVariable]; !ok {
+			config.Labels[labels.NodeNameVariable] = config.NodeName
+		}
+	}
 
-				replyCount := 0
+	if config.NsmgrDiscovery {
+		discovered, discoverErr := nsmgr.Discover(ctx, config)
+		if discoverErr != nil {
+			log.FromContext(ctx).Fatalf("failed to discover NSMgr: %+v", discoverErr)
+		}
+		log.FromContext(ctx).Infof("discovered NSMgr: %v", discovered)
+		config.ConnectTo = discovered
+	}
+	if len(config.ConnectTo) == 0 {
+		log.FromContext(ctx).Fatal("no NSMgr URLs to connect to")
+	}
+	nsmURLs := failover.New(config.ConnectTo, config.FailoverMaxFailures)
 
-				for i := 0; i < packetCount; i++ {
-					reply, _ := ping.NewServiceClient(vppConn).Ping(deadlineCtx, &msg)
-					if deadlineCtx.Err() != nil {
-						l.Info("deadline exceeded")
+	// ********************************************************************************
+	// Configure signal handling context
+	// ********************************************************************************
+	signalCtx, cancelSignalCtx := notifyContext(ctx)
+	defer cancelSignalCtx()
 
-						if reply != nil {
-							replyCount += int(reply.ReplyCount)
+	// ********************************************************************************
+	// Create Network Service Manager monitorClient
+	// ********************************************************************************
+	// Without network services NSC starts idle and dials NSMgr when the first service is added at runtime
+	dialedConns := make(map[string]*grpc.ClientConn)
+	if len(config.NetworkServices) == 0 {
+		log.FromContext(ctx).Info("NSC: no network services are configured, waiting for the services to be added at runtime")
+		if config.AdminSocket == "" {
+			log.FromContext(ctx).Warn("admin API is disabled, network services can be added only by the config reload on SIGHUP")
+		}
+	} else {
+		log.FromContext(ctx).Infof("NSC: Connecting to Network Service Manager %v", config.ConnectTo)
+		nsmURL, cc, dialErr := nsmgr.Dial(signalCtx, config, nsmURLs, dialOptions...)
+		if dialErr != nil {
+			log.FromContext(ctx).Fatalf("failed dial to NSMgr: %v", dialErr.Error())
+		}
+		dialedConns[nsmURL.String()] = cc
+	}
 
-							l.Infof("reply.Retval: %v", reply.Retval)
-							l.Infof("reply.ReplyCount: %v", reply.ReplyCount)
-						}
+	// The same gRPC connections are used for the requests and the monitor streams
+	nsmgrConns := failover.NewConns(ctx,
+		func(ctx context.Context, u *url.URL) (*grpc.ClientConn, error) {
+			return grpc.DialContext(ctx, grpcutils.URLToTarget(u), dialOptions...)
+		},
+		dialedConns,
+	)
+	monitorClient := failover.NewMonitorClient(nsmURLs, nsmgrConns)
+
+	redundancyGroups := redundancy.New(vppConn)
+	healCheck := health.NewHealCheck(config, livenessCheck, connRegistry, redundancyGroups, attacher)
+
+	nscClients, err := nscclient.New(ctx, config, &nscclient.Dependencies{
+		VPPConn:    vppConn,
+		Conns:      nsmgrConns,
+		HealCheck:  healCheck,
+		ConnLog:    connLogHook,
+		Metrics:    nscMetrics,
+		Registry:   connRegistry,
+		Attacher:   attacher,
+		Redundancy: redundancyGroups,
+		Verifier:   datapathVerifier,
+		APITrace:   apiTraceClient,
+		Events:     eventsClient,
+	})
+	if err != nil {
+		log.FromContext(ctx).Fatalf("failed to create network service client: %+v", err)
+	}
+	newNSMClient := func(dialTimeout time.Duration) networkservice.NetworkServiceClient {
+		return failover.NewClient(nsmURLs, func(u *url.URL) networkservice.NetworkServiceClient {
+			return nscClients.NewClient(u, dialTimeout)
+		})
+	}
 
-						return replyCount > 0
-					}
+	go svid.WatchRotation(ctx, source, func() {
+		nscMetrics.SVIDRotation(ctx)
+		nsmgrConns.Reset()
+	})
 
-					if reply != nil {
-						l.Infof("reply.Retval: %v", reply.Retval)
-						l.Infof("reply.ReplyCount: %v", reply.ReplyCount)
-					}
+	// ********************************************************************************
+	log.FromContext(ctx).Infof("executing phase 5: connect to all passed services (time since start: %s)", time.Since(starttime))
+	// ********************************************************************************
 
-					replyCount += int(reply.ReplyCount)
-				}
+	labelVariables := labels.Variables{}
+	labelVariables.Set(labels.PodNameVariable, config.PodName)
+	labelVariables.Set(labels.NodeNameVariable, config.NodeName)
+	labelVariables.Set(labels.NamespaceVariable, config.PodNamespace)
+	if config.PodInfoDir != "" {
+		if err = labelVariables.ReadDir(config.PodInfoDir); err != nil {
+			log.FromContext(ctx).Fatalf("failed to read pod info: %v", err)
+		}
+	}
 
-				return replyCount > 0
-			}),
-			heal.WithLivenessCheckInterval(time.Second*3),
-			heal.WithLivenessCheckTimeout(time.Second*10))),
-		client.WithAdditionalFunctionality(
-			clientinfo.NewClient(),
-			upstreamrefresh.NewClient(ctx),
-			up.NewClient(ctx, vppConn),
-			connectioncontext.NewClient(vppConn),
-			memif.NewClient(ctx, vppConn),
-			NewClient(ctx, &ifindex),
-			sendfd.NewClient(),
-			recvfd.NewClient(),
-			excludedprefixes.NewClient(excludedprefixes.WithAwarenessGroups(config.AwarenessGroups)),
-		),
-		client.WithDialTimeout(config.DialTimeout),
-		client.WithDialOptions(dialOptions...),
+	connManager := connections.NewManager(ctx, config.Name, newNSMClient, monitorClient,
+		connections.WithRequestTimeout(config.RequestTimeout),
+		connections.WithDialTimeout(config.DialTimeout),
+		connections.WithDatapathCheck(health.NewDatapathCheck(livenessCheck, redundancyGroups, attacher)),
+		connections.WithMaxParallelRequests(config.MaxParallelRequests),
+		connections.WithStartupJitter(config.StartupJitter),
+		connections.WithRetryJitter(config.RetryJitter),
+		connections.WithStateFile(config.StateFile),
+		connections.WithLabels(config.Labels, labelVariables),
 	)
 
-	nsmClient = retry.NewClient(nsmClient, retry.WithTryTimeout(config.RequestTimeout))
+	// ********************************************************************************
+	// Serve Kubernetes probes
+	// ********************************************************************************
+	readinessChecks := []probes.Check{
+		connManager.Established,
+		connRegistry.Check,
+		probes.InterfacesUp(vppConn, connRegistry.IfIndexes),
+	}
+	if datapathVerifier != nil {
+		readinessChecks = append(readinessChecks, datapathVerifier.Check)
+	}
+	healthProbes := health.NewProbes(vppConn, readinessChecks...)
+	if config.ProbesListen != "" {
+		mux := http.NewServeMux()
+		healthProbes.Register(mux)
+		mux.Handle("/services", connManager)
+		exitOnErrCh(ctx, cancel, httputils.ListenAndServe(signalCtx, config.ProbesListen, mux))
+		log.FromContext(ctx).Infof("probes are listening on %s", config.ProbesListen)
+	}
 
 	// ********************************************************************************
-	// Configure signal handling context
+	// Serve Prometheus metrics
 	// ********************************************************************************
-	signalCtx, cancelSignalCtx := notifyContext(ctx)
-	defer cancelSignalCtx()
+	if prometheus != nil {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", prometheus)
+		exitOnErrCh(ctx, cancel, httputils.ListenAndServe(signalCtx, config.PrometheusListen, mux))
+		log.FromContext(ctx).Infof("Prometheus metrics are served on %s", config.PrometheusListen)
+	}
 
 	// ********************************************************************************
-	// Create Network Service Manager monitorClient
+	// Serve pprof
 	// ********************************************************************************
-	dialCtx, cancelDial := context.WithTimeout(signalCtx, config.DialTimeout)
-	defer cancelDial()
+	if config.PprofListenOn != "" {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		exitOnErrCh(ctx, cancel, httputils.ListenAndServe(signalCtx, config.PprofListenOn, mux))
+		log.FromContext(ctx).Infof("pprof is served on %s", config.PprofListenOn)
+	}
 
-	log.FromContext(ctx).Infof("NSC: Connecting to Network Service Manager %v", config.ConnectTo.String())
-	cc, err := grpc.DialContext(dialCtx, grpcutils.URLToTarget(&config.ConnectTo), dialOptions...)
-	if err != nil {
-		log.FromContext(ctx).Fatalf("failed dial to NSMgr: %v", err.Error())
+	if err := connManager.Update(ctx, config.NetworkServices); err != nil {
+		log.FromContext(ctx).Fatalf("invalid network services: %v", err.Error())
 	}
+	if config.CloseOnExit {
+		defer vppinit.Drain(ctx, config.ShutdownTimeout, connManager, vppConn, connRegistry.IfIndexes)
+	} else {
+		defer log.FromContext(ctx).Infof("leaving connections open to be adopted by the next NSC instance")
+	}
+	healthProbes.SetStarted()
 
-	monitorClient := networkservice.NewMonitorConnectionClient(cc)
+	// ********************************************************************************
+	// Inject faults
+	// ********************************************************************************
+	if faults != nil {
+		go faults.Run(signalCtx, func() []string {
+			var ids []string
+			for _, info := range connRegistry.Connections() {
+				ids = append(ids, info.ID)
+			}
+			return ids
+		}, connManager.Reselect)
+	}
 
 	// ********************************************************************************
-	log.FromContext(ctx).Infof("executing phase 5: connect to all passed services (time since start: %s)", time.Since(starttime))
+	// Notify systemd
 	// ********************************************************************************
+	if sdnotify.Enabled() {
+		if err := sdnotify.Notify(sdnotify.Ready); err != nil {
+			log.FromContext(ctx).Warnf("%v", err.Error())
+		}
+		go sdnotify.RunWatchdog(signalCtx, probes.VPPAlive(vppConn))
+		defer func() { _ = sdnotify.Notify(sdnotify.Stopping) }()
+	}
 
-	for i := 0; i < len(config.NetworkServices); i++ {
-		u := nsurl.NSURL(config.NetworkServices[i])
+	// ********************************************************************************
+	// Export VPP interface counters
+	// ********************************************************************************
+	if opentelemetry.IsEnabled() || prometheus != nil {
+		if err := stats.Start(ctx, config.VppStatsSocket, connRegistry.Connections); err != nil {
+			log.FromContext(ctx).Errorf("failed to export VPP stats: %+v", err)
+		}
+	}
 
-		id := fmt.Sprintf("%s-%d", config.Name, i)
-		var monitoredConnections map[string]*networkservice.Connection
-		monitorCtx, cancelMonitor := context.WithTimeout(signalCtx, config.RequestTimeout)
-		defer cancelMonitor()
+	// ********************************************************************************
+	// Benchmark the datapath of the connections
+	// ********************************************************************************
+	if config.BenchmarkDuration > 0 {
+		bench := benchmark.New(vppConn, config.VppStatsSocket, config.BenchmarkDuration, config.BenchmarkRate, config.BenchmarkPacketSize)
+		go func() {
+			for connManager.Established(ctx) != nil {
+				select {
+				case <-signalCtx.Done():
+					return
+				case <-time.After(time.Second):
+				}
+			}
+			bench.RunAll(signalCtx, connRegistry.Connections())
+		}()
+	}
 
-		stream, err := monitorClient.MonitorConnections(monitorCtx, &networkservice.MonitorScopeSelector{
-			PathSegments: []*networkservice.PathSegment{
-				{
-					Id: id,
-				},
-			},
-		})
-		if err != nil {
-			log.FromContext(ctx).Fatalf("error from monitorConnectionClient", err.Error())
+	// ********************************************************************************
+	// Restore the connections after VPP restart
+	// ********************************************************************************
+	vppConn.OnRestart(func(ctx context.Context) {
+		log.FromContext(ctx).Infof("VPP has been restarted, reconnecting to network services")
+		if err := connManager.Reconnect(ctx); err != nil {
+			log.FromContext(ctx).Errorf("failed to restore connections after VPP restart: %v", err.Error())
 		}
+	})
 
-		event, err := stream.Recv()
-		if err != nil {
-			log.FromContext(ctx).Errorf("error from monitorConnection stream", err.Error())
-		} else {
-			monitoredConnections = event.Connections
+	// ********************************************************************************
+	// Reload network services on SIGHUP
+	// ********************************************************************************
+	handleSignals(signalCtx, reloadCh, func() {
+		reloaded := &nscconfig.Config{}
+		if err := nscconfig.Load(reloaded); err != nil {
+			log.FromContext(ctx).Errorf("failed to reload config: %+v", err)
+			return
 		}
-		cancelMonitor()
+		log.FromContext(ctx).Infof("reloaded network services: %v", reloaded.NetworkServices)
+		if err := connManager.Update(ctx, reloaded.NetworkServices); err != nil {
+			log.FromContext(ctx).Errorf("failed to update network services: %v", err.Error())
+		}
+	})
 
-		mech := u.Mechanism()
-		if mech.Type != memif.MECHANISM {
-			log.FromContext(ctx).Fatalf("mechanism type: %v is not supported", mech.Type)
+	// ********************************************************************************
+	// Collect debug bundles on SIGUSR1
+	// ********************************************************************************
+	var adminOptions []admin.Option
+	if config.DebugBundleDir != "" {
+		bundleOptions := []debugbundle.Option{
+			debugbundle.WithVPPCommands(vppConn, "show interface", "show memif", "show errors"),
+			debugbundle.WithSource("nsc.log", logBuffer.Source),
+			debugbundle.WithJSON("connections.json", func() interface{} { return connRegistry.Connections() }),
+			debugbundle.WithJSON("services.json", func() interface{} { return connManager.Services() }),
 		}
-		request := &networkservice.NetworkServiceRequest{
-			Connection: &networkservice.Connection{
-				Id:             id,
-				NetworkService: u.NetworkService(),
-				Labels:         u.Labels(),
-			},
-			MechanismPreferences: []*networkservice.Mechanism{
-				mech,
-			},
+		if config.VppAPITraceSize > 0 {
+			bundleOptions = append(bundleOptions, debugbundle.WithSource("vpp/api-trace.txt", apiTrace.Source))
 		}
-
-		for _, conn := range monitoredConnections {
-			path := conn.GetPath()
-			if path.Index == 1 && path.PathSegments[0].Id == id && conn.Mechanism.Type == u.Mechanism().Type {
-				request.Connection = conn
-				request.Connection.Path.Index = 0
-				request.Connection.Id = id
-				break
+		bundles := debugbundle.New(config.DebugBundleDir, bundleOptions...)
+		onSignal(signalCtx, syscall.SIGUSR1, func() {
+			path, collectErr := bundles.Collect(ctx)
+			if collectErr != nil {
+				log.FromContext(ctx).Errorf("failed to collect debug bundle: %+v", collectErr)
+				return
+			}
+			log.FromContext(ctx).Infof("debug bundle is written to %s", path)
+		})
+		adminOptions = append(adminOptions, admin.WithDebugBundle(bundles.Collect))
+	}
+	adminOptions = append(adminOptions, admin.WithVersion(func(ctx context.Context) (interface{}, error) {
+		return buildInfo.WithVPP(ctx, vppConn)
+	}))
+	adminOptions = append(adminOptions, admin.WithPacketTracer(packettrace.New(vppConn, func(id string) (string, bool) {
+		for _, info := range connRegistry.Connections() {
+			if info.ID == id {
+				return info.Mechanism, true
 			}
 		}
-
-		resp, err := nsmClient.Request(ctx, request)
-		if err != nil {
-			log.FromContext(ctx).Fatalf("request has failed: %v", err.Error())
+		return "", false
+	})))
+	if config.MirrorSocketDir != "" {
+		if mkdirErr := os.MkdirAll(config.MirrorSocketDir, 0o755); mkdirErr != nil {
+			log.FromContext(ctx).Fatalf("failed to create mirror socket directory: %+v", mkdirErr)
 		}
+	}
+	adminOptions = append(adminOptions, admin.WithMirror(mirror.New(vppConn, connRegistry.IfIndex, config.MirrorSocketDir)))
+	if capturer != nil {
+		adminOptions = append(adminOptions, admin.WithPacketCapture(
+			func(captureCtx context.Context, id string, packets uint32, duration time.Duration) (string, error) {
+				swIfIndex, ok := connRegistry.IfIndex(id)
+				if !ok {
+					return "", errors.Errorf("no interface found for connection %s", id)
+				}
+				if packets == 0 {
+					packets = config.PcapMaxPackets
+				}
+				if duration == 0 {
+					duration = config.PcapDuration
+				}
+				return capturer.Capture(captureCtx, id, swIfIndex, packets, duration)
+			}))
+	}
 
-		defer func() {
-			closeCtx, cancelClose := context.WithTimeout(ctx, config.RequestTimeout)
-			defer cancelClose()
-			_, _ = nsmClient.Close(closeCtx, resp)
-		}()
+	// ********************************************************************************
+	// Serve runtime admin API
+	// ********************************************************************************
+	if config.AdminSocket != "" {
+		adminServer := grpc.NewServer()
+		admin.RegisterAdminServer(adminServer, admin.NewServer(ctx, connManager, adminOptions...))
+		adminURL := &url.URL{Scheme: "unix", Path: config.AdminSocket}
+		exitOnErrCh(ctx, cancel, grpcutils.ListenAndServe(signalCtx, adminURL, adminServer))
+		log.FromContext(ctx).Infof("admin API is listening on %s", adminURL.String())
+	}
+	if config.InterfacesSocket != "" {
+		interfacesServer := grpc.NewServer()
+		ifmap.RegisterInterfacesServer(interfacesServer, ifmap.NewServer(connRegistry.Connections))
+		interfacesURL := &url.URL{Scheme: "unix", Path: config.InterfacesSocket}
+		exitOnErrCh(ctx, cancel, grpcutils.ListenAndServe(signalCtx, interfacesURL, interfacesServer))
+		log.FromContext(ctx).Infof("interfaces API is listening on %s", interfacesURL.String())
+	}
+	if config.AdminListen != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/connections", connRegistry)
+		exitOnErrCh(ctx, cancel, httputils.ListenAndServe(signalCtx, config.AdminListen, mux))
+		log.FromContext(ctx).Infof("admin HTTP endpoint is listening on %s", config.AdminListen)
 	}
 
 	<-signalCtx.Done()
 }
 
+// validateConfig prints the effective config to stdout and logs all its invalid values. It returns false if the
+// config is invalid.
+func validateConfig(config *nscconfig.Config) bool {
+	valid := true
+	if err := nscconfig.Print(os.Stdout, config); err != nil {
+		logrus.Error(err)
+		valid = false
+	}
+	if err := nscconfig.Validate(config, nscclient.Mechanisms(config)...); err != nil {
+		logrus.Error(err)
+		valid = false
+	}
+	if err := health.ValidateLivenessCheck(config); err != nil {
+		logrus.Error(err)
+		valid = false
+	}
+	return valid
+}
+
 func exitOnErrCh(ctx context.Context, cancel context.CancelFunc, errCh <-chan error) {
 	// If we already have an error, log it and exit
 	select {
@@ -415,8 +734,29 @@ func notifyContext(ctx context.Context) (context.Context, context.CancelFunc) {
 		ctx,
 		os.Interrupt,
 		// More Linux signals here
-		syscall.SIGHUP,
 		syscall.SIGTERM,
 		syscall.SIGQUIT,
 	)
 }
+
+func onSignal(ctx context.Context, sig os.Signal, handler func()) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, sig)
+	handleSignals(ctx, sigCh, handler)
+}
+
+// handleSignals calls handler for each signal received from sigCh and stops the signal delivery to sigCh once ctx
+// is done
+func handleSignals(ctx context.Context, sigCh chan os.Signal, handler func()) {
+	go func() {
+		defer signal.Stop(sigCh)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigCh:
+				handler()
+			}
+		}
+	}()
+}